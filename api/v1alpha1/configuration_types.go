@@ -34,6 +34,9 @@ type ConfigurationSpec struct {
 	// Lists contains predefined name lists of name/value pairs that can be
 	// used to offer a selection to a user for a configuration item
 	Lists map[string][]ListItem `json:"lists,omitempty"`
+	// Groups contains named groups of connection history entries. Each
+	// group member is either a history entry id or an alias.
+	Groups map[string][]string `json:"groups,omitempty"`
 	// ImportedFrom holds where this configuration was originally imported from
 	ImportedFrom *string `json:"importedFrom,omitempty"`
 	// VersionCheck holds details of the last version cehck
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/pkg/utils"
+)
+
+const (
+	shortDesc = "Share the AWS credentials from kconnect with other tools."
+	longDesc  = `
+The aws command and sub-commands allow the AWS credentials that kconnect obtains
+when connecting to EKS clusters to be used by other AWS tools, such as the aws cli
+and the AWS SDKs, and to manage the profiles that kconnect creates in the AWS
+credentials file.
+`
+	examples = `
+  # Output the AWS credentials for a connection history entry
+  {{.CommandPath}} aws credential-process --entry uat-bu1
+
+  # Add a profile to the AWS config file that uses the credentials of an entry
+  {{.CommandPath}} aws credential-process --entry uat-bu1 --write-profile uat
+
+  # List the kconnect profiles in the AWS credentials file
+  {{.CommandPath}} aws profiles ls
+`
+)
+
+// Command creates the aws command
+func Command() (*cobra.Command, error) {
+	awsCmd := &cobra.Command{
+		Use:     "aws",
+		Short:   shortDesc,
+		Long:    longDesc,
+		Example: examples,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cmd.Help(); err != nil {
+				zap.S().Debugw("ignoring cobra error",
+					"error",
+					err.Error())
+			}
+		},
+	}
+	utils.FormatCommand(awsCmd)
+
+	credentialProcessCmd, err := credentialProcessCommand()
+	if err != nil {
+		return nil, fmt.Errorf("creating aws credential-process command: %w", err)
+	}
+
+	awsCmd.AddCommand(credentialProcessCmd)
+
+	profilesCmd, err := profilesCommand()
+	if err != nil {
+		return nil, fmt.Errorf("creating aws profiles command: %w", err)
+	}
+
+	awsCmd.AddCommand(profilesCmd)
+
+	tokenCmd, err := tokenCommand()
+	if err != nil {
+		return nil, fmt.Errorf("creating aws token command: %w", err)
+	}
+
+	awsCmd.AddCommand(tokenCmd)
+
+	return awsCmd, nil
+}
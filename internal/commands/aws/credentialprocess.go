@@ -0,0 +1,164 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/internal/helpers"
+	"github.com/fidelity/kconnect/pkg/app"
+	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/defaults"
+	"github.com/fidelity/kconnect/pkg/flags"
+	"github.com/fidelity/kconnect/pkg/history"
+	"github.com/fidelity/kconnect/pkg/history/loader"
+	"github.com/fidelity/kconnect/pkg/utils"
+)
+
+var ErrEntryRequired = errors.New("entry must be specified")
+
+const (
+	shortDescCredentialProcess = "Output the AWS credentials of a connection history entry"
+	longDescCredentialProcess  = `
+Output the AWS credentials of a connection history entry in the format used by
+the credential_process setting of the AWS config file. This allows the aws cli
+and the AWS SDKs to use the same credentials as kconnect.
+
+The credentials stored in the AWS profile of the entry are used until they
+expire. When they have expired kconnect authenticates again using the identity
+provider and settings of the entry, the new credentials are stored in the
+profile and output.
+
+The --write-profile flag adds a profile to the AWS config file (~/.aws/config
+or AWS_CONFIG_FILE) that uses this command to get its credentials:
+
+  [profile uat]
+  credential_process = /usr/local/bin/kconnect aws credential-process --entry uat-bu1
+
+The AWS tools don't allow kconnect to prompt for input, so the password should
+be supplied with the KCONNECT_PASSWORD environment variable or the --password
+flag if authentication needs a password.
+`
+	examplesCredentialProcess = `
+  # Output the AWS credentials for an entry by its alias
+  {{.CommandPath}} aws credential-process --entry uat-bu1
+
+  # Output the AWS credentials for an entry by its id
+  {{.CommandPath}} aws credential-process --entry 01EM615GB2YX3C6WZ9MCWBDWBF
+
+  # Add a profile called uat to the AWS config file that uses the credentials of an entry
+  {{.CommandPath}} aws credential-process --entry uat-bu1 --write-profile uat
+
+  # Use the profile with the aws cli
+  aws s3 ls --profile uat
+`
+)
+
+func credentialProcessCommand() (*cobra.Command, error) {
+	cfg := config.NewConfigurationSet()
+
+	credentialProcessCmd := &cobra.Command{
+		Use:     "credential-process",
+		Short:   shortDescCredentialProcess,
+		Long:    longDescCredentialProcess,
+		Example: examplesCredentialProcess,
+		Args:    cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			flags.BindFlags(cmd)
+			flags.PopulateConfigFromCommand(cmd, cfg)
+
+			commonCfg, err := helpers.GetCommonConfig(cmd, cfg)
+			if err != nil {
+				return fmt.Errorf("getting common config: %w", err)
+			}
+
+			if err := config.ApplyToConfigSet(commonCfg.ConfigFile, cfg); err != nil {
+				return fmt.Errorf("applying app config: %w", err)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			zap.S().Debug("running `aws credential-process` command")
+
+			params := &app.AWSCredentialProcessInput{}
+
+			if err := config.Unmarshall(cfg, params); err != nil {
+				return fmt.Errorf("unmarshalling config into aws credential-process params: %w", err)
+			}
+
+			if params.Entry == "" {
+				return ErrEntryRequired
+			}
+
+			historyLoader, err := loader.NewFileLoader(params.Location)
+			if err != nil {
+				return fmt.Errorf("getting history loader with path %s: %w", params.Location, err)
+			}
+
+			store, err := history.NewStore(defaults.MaxHistoryItems, historyLoader)
+			if err != nil {
+				return fmt.Errorf("creating history store: %w", err)
+			}
+
+			a := app.New(app.WithHistoryStore(store), app.WithInteractive(false))
+
+			return a.AWSCredentialProcess(cmd.Context(), params)
+		},
+	}
+	utils.FormatCommand(credentialProcessCmd)
+
+	if err := addConfigCredentialProcess(cfg); err != nil {
+		return nil, fmt.Errorf("add credential-process command config: %w", err)
+	}
+
+	if err := flags.CreateCommandFlags(credentialProcessCmd, cfg); err != nil {
+		return nil, err
+	}
+
+	return credentialProcessCmd, nil
+}
+
+func addConfigCredentialProcess(cs config.ConfigurationSet) error {
+	if err := app.AddCommonConfigItems(cs); err != nil {
+		return fmt.Errorf("adding common config: %w", err)
+	}
+
+	if err := app.AddHistoryLocationItems(cs); err != nil {
+		return fmt.Errorf("adding history location items: %w", err)
+	}
+
+	if _, err := cs.String("entry", "", "The alias or id of the connection history entry"); err != nil {
+		return fmt.Errorf("adding entry config item: %w", err)
+	}
+
+	if _, err := cs.String("password", "", "Password to use if authentication is needed"); err != nil {
+		return fmt.Errorf("adding password config item: %w", err)
+	}
+
+	if _, err := cs.String("write-profile", "", "Write a profile with this name to the AWS config file that uses the credentials of the entry"); err != nil {
+		return fmt.Errorf("adding write-profile config item: %w", err)
+	}
+
+	cs.SetSensitive("password") //nolint: errcheck
+
+	return nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/internal/helpers"
+	"github.com/fidelity/kconnect/pkg/app"
+	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/defaults"
+	"github.com/fidelity/kconnect/pkg/flags"
+	"github.com/fidelity/kconnect/pkg/history"
+	"github.com/fidelity/kconnect/pkg/history/loader"
+	"github.com/fidelity/kconnect/pkg/utils"
+)
+
+const (
+	shortDescProfiles = "Manage the kconnect profiles in the AWS credentials file"
+	longDescProfiles  = `
+Each time kconnect gets temporary AWS credentials, for example when using SAML,
+they are stored in a kconnect-<id> profile in the AWS credentials file. The
+profiles sub-commands allow you to list these profiles and remove the ones that
+are no longer needed.
+
+The profiles can also be removed automatically after connecting to an EKS
+cluster by setting prune-aws-profiles to true in the kconnect config file.
+`
+	examplesProfiles = `
+  # List the kconnect profiles in the AWS credentials file
+  {{.CommandPath}} aws profiles ls
+
+  # Remove the expired and unused kconnect profiles
+  {{.CommandPath}} aws profiles prune
+`
+
+	shortDescProfilesLs = "List the kconnect profiles in the AWS credentials file"
+	longDescProfilesLs  = `
+List the kconnect profiles in the AWS credentials file with when their
+credentials expire and the connection history entries that use them.
+
+The status of each profile is one of:
+  - active:       the credentials are valid and a history entry uses the profile
+  - expired:      the credentials have expired
+  - unreferenced: no history entry uses the profile
+`
+	examplesProfilesLs = `
+  # List the kconnect profiles in the AWS credentials file
+  {{.CommandPath}} aws profiles ls
+
+  # List the kconnect profiles as json
+  {{.CommandPath}} aws profiles ls --output json
+`
+
+	shortDescProfilesPrune = "Remove the expired and unused kconnect profiles"
+	longDescProfilesPrune  = `
+Remove the kconnect profiles from the AWS credentials file whose credentials
+have expired or that aren't used by a connection history entry. The other
+profiles and comments in the file are kept.
+
+The expired profiles of history entries are removed as well, the profile is
+created again when reconnecting with the to command.
+`
+	examplesProfilesPrune = `
+  # Remove the expired and unused kconnect profiles
+  {{.CommandPath}} aws profiles prune
+
+  # Remove the expired and unused kconnect profiles from a different credentials file
+  {{.CommandPath}} aws profiles prune --aws-shared-credentials-file ~/.aws/other-credentials
+`
+)
+
+func profilesCommand() (*cobra.Command, error) {
+	profilesCmd := &cobra.Command{
+		Use:     "profiles",
+		Short:   shortDescProfiles,
+		Long:    longDescProfiles,
+		Example: examplesProfiles,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cmd.Help(); err != nil {
+				zap.S().Debugw("ignoring cobra error",
+					"error",
+					err.Error())
+			}
+		},
+	}
+	utils.FormatCommand(profilesCmd)
+
+	lsCmd, err := profilesSubCommand("ls", shortDescProfilesLs, longDescProfilesLs, examplesProfilesLs, func(a *app.App, cmd *cobra.Command, params *app.AWSProfilesInput) error {
+		return a.AWSProfilesList(cmd.Context(), params)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating aws profiles ls command: %w", err)
+	}
+
+	pruneCmd, err := profilesSubCommand("prune", shortDescProfilesPrune, longDescProfilesPrune, examplesProfilesPrune, func(a *app.App, cmd *cobra.Command, params *app.AWSProfilesInput) error {
+		return a.AWSProfilesPrune(cmd.Context(), params)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating aws profiles prune command: %w", err)
+	}
+
+	profilesCmd.AddCommand(lsCmd)
+	profilesCmd.AddCommand(pruneCmd)
+
+	return profilesCmd, nil
+}
+
+type profilesRunFunc func(a *app.App, cmd *cobra.Command, params *app.AWSProfilesInput) error
+
+func profilesSubCommand(use, short, long, examples string, run profilesRunFunc) (*cobra.Command, error) {
+	cfg := config.NewConfigurationSet()
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   short,
+		Long:    long,
+		Example: examples,
+		Args:    cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			flags.BindFlags(cmd)
+			flags.PopulateConfigFromCommand(cmd, cfg)
+
+			commonCfg, err := helpers.GetCommonConfig(cmd, cfg)
+			if err != nil {
+				return fmt.Errorf("getting common config: %w", err)
+			}
+
+			if err := config.ApplyToConfigSet(commonCfg.ConfigFile, cfg); err != nil {
+				return fmt.Errorf("applying app config: %w", err)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			zap.S().Debugf("running `aws profiles %s` command", use)
+
+			params := &app.AWSProfilesInput{}
+
+			if err := config.Unmarshall(cfg, params); err != nil {
+				return fmt.Errorf("unmarshalling config into aws profiles params: %w", err)
+			}
+
+			historyLoader, err := loader.NewFileLoader(params.Location)
+			if err != nil {
+				return fmt.Errorf("getting history loader with path %s: %w", params.Location, err)
+			}
+
+			store, err := history.NewStore(defaults.MaxHistoryItems, historyLoader)
+			if err != nil {
+				return fmt.Errorf("creating history store: %w", err)
+			}
+
+			return run(app.New(app.WithHistoryStore(store)), cmd, params)
+		},
+	}
+	utils.FormatCommand(cmd)
+
+	if err := addConfigProfiles(cfg); err != nil {
+		return nil, fmt.Errorf("add profiles %s command config: %w", use, err)
+	}
+
+	if err := flags.CreateCommandFlags(cmd, cfg); err != nil {
+		return nil, err
+	}
+
+	return cmd, nil
+}
+
+func addConfigProfiles(cs config.ConfigurationSet) error {
+	if err := app.AddCommonConfigItems(cs); err != nil {
+		return fmt.Errorf("adding common config: %w", err)
+	}
+
+	if err := app.AddHistoryLocationItems(cs); err != nil {
+		return fmt.Errorf("adding history location items: %w", err)
+	}
+
+	if _, err := cs.String("aws-shared-credentials-file", "", "Path to the AWS credentials file. (default \"$HOME/.aws/credentials\")"); err != nil {
+		return fmt.Errorf("adding aws-shared-credentials-file config item: %w", err)
+	}
+
+	if _, err := cs.String("output", "table", "Output format for the results (table, json, yaml)"); err != nil {
+		return fmt.Errorf("adding output config item: %w", err)
+	}
+
+	if err := cs.SetShort("output", "o"); err != nil {
+		return fmt.Errorf("adding output short flag: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/internal/helpers"
+	"github.com/fidelity/kconnect/pkg/app"
+	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/flags"
+	"github.com/fidelity/kconnect/pkg/utils"
+)
+
+var ErrClusterNameRequired = errors.New("cluster-name must be specified")
+
+const (
+	shortDescToken = "Output a token to authenticate with an EKS cluster"
+	longDescToken  = `
+Output a token to authenticate with an EKS cluster as a Kubernetes ExecCredential.
+This is used by kubectl when a context is generated with --login-type kconnect,
+instead of running aws-iam-authenticator or the aws cli.
+
+The AWS credentials are loaded from the --profile flag or the AWS_PROFILE,
+AWS_SHARED_CREDENTIALS_FILE and other AWS environment variables.
+`
+	examplesToken = `
+  # Output a token for an EKS cluster using the credentials of an AWS profile
+  {{.CommandPath}} aws token --cluster-name dev-cluster --region eu-west-2 --profile kconnect-1234567890
+`
+)
+
+func tokenCommand() (*cobra.Command, error) {
+	cfg := config.NewConfigurationSet()
+
+	tokenCmd := &cobra.Command{
+		Use:     "token",
+		Short:   shortDescToken,
+		Long:    longDescToken,
+		Example: examplesToken,
+		Args:    cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			flags.BindFlags(cmd)
+			flags.PopulateConfigFromCommand(cmd, cfg)
+
+			if _, err := helpers.GetCommonConfig(cmd, cfg); err != nil {
+				return fmt.Errorf("getting common config: %w", err)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			zap.S().Debug("running `aws token` command")
+
+			params := &app.AWSTokenInput{}
+
+			if err := config.Unmarshall(cfg, params); err != nil {
+				return fmt.Errorf("unmarshalling config into aws token params: %w", err)
+			}
+
+			if params.ClusterName == "" {
+				return ErrClusterNameRequired
+			}
+
+			a := app.New(app.WithInteractive(false))
+
+			return a.AWSToken(cmd.Context(), params)
+		},
+	}
+	utils.FormatCommand(tokenCmd)
+
+	if err := addConfigToken(cfg); err != nil {
+		return nil, fmt.Errorf("add token command config: %w", err)
+	}
+
+	if err := flags.CreateCommandFlags(tokenCmd, cfg); err != nil {
+		return nil, err
+	}
+
+	return tokenCmd, nil
+}
+
+func addConfigToken(cs config.ConfigurationSet) error {
+	if err := app.AddCommonConfigItems(cs); err != nil {
+		return fmt.Errorf("adding common config: %w", err)
+	}
+
+	if _, err := cs.String("cluster-name", "", "The name of the EKS cluster"); err != nil {
+		return fmt.Errorf("adding cluster-name config item: %w", err)
+	}
+
+	if _, err := cs.String("region", "", "The AWS region of the EKS cluster"); err != nil {
+		return fmt.Errorf("adding region config item: %w", err)
+	}
+
+	if _, err := cs.String("profile", "", "The AWS profile to use. Defaults to AWS_PROFILE"); err != nil {
+		return fmt.Errorf("adding profile config item: %w", err)
+	}
+
+	return nil
+}
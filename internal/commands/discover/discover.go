@@ -0,0 +1,297 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discover
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/internal/helpers"
+	"github.com/fidelity/kconnect/pkg/app"
+	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/flags"
+	"github.com/fidelity/kconnect/pkg/printer"
+	"github.com/fidelity/kconnect/pkg/provider/common"
+	"github.com/fidelity/kconnect/pkg/provider/registry"
+	"github.com/fidelity/kconnect/pkg/utils"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown discovery provider")
+)
+
+const (
+	shortDesc         = "Discover the clusters available from cluster providers."
+	shortDescProvider = "Discover the clusters available from the %s cluster provider."
+	longDesc          = `
+Discover and display the Kubernetes clusters that the user's identity has access to
+without connecting to a cluster.
+
+The discover command uses the same flags and identity flow as the use command, but
+instead of asking the user to choose a cluster it prints the discovered clusters
+along with their version, status and labels. The kubectl configuration and the
+connection history aren't changed.
+
+Additional cluster providers can be supplied as arguments to discover the clusters
+across multiple providers in one invocation. Flags that are supported by an
+additional provider are applied to it, any other settings for the additional
+providers come from the app configuration or are resolved interactively.
+
+The --cluster-filter and --cluster-selector flags can be used to limit the clusters
+that are displayed.
+
+If some clusters, or groups of clusters such as a subscription, can't be discovered
+the clusters that were discovered are still displayed along with a summary of the
+warnings. Use --strict to fail instead.
+`
+	examples = `
+  # Discover the EKS clusters and display them as a table
+  {{.CommandPath}} discover eks
+
+  # Discover the AKS clusters and display them as JSON
+  {{.CommandPath}} discover aks --output json
+
+  # Discover the active EKS clusters in a region
+  {{.CommandPath}} discover eks --cluster-selector "region=eu-west-2,status=ACTIVE"
+
+  # Discover the clusters across EKS, AKS and Rancher
+  {{.CommandPath}} discover eks aks rancher --username bob
+`
+)
+
+// Command creates the discover command
+func Command() (*cobra.Command, error) {
+	discoverCmd := &cobra.Command{
+		Use:     "discover",
+		Short:   shortDesc,
+		Long:    longDesc,
+		Example: examples,
+		Run: func(c *cobra.Command, _ []string) {
+			if err := c.Help(); err != nil {
+				zap.S().Debugw("ignoring cobra error", "error", err.Error())
+			}
+		},
+	}
+
+	utils.FormatCommand(discoverCmd)
+
+	for _, registration := range registry.ListDiscoveryPluginRegistrations() {
+		providerCmd, err := createProviderCmd(registration)
+		if err != nil {
+			return nil, fmt.Errorf("creating provider command for %s: %w", registration.Name, err)
+		}
+
+		discoverCmd.AddCommand(providerCmd)
+	}
+
+	return discoverCmd, nil
+}
+
+func createProviderCmd(registration *registry.DiscoveryPluginRegistration) (*cobra.Command, error) {
+	params := &app.UseInput{
+		ConfigSet:         config.NewConfigurationSet(),
+		DiscoveryProvider: registration.Name,
+	}
+
+	providerCmd := &cobra.Command{
+		Use:     fmt.Sprintf("%s [provider]...", registration.Name),
+		Short:   fmt.Sprintf(shortDescProvider, registration.Name),
+		Long:    longDesc,
+		Example: examples,
+		Args:    cobra.ArbitraryArgs,
+		AdditionalSetupE: func(cmd *cobra.Command, args []string) error {
+			if err := helpers.SetupIdpProtocol(cmd, os.Args, params); err != nil {
+				return fmt.Errorf("additional command setup: %w", err)
+			}
+
+			if err := flags.CreateCommandFlags(cmd, params.ConfigSet); err != nil {
+				return err
+			}
+
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			flags.BindFlags(cmd)
+			flags.PopulateConfigFromCommand(cmd, params.ConfigSet)
+
+			commonCfg, err := helpers.GetCommonConfig(cmd, params.ConfigSet)
+			if err != nil {
+				return fmt.Errorf("getting common config: %w", err)
+			}
+
+			if err := config.ApplyToConfigSetWithProvider(commonCfg.ConfigFile, params.ConfigSet, registration.Name); err != nil {
+				return fmt.Errorf("applying app config: %w", err)
+			}
+
+			if err := config.Unmarshall(params.ConfigSet, params); err != nil {
+				return fmt.Errorf("unmarshalling config into discover params: %w", err)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			zap.S().Debugw("running `discover` command", "provider", registration.Name, "additional", args)
+
+			input := &app.DiscoverInput{
+				Output:    printer.OutputPrinter(params.ConfigSet.ValueString("output")),
+				Providers: []*app.UseInput{params},
+			}
+
+			providerNames := []string{registration.Name}
+
+			for _, providerName := range args {
+				if slices.Contains(providerNames, providerName) {
+					continue
+				}
+
+				providerParams, err := newAdditionalProviderInput(cmd, providerName, params.ConfigFile)
+				if err != nil {
+					return err
+				}
+
+				providerNames = append(providerNames, providerName)
+				input.Providers = append(input.Providers, providerParams)
+			}
+
+			a := app.New(app.WithInteractive(!params.NoInput))
+
+			return a.Discover(cmd.Context(), input)
+		},
+	}
+
+	if err := addConfig(params.ConfigSet, registration); err != nil {
+		return nil, fmt.Errorf("add command config: %w", err)
+	}
+
+	if err := flags.CreateCommandFlags(providerCmd, params.ConfigSet); err != nil {
+		return nil, err
+	}
+
+	providerCmd.SetUsageFunc(helpers.ProviderUsage(registration.Name))
+
+	utils.FormatCommand(providerCmd)
+
+	return providerCmd, nil
+}
+
+// newAdditionalProviderInput will create the input for an additional discovery provider. The flags
+// that were set on the command and are supported by the provider are applied before the app config.
+func newAdditionalProviderInput(cmd *cobra.Command, providerName, configFile string) (*app.UseInput, error) {
+	registration, err := registry.GetDiscoveryProviderRegistration(providerName)
+	if err != nil {
+		return nil, fmt.Errorf("getting discovery provider %s: %w", providerName, ErrUnknownProvider)
+	}
+
+	params := &app.UseInput{
+		ConfigSet:         config.NewConfigurationSet(),
+		DiscoveryProvider: registration.Name,
+	}
+
+	if err := addConfig(params.ConfigSet, registration); err != nil {
+		return nil, fmt.Errorf("adding config for %s: %w", providerName, err)
+	}
+
+	if err := helpers.SetupIdpProtocol(nil, nil, params); err != nil {
+		return nil, fmt.Errorf("setting up idp protocol for %s: %w", providerName, err)
+	}
+
+	fs, err := flags.CreateFlagsFromConfig(params.ConfigSet)
+	if err != nil {
+		return nil, fmt.Errorf("creating flags for %s: %w", providerName, err)
+	}
+
+	var setErr error
+
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name == "idp-protocol" || fs.Lookup(f.Name) == nil {
+			return
+		}
+
+		if err := fs.Set(f.Name, f.Value.String()); err != nil && setErr == nil {
+			setErr = fmt.Errorf("setting flag %s for %s: %w", f.Name, providerName, err)
+		}
+	})
+
+	if setErr != nil {
+		return nil, setErr
+	}
+
+	flags.PopulateConfigFromFlags(fs, params.ConfigSet)
+
+	if err := config.ApplyToConfigSetWithProvider(configFile, params.ConfigSet, registration.Name); err != nil {
+		return nil, fmt.Errorf("applying app config for %s: %w", providerName, err)
+	}
+
+	if err := config.Unmarshall(params.ConfigSet, params); err != nil {
+		return nil, fmt.Errorf("unmarshalling config into discover params for %s: %w", providerName, err)
+	}
+
+	return params, nil
+}
+
+func addConfig(cs config.ConfigurationSet, registration *registry.DiscoveryPluginRegistration) error {
+	if err := app.AddCommonConfigItems(cs); err != nil {
+		return fmt.Errorf("adding common config %s: %w", registration.Name, err)
+	}
+
+	providerCS, err := registration.ConfigurationItemsFunc("")
+	if err != nil {
+		return fmt.Errorf("getting configuration items for %s: %w", registration.Name, err)
+	}
+
+	if err := cs.AddSet(providerCS); err != nil {
+		return fmt.Errorf("adding cluster provider config %s: %w", registration.Name, err)
+	}
+
+	if _, err := cs.String("idp-protocol", "", "The idp protocol to use (e.g. saml, aad). See flags additional flags for the protocol."); err != nil {
+		return fmt.Errorf("adding idp-protocol config: %w", err)
+	}
+
+	if err := common.AddCommonIdentityConfig(cs); err != nil {
+		return fmt.Errorf("adding common identity config items: %w", err)
+	}
+
+	if err := common.AddCommonClusterConfig(cs); err != nil {
+		return fmt.Errorf("adding common cluster config items: %w", err)
+	}
+
+	if err := app.AddClusterFilterConfigItems(cs); err != nil {
+		return fmt.Errorf("adding cluster filter config items: %w", err)
+	}
+
+	if err := app.AddHTTPConfigItems(cs); err != nil {
+		return fmt.Errorf("adding http config items: %w", err)
+	}
+
+	if _, err := cs.String("output", "table", "Output format for the results (table, json, yaml)"); err != nil {
+		return fmt.Errorf("adding output config item: %w", err)
+	}
+
+	if err := cs.SetShort("output", "o"); err != nil {
+		return fmt.Errorf("adding output short flag: %w", err)
+	}
+
+	cs.SetHistoryIgnore("output") //nolint: errcheck
+
+	return nil
+}
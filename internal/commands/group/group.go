@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/pkg/app"
+	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/flags"
+	"github.com/fidelity/kconnect/pkg/utils"
+)
+
+const (
+	maxHistoryEntries = 100
+	shortDesc         = "Query and manipulate groups of connection history entries."
+	longDesc          = `
+A group is a named list of connection history entries. The entries in a
+group are referred to by either their alias or entry ID.
+
+The group command and sub-commands allow you to query and manipulate
+groups. All the entries in a group can be connected to with a single command
+by prefixing the group name with @ when using the to command.
+`
+	examples = `
+  # Create a group from existing connection history entries
+  {{.CommandPath}} group add oncall appdev 01EMEM5DB60TMX7D8SS2JCX3MT
+
+  # List available groups
+  {{.CommandPath}} group ls
+
+  # Connect to all the entries in a group
+  {{.CommandPath}} to @oncall
+
+  # Remove a group
+  {{.CommandPath}} group remove oncall
+`
+)
+
+func Command() (*cobra.Command, error) {
+	cfg := config.NewConfigurationSet()
+
+	groupCmd := &cobra.Command{
+		Use:     "group",
+		Short:   shortDesc,
+		Long:    longDesc,
+		Example: examples,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cmd.Help(); err != nil {
+				zap.S().Debugw("ignoring cobra error",
+					"error",
+					err.Error())
+			}
+		},
+	}
+	utils.FormatCommand(groupCmd)
+
+	if err := addConfigRoot(cfg); err != nil {
+		return nil, fmt.Errorf("add command config: %w", err)
+	}
+
+	commonFlags, err := flags.CreateFlagsFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating common command flags: %w", err)
+	}
+
+	groupCmd.PersistentFlags().AddFlagSet(commonFlags)
+
+	lsCmd, err := lsCommand()
+	if err != nil {
+		return nil, fmt.Errorf("creating group ls command: %w", err)
+	}
+
+	groupCmd.AddCommand(lsCmd)
+
+	addCmd, err := addCommand()
+	if err != nil {
+		return nil, fmt.Errorf("creating group add command: %w", err)
+	}
+
+	groupCmd.AddCommand(addCmd)
+
+	removeCmd, err := removeCommand()
+	if err != nil {
+		return nil, fmt.Errorf("creating group remove command: %w", err)
+	}
+
+	groupCmd.AddCommand(removeCmd)
+
+	return groupCmd, nil
+}
+
+func addConfigRoot(cs config.ConfigurationSet) error {
+	if err := app.AddCommonConfigItems(cs); err != nil {
+		return fmt.Errorf("adding common config: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/internal/helpers"
+	"github.com/fidelity/kconnect/pkg/app"
+	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/flags"
+	"github.com/fidelity/kconnect/pkg/utils"
+)
+
+var (
+	shortDescLs = "List all the groups currently defined"
+	longDescLs  = `
+List all the groups of connection history entries currently defined and the
+entries in each group.
+`
+	examplesLs = `
+  # Display all the groups as a table
+  {{.CommandPath}} group ls
+
+  # Display all the groups as json
+  {{.CommandPath}} group ls --output json
+
+  # Connect to all the clusters in a group
+  {{.CommandPath}} to @${group}
+`
+)
+
+func lsCommand() (*cobra.Command, error) {
+	cfg := config.NewConfigurationSet()
+
+	lsCmd := &cobra.Command{
+		Use:     "ls",
+		Short:   shortDescLs,
+		Long:    longDescLs,
+		Example: examplesLs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			flags.BindFlags(cmd)
+			flags.PopulateConfigFromCommand(cmd, cfg)
+
+			commonCfg, err := helpers.GetCommonConfig(cmd, cfg)
+			if err != nil {
+				return fmt.Errorf("getting common config: %w", err)
+			}
+
+			if err := config.ApplyToConfigSet(commonCfg.ConfigFile, cfg); err != nil {
+				return fmt.Errorf("applying app config: %w", err)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			zap.S().Debug("running `group ls` command")
+
+			params := &app.GroupListInput{}
+
+			if err := config.Unmarshall(cfg, params); err != nil {
+				return fmt.Errorf("unmarshalling config into to params: %w", err)
+			}
+
+			a := app.New()
+
+			return a.GroupList(cmd.Context(), params)
+		},
+	}
+	utils.FormatCommand(lsCmd)
+
+	if err := addConfigLs(cfg); err != nil {
+		return nil, fmt.Errorf("add ls command config: %w", err)
+	}
+
+	if err := flags.CreateCommandFlags(lsCmd, cfg); err != nil {
+		return nil, err
+	}
+
+	return lsCmd, nil
+}
+
+func addConfigLs(cs config.ConfigurationSet) error {
+	if err := app.AddCommonConfigItems(cs); err != nil {
+		return fmt.Errorf("adding common config: %w", err)
+	}
+
+	if _, err := cs.String("output", "table", "Output format for the results"); err != nil {
+		return fmt.Errorf("adding output config item: %w", err)
+	}
+
+	cs.SetHistoryIgnore("output") //nolint: errcheck
+
+	return nil
+}
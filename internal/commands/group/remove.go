@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/internal/helpers"
+	"github.com/fidelity/kconnect/pkg/app"
+	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/flags"
+	"github.com/fidelity/kconnect/pkg/utils"
+)
+
+const (
+	shortDescRemove = "Remove a group or entries from a group"
+	longDescRemove  = `
+Removes connection history entries from a group. If no entries are
+specified then the whole group is removed.
+
+Removing a group doesn't remove the connection history entries.
+`
+	examplesRemove = `
+  # Remove a group
+  {{.CommandPath}} group remove oncall
+
+  # Remove an entry from a group
+  {{.CommandPath}} group remove oncall dev-bu-1
+`
+)
+
+func removeCommand() (*cobra.Command, error) {
+	cfg := config.NewConfigurationSet()
+
+	removeCmd := &cobra.Command{
+		Use:     "remove [group] [alias/id]...",
+		Short:   shortDescRemove,
+		Long:    longDescRemove,
+		Example: examplesRemove,
+		Args:    cobra.MinimumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			flags.BindFlags(cmd)
+			flags.PopulateConfigFromCommand(cmd, cfg)
+
+			commonCfg, err := helpers.GetCommonConfig(cmd, cfg)
+			if err != nil {
+				return fmt.Errorf("getting common config: %w", err)
+			}
+
+			if err := config.ApplyToConfigSet(commonCfg.ConfigFile, cfg); err != nil {
+				return fmt.Errorf("applying app config: %w", err)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			zap.S().Debug("running `group remove` command")
+
+			params := &app.GroupRemoveInput{
+				Name:    args[0],
+				Entries: args[1:],
+			}
+
+			if err := config.Unmarshall(cfg, params); err != nil {
+				return fmt.Errorf("unmarshalling config into to params: %w", err)
+			}
+
+			a := app.New()
+
+			return a.GroupRemove(cmd.Context(), params)
+		},
+	}
+	utils.FormatCommand(removeCmd)
+
+	if err := addConfigRemove(cfg); err != nil {
+		return nil, fmt.Errorf("adding remove command config: %w", err)
+	}
+
+	if err := flags.CreateCommandFlags(removeCmd, cfg); err != nil {
+		return nil, err
+	}
+
+	return removeCmd, nil
+}
+
+func addConfigRemove(cs config.ConfigurationSet) error {
+	if err := app.AddCommonConfigItems(cs); err != nil {
+		return fmt.Errorf("adding common config: %w", err)
+	}
+
+	return nil
+}
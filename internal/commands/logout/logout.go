@@ -127,5 +127,9 @@ func addConfig(cs config.ConfigurationSet) error {
 		return fmt.Errorf("adding kubeconfig config items: %w", err)
 	}
 
+	if err := app.AddHTTPConfigItems(cs); err != nil {
+		return fmt.Errorf("adding http config items: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rancher
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/pkg/utils"
+)
+
+const (
+	shortDesc = "Manage the Rancher resources used by kconnect."
+	longDesc  = `
+The rancher command and sub-commands allow you to manage the resources that
+kconnect creates in Rancher when connecting to clusters.
+
+Each time kconnect logs in to Rancher an api token is created. The token is stored
+and reused until it expires, the tokens sub-command can be used to list the tokens
+and clean up the old ones.
+`
+	examples = `
+  # List the Rancher tokens created by kconnect
+  {{.CommandPath}} rancher tokens --api-endpoint https://rancher.mycompany.com/v3
+
+  # Delete the expired and unused Rancher tokens created by kconnect
+  {{.CommandPath}} rancher tokens --api-endpoint https://rancher.mycompany.com/v3 --clean
+`
+)
+
+// Command creates the rancher command
+func Command() (*cobra.Command, error) {
+	rancherCmd := &cobra.Command{
+		Use:     "rancher",
+		Short:   shortDesc,
+		Long:    longDesc,
+		Example: examples,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cmd.Help(); err != nil {
+				zap.S().Debugw("ignoring cobra error",
+					"error",
+					err.Error())
+			}
+		},
+	}
+	utils.FormatCommand(rancherCmd)
+
+	tokensCmd, err := tokensCommand()
+	if err != nil {
+		return nil, fmt.Errorf("creating rancher tokens command: %w", err)
+	}
+
+	rancherCmd.AddCommand(tokensCmd)
+
+	return rancherCmd, nil
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rancher
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/internal/helpers"
+	"github.com/fidelity/kconnect/pkg/app"
+	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/flags"
+	rshared "github.com/fidelity/kconnect/pkg/rancher"
+	"github.com/fidelity/kconnect/pkg/utils"
+)
+
+const (
+	shortDescTokens = "List and clean up the Rancher tokens created by kconnect"
+	longDescTokens  = `
+List the Rancher api tokens that were created by kconnect when logging in to
+Rancher. The tokens are identified by their description, which includes kconnect
+and the name of the machine that they were created on.
+
+The status of each token is one of:
+  - stored:  the token is stored by kconnect and is reused when connecting
+  - active:  the token is valid but is no longer used by kconnect
+  - expired: the token has expired
+  - deleted: the token was deleted by --clean
+
+The --clean flag deletes the expired tokens and the active tokens that were created
+on this machine. The stored tokens aren't deleted, use the logout command to revoke
+them.
+
+The tokens are listed using the token stored when logging in to Rancher. A token
+can be supplied with the --token flag instead.
+`
+	examplesTokens = `
+  # List the Rancher tokens created by kconnect
+  {{.CommandPath}} rancher tokens --api-endpoint https://rancher.mycompany.com/v3
+
+  # List the Rancher tokens as json
+  {{.CommandPath}} rancher tokens --api-endpoint https://rancher.mycompany.com/v3 --output json
+
+  # Delete the expired and unused Rancher tokens created by kconnect
+  {{.CommandPath}} rancher tokens --api-endpoint https://rancher.mycompany.com/v3 --clean
+`
+)
+
+const tokenConfigItem = "token"
+
+func tokensCommand() (*cobra.Command, error) {
+	cfg := config.NewConfigurationSet()
+
+	tokensCmd := &cobra.Command{
+		Use:     "tokens",
+		Short:   shortDescTokens,
+		Long:    longDescTokens,
+		Example: examplesTokens,
+		Args:    cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			flags.BindFlags(cmd)
+			flags.PopulateConfigFromCommand(cmd, cfg)
+
+			commonCfg, err := helpers.GetCommonConfig(cmd, cfg)
+			if err != nil {
+				return fmt.Errorf("getting common config: %w", err)
+			}
+
+			if err := config.ApplyToConfigSetWithProvider(commonCfg.ConfigFile, cfg, app.RancherProviderName); err != nil {
+				return fmt.Errorf("applying app config: %w", err)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			zap.S().Debug("running `rancher tokens` command")
+
+			params := &app.RancherTokensInput{}
+
+			if err := config.Unmarshall(cfg, params); err != nil {
+				return fmt.Errorf("unmarshalling config into rancher tokens params: %w", err)
+			}
+
+			a := app.New()
+
+			return a.RancherTokens(cmd.Context(), params)
+		},
+	}
+	utils.FormatCommand(tokensCmd)
+
+	if err := addConfigTokens(cfg); err != nil {
+		return nil, fmt.Errorf("add tokens command config: %w", err)
+	}
+
+	if err := flags.CreateCommandFlags(tokensCmd, cfg); err != nil {
+		return nil, err
+	}
+
+	return tokensCmd, nil
+}
+
+func addConfigTokens(cs config.ConfigurationSet) error {
+	if err := app.AddCommonConfigItems(cs); err != nil {
+		return fmt.Errorf("adding common config: %w", err)
+	}
+
+	if err := rshared.AddCommonConfig(cs); err != nil {
+		return fmt.Errorf("adding rancher common config: %w", err)
+	}
+
+	if err := app.AddHTTPConfigItems(cs); err != nil {
+		return fmt.Errorf("adding http config items: %w", err)
+	}
+
+	if _, err := cs.String(tokenConfigItem, "", "A Rancher api token to use instead of the stored token"); err != nil {
+		return fmt.Errorf("adding token config item: %w", err)
+	}
+
+	cs.SetSensitive(tokenConfigItem) //nolint: errcheck
+
+	if _, err := cs.Bool("clean", false, "Delete the expired and unused tokens created on this machine"); err != nil {
+		return fmt.Errorf("adding clean config item: %w", err)
+	}
+
+	if _, err := cs.String("output", "table", "Output format for the results (table, json, yaml)"); err != nil {
+		return fmt.Errorf("adding output config item: %w", err)
+	}
+
+	if err := cs.SetShort("output", "o"); err != nil {
+		return fmt.Errorf("adding output short flag: %w", err)
+	}
+
+	return nil
+}
@@ -33,11 +33,16 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/fidelity/kconnect/internal/commands/alias"
+	"github.com/fidelity/kconnect/internal/commands/aws"
 	configcmd "github.com/fidelity/kconnect/internal/commands/config"
+	"github.com/fidelity/kconnect/internal/commands/discover"
+	"github.com/fidelity/kconnect/internal/commands/group"
 	"github.com/fidelity/kconnect/internal/commands/history"
 	"github.com/fidelity/kconnect/internal/commands/logout"
 	"github.com/fidelity/kconnect/internal/commands/ls"
+	"github.com/fidelity/kconnect/internal/commands/rancher"
 	"github.com/fidelity/kconnect/internal/commands/to"
+	"github.com/fidelity/kconnect/internal/commands/tunnel"
 	"github.com/fidelity/kconnect/internal/commands/use"
 	"github.com/fidelity/kconnect/internal/commands/version"
 	"github.com/fidelity/kconnect/internal/helpers"
@@ -46,6 +51,7 @@ import (
 	"github.com/fidelity/kconnect/pkg/config"
 	"github.com/fidelity/kconnect/pkg/defaults"
 	"github.com/fidelity/kconnect/pkg/flags"
+	khttp "github.com/fidelity/kconnect/pkg/http"
 	"github.com/fidelity/kconnect/pkg/utils"
 )
 
@@ -157,6 +163,10 @@ func RootCmd() (*cobra.Command, error) {
 				cmd.Flags().Set(app.NoInputConfigItem, "true") //nolint: errcheck
 			}
 
+			if err := configureHTTPTracing(cmd.Flags()); err != nil {
+				return err
+			}
+
 			checkPrereqs()
 
 			return nil
@@ -204,6 +214,32 @@ func RootCmd() (*cobra.Command, error) {
 	return rootCmd, nil
 }
 
+// configureHTTPTracing will enable the tracing of http requests if --trace-http or --har-file
+// are set, or the verbosity is high enough.
+func configureHTTPTracing(fs *pflag.FlagSet) error {
+	traceHTTP, err := fs.GetBool(app.TraceHTTPConfigItem)
+	if err != nil {
+		return fmt.Errorf("getting '--%s' flag: %w", app.TraceHTTPConfigItem, err)
+	}
+
+	harFile, err := fs.GetString(app.HARFileConfigItem)
+	if err != nil {
+		return fmt.Errorf("getting '--%s' flag: %w", app.HARFileConfigItem, err)
+	}
+
+	verbosity, err := fs.GetInt(app.VerbosityConfigItem)
+	if err != nil {
+		return fmt.Errorf("getting '--%s' flag: %w", app.VerbosityConfigItem, err)
+	}
+
+	khttp.ConfigureTracing(&khttp.TraceConfig{
+		Enabled: traceHTTP || verbosity >= khttp.TraceVerbosity,
+		HARFile: harFile,
+	})
+
+	return nil
+}
+
 func initConfig() {
 	viper.SetEnvPrefix("KCONNECT")
 	viper.AutomaticEnv()
@@ -217,6 +253,13 @@ func addRootCommands(rootCmd *cobra.Command) error {
 
 	rootCmd.AddCommand(useCmd)
 
+	discoverCmd, err := discover.Command()
+	if err != nil {
+		return fmt.Errorf("creating discover command: %w", err)
+	}
+
+	rootCmd.AddCommand(discoverCmd)
+
 	toCmd, err := to.Command()
 	if err != nil {
 		return fmt.Errorf("creating to command: %w", err)
@@ -260,6 +303,34 @@ func addRootCommands(rootCmd *cobra.Command) error {
 
 	rootCmd.AddCommand(historyCmd)
 
+	groupCmd, err := group.Command()
+	if err != nil {
+		return fmt.Errorf("creating group command: %w", err)
+	}
+
+	rootCmd.AddCommand(groupCmd)
+
+	rancherCmd, err := rancher.Command()
+	if err != nil {
+		return fmt.Errorf("creating rancher command: %w", err)
+	}
+
+	rootCmd.AddCommand(rancherCmd)
+
+	awsCmd, err := aws.Command()
+	if err != nil {
+		return fmt.Errorf("creating aws command: %w", err)
+	}
+
+	rootCmd.AddCommand(awsCmd)
+
+	tunnelCmd, err := tunnel.Command()
+	if err != nil {
+		return fmt.Errorf("creating tunnel command: %w", err)
+	}
+
+	rootCmd.AddCommand(tunnelCmd)
+
 	return nil
 }
 
@@ -47,10 +47,20 @@ the connection history entry's ID or alias.
 The to command also accepts - or LAST as proxy references to the most recent
 connection history entry, or LAST~N for the Nth previous entry.
 
+All the entries in a group can be connected to by prefixing the group name
+with @. Authentication is done once for each distinct identity used by the
+entries in the group and a summary of the result for each entry is displayed.
+A failure connecting to one entry doesn't stop the other entries being
+connected to.
+
 Although kconnect does not save the user's password in the connection history,
 the user can avoid having to enter their password interactively by setting the
 KCONNECT_PASSWORD environment variable or the --password command-line flag.
 Otherwise kconnect will promot the user to enter their password.
+
+When the entry uses SAML the assertion from the identity provider is cached
+until it expires, so reconnecting or switching roles within that time doesn't
+need another login or MFA prompt. Use --fresh-login to login again.
 `
 	examples = `
   # Reconnect based on an alias - aliases can be found using kconnect ls
@@ -70,11 +80,17 @@ Otherwise kconnect will promot the user to enter their password.
   # Reconnect to cluster used before current one
   {{.CommandPath}} to LAST~1
 
+  # Reconnect to all the entries in a group - groups can be found using kconnect group ls
+  {{.CommandPath}} to @oncall
+
   # Reconnect based on an alias supplying a password
   {{.CommandPath}} to uat-bu1 --password supersecret
 
   # Reconnect based on an alias supplying a password via env var
   KCONNECT_PASSWORD=supersecret {{.CommandPath}} to uat-bu2
+
+  # Reconnect based on an alias, logging in to the SAML IdP again instead of reusing the last assertion
+  {{.CommandPath}} to uat-bu1 --fresh-login
  `
 )
 
@@ -82,7 +98,7 @@ func Command() (*cobra.Command, error) {
 	cfg := config.NewConfigurationSet()
 
 	toCmd := &cobra.Command{
-		Use:     "to [historyid/alias/-/LAST/LAST~N]",
+		Use:     "to [historyid/alias/-/LAST/LAST~N/@group]",
 		Short:   shortDesc,
 		Long:    longDesc,
 		Example: examples,
@@ -152,6 +168,10 @@ func addConfig(cs config.ConfigurationSet) error {
 		return fmt.Errorf("adding password config: %w", err)
 	}
 
+	if _, err := cs.Bool("fresh-login", false, "Login to the identity provider again instead of reusing cached credentials (saml only)"); err != nil {
+		return fmt.Errorf("adding fresh-login config: %w", err)
+	}
+
 	if err := app.AddHistoryLocationItems(cs); err != nil {
 		return fmt.Errorf("adding history location items: %w", err)
 	}
@@ -160,8 +180,9 @@ func addConfig(cs config.ConfigurationSet) error {
 		return fmt.Errorf("adding kubeconfig config items: %w", err)
 	}
 
-	cs.SetHistoryIgnore("password") //nolint: errcheck
-	cs.SetSensitive("password")     //nolint: errcheck
+	cs.SetHistoryIgnore("password")    //nolint: errcheck
+	cs.SetHistoryIgnore("fresh-login") //nolint: errcheck
+	cs.SetSensitive("password")        //nolint: errcheck
 
 	return nil
 }
@@ -0,0 +1,177 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/internal/helpers"
+	"github.com/fidelity/kconnect/pkg/app"
+	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/defaults"
+	"github.com/fidelity/kconnect/pkg/flags"
+	"github.com/fidelity/kconnect/pkg/history"
+	"github.com/fidelity/kconnect/pkg/history/loader"
+	"github.com/fidelity/kconnect/pkg/utils"
+)
+
+var ErrEntryRequired = errors.New("alias or id must be specified")
+
+const (
+	shortDesc = "Tunnel the connections to a cluster through a jump host."
+	longDesc  = `
+Tunnel the connections to the cluster of a connection history entry through a
+jump host using SSH. This allows clusters with private endpoints to be reached
+from outside of their network.
+
+A local SOCKS5 and HTTP CONNECT proxy is started and the clusters in the
+kubeconfig that are used by the entry are changed to use it with proxy-url. The
+proxy forwards the connections through the jump host until the tunnel is
+stopped with Ctrl+C, when the previous proxy-url of the clusters is restored.
+
+The host key of the jump host is checked using the known hosts file. The ssh
+agent is used for authentication if it's running along with the ssh key file.
+
+The jump host and other tunnel settings can be set in the global section of
+the app configuration so that they don't need to be supplied each time.
+
+To use an existing proxy instead of a tunnel set --proxy-url with the use
+command. The --endpoint-rewrite flag of the use command can be used to change
+the host of the cluster endpoints, e.g. when private endpoints are resolved
+using a different DNS name from the corporate network.
+`
+	examples = `
+  # Tunnel the connections for an entry through a jump host
+  {{.CommandPath}} tunnel uat-bu1 --jump-host bastion.example.com
+
+  # Tunnel using a specific user, port and ssh key
+  {{.CommandPath}} tunnel uat-bu1 --jump-host ec2-user@bastion.example.com:2222 --ssh-key-file ~/.ssh/bastion
+
+  # Tunnel using a different local proxy port
+  {{.CommandPath}} tunnel uat-bu1 --jump-host bastion.example.com --listen-address 127.0.0.1:8118
+`
+)
+
+// Command creates the tunnel command
+func Command() (*cobra.Command, error) {
+	cfg := config.NewConfigurationSet()
+
+	tunnelCmd := &cobra.Command{
+		Use:     "tunnel [historyid/alias]",
+		Short:   shortDesc,
+		Long:    longDesc,
+		Example: examples,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			flags.BindFlags(cmd)
+			flags.PopulateConfigFromCommand(cmd, cfg)
+
+			commonCfg, err := helpers.GetCommonConfig(cmd, cfg)
+			if err != nil {
+				return fmt.Errorf("getting common config: %w", err)
+			}
+
+			if err := config.ApplyToConfigSet(commonCfg.ConfigFile, cfg); err != nil {
+				return fmt.Errorf("applying app config: %w", err)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			zap.S().Debug("running `tunnel` command")
+
+			params := &app.TunnelInput{
+				Entry: args[0],
+			}
+
+			if err := config.Unmarshall(cfg, params); err != nil {
+				return fmt.Errorf("unmarshalling config into tunnel params: %w", err)
+			}
+
+			if params.Entry == "" {
+				return ErrEntryRequired
+			}
+
+			historyLoader, err := loader.NewFileLoader(params.Location)
+			if err != nil {
+				return fmt.Errorf("getting history loader with path %s: %w", params.Location, err)
+			}
+
+			store, err := history.NewStore(defaults.MaxHistoryItems, historyLoader)
+			if err != nil {
+				return fmt.Errorf("creating history store: %w", err)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			a := app.New(app.WithHistoryStore(store))
+
+			return a.Tunnel(ctx, params)
+		},
+	}
+	utils.FormatCommand(tunnelCmd)
+
+	if err := addConfig(cfg); err != nil {
+		return nil, fmt.Errorf("add tunnel command config: %w", err)
+	}
+
+	if err := flags.CreateCommandFlags(tunnelCmd, cfg); err != nil {
+		return nil, err
+	}
+
+	return tunnelCmd, nil
+}
+
+func addConfig(cs config.ConfigurationSet) error {
+	if err := app.AddCommonConfigItems(cs); err != nil {
+		return fmt.Errorf("adding common config: %w", err)
+	}
+
+	if err := app.AddHistoryLocationItems(cs); err != nil {
+		return fmt.Errorf("adding history location items: %w", err)
+	}
+
+	if err := app.AddKubeconfigConfigItems(cs); err != nil {
+		return fmt.Errorf("adding kubeconfig config items: %w", err)
+	}
+
+	if _, err := cs.String("jump-host", "", "The jump host to tunnel through as [user@]host[:port]"); err != nil {
+		return fmt.Errorf("adding jump-host config item: %w", err)
+	}
+
+	if _, err := cs.String("ssh-key-file", "~/.ssh/id_rsa", "The private key used to authenticate with the jump host"); err != nil {
+		return fmt.Errorf("adding ssh-key-file config item: %w", err)
+	}
+
+	if _, err := cs.String("known-hosts-file", "~/.ssh/known_hosts", "The known hosts file used to check the host key of the jump host"); err != nil {
+		return fmt.Errorf("adding known-hosts-file config item: %w", err)
+	}
+
+	if _, err := cs.String("listen-address", "127.0.0.1:1080", "The local address the proxy listens on"); err != nil {
+		return fmt.Errorf("adding listen-address config item: %w", err)
+	}
+
+	return nil
+}
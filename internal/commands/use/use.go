@@ -20,7 +20,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -38,9 +37,8 @@ import (
 )
 
 var (
-	ErrMissingProvider    = errors.New("required provider name argument")
-	ErrMissingIdpProtocol = errors.New("missing idp protocol")
-	ErrMustBeDirectory    = errors.New("specified config directory is not a directory")
+	ErrMissingProvider = errors.New("required provider name argument")
+	ErrMustBeDirectory = errors.New("specified config directory is not a directory")
 )
 
 const (
@@ -62,6 +60,20 @@ token to connect to the chosen cluster and adds a connection history entry to
 store the chosen connection settings.  If given an alias name, kconnect will add
 a user-friendly alias to the new connection history entry.
 
+The --all flag can be used to generate contexts for all the discovered clusters
+instead of choosing a single cluster. A connection history entry is added for
+each cluster and an alias can be generated for each entry using --alias-template.
+The --cluster-filter flag can be used to limit the clusters by name and the
+--cluster-selector flag can be used to limit the clusters by their labels, version
+or status using a label selector. If some clusters can't be discovered the clusters
+that were discovered can still be chosen, unless the --strict flag is used.
+
+The names of the context, cluster and user in the kubeconfig can be set using the
+--context-name-template, --cluster-name-template and --user-name-template flags.
+The templates can use .Provider, .Identity, .ClusterName, .ClusterID, .Region,
+.Account, .Subscription, .Alias, .Username and .Labels and are saved in the
+connection history entry so they are used again when reconnecting.
+
 The user can then reconnect to the provider with the settings stored in the
 connection history entry using the kconnect to command and the connection history
 entry ID or alias.  When the user reconnects using a connection history entry,
@@ -94,6 +106,15 @@ specified cluster provider.
 
   # Connect to an EKS cluster and create an alias for its connection history entry.
   {{.CommandPath}} use eks --alias mycluster
+
+  # Generate contexts for all the EKS clusters whose name starts with dev-.
+  {{.CommandPath}} use eks --all --cluster-filter "^dev-" --alias-template "{{.Provider}}-{{.ClusterName}}"
+
+  # Choose from the active EKS clusters in a specific region.
+  {{.CommandPath}} use eks --cluster-selector "region=eu-west-2,status=ACTIVE"
+
+  # Name the context in the kubeconfig after the account, region and cluster.
+  {{.CommandPath}} use eks --context-name-template "{{.Account}}-{{.Region}}-{{.ClusterName}}"
 `
 	usageExampleFoot = `
   # Reconnect to a cluster by its connection history entry alias.
@@ -161,7 +182,7 @@ func createProviderCmd(registration *registry.DiscoveryPluginRegistration) (*cob
 		Long:    providerLongDesc,
 		Example: providerUsageExample,
 		AdditionalSetupE: func(cmd *cobra.Command, args []string) error {
-			if err := setupIdpProtocol(cmd, os.Args, params); err != nil {
+			if err := helpers.SetupIdpProtocol(cmd, os.Args, params); err != nil {
 				return fmt.Errorf("additional command setup: %w", err)
 			}
 
@@ -220,7 +241,7 @@ func createProviderCmd(registration *registry.DiscoveryPluginRegistration) (*cob
 		return nil, err
 	}
 
-	providerCmd.SetUsageFunc(providerUsage(registration.Name))
+	providerCmd.SetUsageFunc(helpers.ProviderUsage(registration.Name))
 
 	utils.FormatCommand(providerCmd)
 
@@ -274,73 +295,6 @@ func addConfig(cs config.ConfigurationSet, registration *registry.DiscoveryPlugi
 	return nil
 }
 
-func setupIdpProtocol(cmd *cobra.Command, args []string, params *app.UseInput) error {
-	idpProtocol, hasFlagValue, err := getIdpProtocol(args, params)
-	if err != nil {
-		return fmt.Errorf("getting idp-protocol: %w", err)
-	}
-
-	if idpProtocol == "" {
-		return ErrMissingIdpProtocol
-	}
-
-	params.IdpProtocol = idpProtocol
-	if !hasFlagValue {
-		// If the flag wasn't supplied and we are using a default then
-		// set the value on the commnads flag
-		cmd.Flags().Set("idp-protocol", idpProtocol) //nolint: errcheck
-	}
-
-	idProviderReg, err := registry.GetIdentityProviderRegistration(idpProtocol)
-	if err != nil {
-		return fmt.Errorf("getting identity provider registration for %s: %w", idpProtocol, err)
-	}
-
-	if err := params.ConfigSet.SetValue("idp-protocol", idpProtocol); err != nil {
-		return fmt.Errorf("setting idp-protocol value: %w", err)
-	}
-
-	params.IdentityProvider = idProviderReg.Name
-
-	idProviderCfg, err := idProviderReg.ConfigurationItemsFunc(params.DiscoveryProvider)
-	if err != nil {
-		return fmt.Errorf("getting config itemsd for %s: %w", idProviderReg.Name, err)
-	}
-
-	if err := params.ConfigSet.AddSet(idProviderCfg); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func getIdpProtocol(args []string, params *app.UseInput) (string, bool, error) {
-	// look for a flag first
-	for i, arg := range args {
-		if arg == "--idp-protocol" {
-			return args[i+1], true, nil
-		}
-	}
-
-	// look in app config
-	idProtocol, err := config.GetValue("idp-protocol", params.DiscoveryProvider)
-	if err != nil {
-		return "", false, fmt.Errorf("getting idp-protocol from config: %w", err)
-	}
-	// Default to the first supported provider if empty
-	if idProtocol == "" {
-		discoReg, err := registry.GetDiscoveryProviderRegistration(params.DiscoveryProvider)
-		if err != nil {
-			return "", false, err
-		}
-
-		idProtocol = discoReg.SupportedIdentityProviders[0]
-		zap.S().Debugw("no idp-protocol, using default for provider", "idp-protocol", idProtocol)
-	}
-
-	return idProtocol, false, nil
-}
-
 func ensureConfigFolder(path string) error {
 	info, err := os.Stat(path)
 	if err != nil && !os.IsNotExist(err) {
@@ -360,52 +314,3 @@ func ensureConfigFolder(path string) error {
 
 	return nil
 }
-
-func providerUsage(providerName string) func(cmd *cobra.Command) error {
-	return func(cmd *cobra.Command) error {
-		use := utils.FormatUse(cmd.UseLine())
-		usage := []string{fmt.Sprintf("Usage: %s", use)}
-
-		if cmd.Example != "" {
-			usage = append(usage, "\nExamples:")
-			usage = append(usage, cmd.Example)
-		}
-
-		usage = append(usage, "\nFlags:")
-		usage = append(usage, cmd.LocalFlags().FlagUsages())
-
-		usage = append(usage, "\nGlobal Flags:")
-		usage = append(usage, cmd.InheritedFlags().FlagUsages())
-
-		clusterProviderReg, err := registry.GetDiscoveryProviderRegistration(providerName)
-		if err != nil {
-			return err
-		}
-
-		for _, idProviderName := range clusterProviderReg.SupportedIdentityProviders {
-			idProviderReg, err := registry.GetIdentityProviderRegistration(idProviderName)
-			if err != nil {
-				return err
-			}
-
-			usage = append(usage, fmt.Sprintf("\n%s Flags:", strings.ToUpper(idProviderReg.Name)))
-			usage = append(usage, fmt.Sprintf("(use --idp-protocol=%s)\n", idProviderReg.Name))
-
-			cfg, err := idProviderReg.ConfigurationItemsFunc(providerName)
-			if err != nil {
-				return err
-			}
-
-			fs, err := flags.CreateFlagsFromConfig(cfg)
-			if err != nil {
-				return err
-			}
-
-			usage = append(usage, fs.FlagUsages())
-		}
-
-		cmd.Println(strings.Join(usage, "\n"))
-
-		return nil
-	}
-}
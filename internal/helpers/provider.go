@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/pkg/app"
+	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/flags"
+	"github.com/fidelity/kconnect/pkg/provider/registry"
+	"github.com/fidelity/kconnect/pkg/utils"
+)
+
+var (
+	ErrMissingIdpProtocol = errors.New("missing idp protocol")
+)
+
+// SetupIdpProtocol will determine the idp protocol to use for the discovery provider and add
+// the config items for the identity provider to the config set. If cmd is nil the default protocol
+// isn't set on the command flags.
+func SetupIdpProtocol(cmd *cobra.Command, args []string, params *app.UseInput) error {
+	idpProtocol, hasFlagValue, err := getIdpProtocol(args, params)
+	if err != nil {
+		return fmt.Errorf("getting idp-protocol: %w", err)
+	}
+
+	if idpProtocol == "" {
+		return ErrMissingIdpProtocol
+	}
+
+	params.IdpProtocol = idpProtocol
+	if !hasFlagValue && cmd != nil {
+		// If the flag wasn't supplied and we are using a default then
+		// set the value on the commnads flag
+		cmd.Flags().Set("idp-protocol", idpProtocol) //nolint: errcheck
+	}
+
+	idProviderReg, err := registry.GetIdentityProviderRegistration(idpProtocol)
+	if err != nil {
+		return fmt.Errorf("getting identity provider registration for %s: %w", idpProtocol, err)
+	}
+
+	if err := params.ConfigSet.SetValue("idp-protocol", idpProtocol); err != nil {
+		return fmt.Errorf("setting idp-protocol value: %w", err)
+	}
+
+	params.IdentityProvider = idProviderReg.Name
+
+	idProviderCfg, err := idProviderReg.ConfigurationItemsFunc(params.DiscoveryProvider)
+	if err != nil {
+		return fmt.Errorf("getting config itemsd for %s: %w", idProviderReg.Name, err)
+	}
+
+	if err := params.ConfigSet.AddSet(idProviderCfg); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func getIdpProtocol(args []string, params *app.UseInput) (string, bool, error) {
+	// look for a flag first
+	for i, arg := range args {
+		if arg == "--idp-protocol" {
+			return args[i+1], true, nil
+		}
+	}
+
+	// look in app config
+	idProtocol, err := config.GetValue("idp-protocol", params.DiscoveryProvider)
+	if err != nil {
+		return "", false, fmt.Errorf("getting idp-protocol from config: %w", err)
+	}
+	// Default to the first supported provider if empty
+	if idProtocol == "" {
+		discoReg, err := registry.GetDiscoveryProviderRegistration(params.DiscoveryProvider)
+		if err != nil {
+			return "", false, err
+		}
+
+		idProtocol = discoReg.SupportedIdentityProviders[0]
+		zap.S().Debugw("no idp-protocol, using default for provider", "idp-protocol", idProtocol)
+	}
+
+	return idProtocol, false, nil
+}
+
+// ProviderUsage returns a usage function for a provider command that includes the flags
+// of each of the supported identity providers
+func ProviderUsage(providerName string) func(cmd *cobra.Command) error {
+	return func(cmd *cobra.Command) error {
+		use := utils.FormatUse(cmd.UseLine())
+		usage := []string{fmt.Sprintf("Usage: %s", use)}
+
+		if cmd.Example != "" {
+			usage = append(usage, "\nExamples:")
+			usage = append(usage, cmd.Example)
+		}
+
+		usage = append(usage, "\nFlags:")
+		usage = append(usage, cmd.LocalFlags().FlagUsages())
+
+		usage = append(usage, "\nGlobal Flags:")
+		usage = append(usage, cmd.InheritedFlags().FlagUsages())
+
+		clusterProviderReg, err := registry.GetDiscoveryProviderRegistration(providerName)
+		if err != nil {
+			return err
+		}
+
+		for _, idProviderName := range clusterProviderReg.SupportedIdentityProviders {
+			idProviderReg, err := registry.GetIdentityProviderRegistration(idProviderName)
+			if err != nil {
+				return err
+			}
+
+			usage = append(usage, fmt.Sprintf("\n%s Flags:", strings.ToUpper(idProviderReg.Name)))
+			usage = append(usage, fmt.Sprintf("(use --idp-protocol=%s)\n", idProviderReg.Name))
+
+			cfg, err := idProviderReg.ConfigurationItemsFunc(providerName)
+			if err != nil {
+				return err
+			}
+
+			fs, err := flags.CreateFlagsFromConfig(cfg)
+			if err != nil {
+				return err
+			}
+
+			usage = append(usage, fs.FlagUsages())
+		}
+
+		cmd.Println(strings.Join(usage, "\n"))
+
+		return nil
+	}
+}
@@ -101,6 +101,26 @@ func WithHTTPClient(client khttp.Client) Option {
 	}
 }
 
+// httpClientFor returns the http client to use with the supplied http settings. The
+// app's http client is used if no settings have been supplied.
+func (a *App) httpClientFor(cfg *HTTPConfig) (khttp.Client, error) {
+	if cfg == nil || cfg.IsEmpty() {
+		return a.httpClient, nil
+	}
+
+	clientCfg, err := cfg.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("creating http client config: %w", err)
+	}
+
+	client, err := khttp.NewHTTPClientWithConfig(clientCfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating http client: %w", err)
+	}
+
+	return client, nil
+}
+
 func WithItemSelectorFunc(itemSelector provider.SelectItemFunc) Option {
 	return func(a *App) {
 		a.itemSelector = itemSelector
@@ -123,20 +143,17 @@ func WithInteractive(interactive bool) Option {
 // 1 cluster then it automatically selects it. If there are more than 1 cluster then
 // a selection is displayed and the user must choose one
 func DefaultSelectCluster(discoverOutput *discovery.DiscoverOutput) (*discovery.Cluster, error) {
-	clusterNameToID := make(map[string]string)
-	options := make([]string, 0, len(discoverOutput.Clusters))
+	options := make(map[string]string, len(discoverOutput.Clusters))
 
 	for _, cluster := range discoverOutput.Clusters {
-		clusterNameToID[cluster.Name] = cluster.ID
-		options = append(options, cluster.Name)
+		options[cluster.DisplayName()] = cluster.ID
 	}
 
-	clusterName, err := prompt.Choose("cluster", "Select a cluster", true, prompt.OptionsFromStringSlice(options))
+	clusterID, err := prompt.Choose("cluster", "Select a cluster", true, prompt.OptionsFromMap(options))
 	if err != nil {
 		return nil, fmt.Errorf("choosing cluster: %w", err)
 	}
 
-	clusterID := clusterNameToID[clusterName]
 	zap.S().Debugw("selected cluster", "id", clusterID)
 
 	return discoverOutput.Clusters[clusterID], nil
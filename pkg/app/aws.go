@@ -0,0 +1,549 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+
+	historyv1alpha "github.com/fidelity/kconnect/api/v1alpha1"
+	kaws "github.com/fidelity/kconnect/pkg/aws"
+	"github.com/fidelity/kconnect/pkg/aws/awsconfig"
+	"github.com/fidelity/kconnect/pkg/defaults"
+	"github.com/fidelity/kconnect/pkg/history"
+	"github.com/fidelity/kconnect/pkg/printer"
+	"github.com/fidelity/kconnect/pkg/provider/identity"
+)
+
+const (
+	// credentialProcessVersion is the version of the credential_process output format
+	credentialProcessVersion = 1
+	// credentialProcessExpiryMargin is how long before they expire that the cached
+	// credentials are refreshed, so that the AWS tools don't get credentials that
+	// expire whilst they are being used
+	credentialProcessExpiryMargin = 5 * time.Minute
+)
+
+// AWSCredentialProcessInput defines the inputs for AWSCredentialProcess
+type AWSCredentialProcessInput struct {
+	CommonConfig
+	HistoryLocationConfig
+
+	Entry        string `json:"entry"`
+	Password     string `json:"password"`
+	WriteProfile string `json:"write-profile"`
+}
+
+type credentialProcessOutput struct {
+	Version         int    `json:"Version"`
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken,omitempty"`
+	Expiration      string `json:"Expiration,omitempty"`
+}
+
+// AWSCredentialProcess will output the AWS credentials for a history entry in the format
+// expected by the credential_process setting of the AWS tools. If the cached credentials
+// have expired then the user is authenticated again using the identity of the entry.
+// When WriteProfile is set a profile that uses this command is written to the AWS config
+// file instead.
+func (a *App) AWSCredentialProcess(ctx context.Context, input *AWSCredentialProcessInput) error {
+	entry, err := a.getHistoryEntryByIDOrAlias(input.Entry)
+	if err != nil {
+		return fmt.Errorf("getting history entry: %w", err)
+	}
+
+	if entry == nil {
+		return history.ErrEntryNotFound
+	}
+
+	if entry.Spec.Provider != EKSProviderName {
+		return fmt.Errorf("entry %s uses provider %s: %w", input.Entry, entry.Spec.Provider, ErrNotAWSEntry)
+	}
+
+	if input.WriteProfile != "" {
+		return a.writeCredentialProcessProfile(input)
+	}
+
+	awsID, err := a.cachedEntryCredentials(entry)
+	if err != nil {
+		return err
+	}
+
+	if awsID == nil {
+		a.logger.Infow("authenticating to refresh aws credentials", "entry", entry.Name, "identity", entry.Spec.Identity)
+
+		awsID, err = a.authenticateEntry(ctx, input, entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	output := &credentialProcessOutput{
+		Version:         credentialProcessVersion,
+		AccessKeyID:     awsID.AWSAccessKey,
+		SecretAccessKey: awsID.AWSSecretKey,
+		SessionToken:    awsID.AWSSessionToken,
+	}
+	if output.SessionToken == "" {
+		output.SessionToken = awsID.AWSSecurityToken
+	}
+
+	if !awsID.Expires.IsZero() {
+		output.Expiration = awsID.Expires.UTC().Format(time.RFC3339)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(output); err != nil {
+		return fmt.Errorf("writing credentials: %w", err)
+	}
+
+	return nil
+}
+
+// cachedEntryCredentials returns the credentials stored in the AWS profile of the entry.
+// If there are no credentials or they are about to expire then nil is returned.
+func (a *App) cachedEntryCredentials(entry *historyv1alpha.HistoryEntry) (*kaws.Identity, error) {
+	profileName := entry.Spec.Flags["aws-profile"]
+	if profileName == "" {
+		a.logger.Debugw("no aws profile for entry", "entry", entry.Name)
+		return nil, nil
+	}
+
+	store, err := kaws.NewIdentityStore(profileName, entry.Spec.Identity, entry.Spec.Flags["aws-shared-credentials-file"])
+	if err != nil {
+		return nil, fmt.Errorf("creating aws identity store: %w", err)
+	}
+
+	exists, err := store.CredsExists()
+	if err != nil {
+		return nil, fmt.Errorf("checking aws profile %s: %w", profileName, err)
+	}
+
+	if !exists {
+		a.logger.Debugw("no credentials in aws profile", "profile", profileName)
+		return nil, nil
+	}
+
+	storedID, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading aws profile %s: %w", profileName, err)
+	}
+
+	awsID, ok := storedID.(*kaws.Identity)
+	if !ok {
+		return nil, fmt.Errorf("expected aws identity but got a %T: %w", storedID, ErrNotAWSEntry)
+	}
+
+	if !awsID.Expires.IsZero() && time.Now().Add(credentialProcessExpiryMargin).After(awsID.Expires) {
+		a.logger.Debugw("credentials in aws profile have expired", "profile", profileName, "expires", awsID.Expires)
+		return nil, nil
+	}
+
+	return awsID, nil
+}
+
+// authenticateEntry authenticates using the identity provider and the settings of the entry.
+// The identity providers save the credentials to the entry's profile.
+func (a *App) authenticateEntry(ctx context.Context, input *AWSCredentialProcessInput, entry *historyv1alpha.HistoryEntry) (*kaws.Identity, error) {
+	useParams, err := a.buildUseInputFromEntry(&ConnectToInput{
+		CommonConfig: input.CommonConfig,
+		Password:     input.Password,
+	}, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := a.httpClientFor(&useParams.HTTPConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	identityProvider, err := a.getIdentityProvider(&useParams.IdentityProvider, &useParams.DiscoveryProvider, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("getting identity provider: %w", err)
+	}
+
+	authOutput, err := identityProvider.Authenticate(ctx, &identity.AuthenticateInput{
+		ConfigSet: useParams.ConfigSet,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("authenticating using provider %s: %w", identityProvider.Name(), err)
+	}
+
+	awsID, ok := authOutput.Identity.(*kaws.Identity)
+	if !ok {
+		return nil, fmt.Errorf("expected aws identity but got a %T: %w", authOutput.Identity, ErrNotAWSEntry)
+	}
+
+	return awsID, nil
+}
+
+// writeCredentialProcessProfile writes a profile to the AWS config file that gets its
+// credentials by running the credential-process command for the entry.
+func (a *App) writeCredentialProcessProfile(input *AWSCredentialProcessInput) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("getting kconnect executable path: %w", err)
+	}
+
+	args := []string{executable, "aws", "credential-process", "--entry", input.Entry}
+	if input.Location != "" && input.Location != defaults.HistoryPath() {
+		args = append(args, "--history-location", input.Location)
+	}
+
+	if input.ConfigFile != "" && input.ConfigFile != defaults.ConfigPath() {
+		args = append(args, "--config", input.ConfigFile)
+	}
+
+	for i := range args {
+		args[i] = quoteCredentialProcessArg(args[i])
+	}
+
+	path, err := awsconfig.LocateSharedConfigFile()
+	if err != nil {
+		return fmt.Errorf("locating aws config file: %w", err)
+	}
+
+	command := strings.Join(args, " ")
+	if err := awsconfig.SetCredentialProcess(path, input.WriteProfile, command); err != nil {
+		return fmt.Errorf("writing aws profile %s: %w", input.WriteProfile, err)
+	}
+
+	a.logger.Infow("written aws profile", "profile", input.WriteProfile, "path", path, "credential_process", command)
+
+	return nil
+}
+
+// quoteCredentialProcessArg quotes an argument of the credential_process command if it contains
+// spaces. The AWS tools split the command using shell like rules.
+func quoteCredentialProcessArg(arg string) string {
+	if !strings.ContainsAny(arg, " \t\"") {
+		return arg
+	}
+
+	return `"` + strings.ReplaceAll(arg, `"`, `\"`) + `"`
+}
+
+// AWSTokenInput defines the inputs for AWSToken
+type AWSTokenInput struct {
+	CommonConfig
+
+	ClusterName string `json:"cluster-name"`
+	Region      string `json:"region"`
+	Profile     string `json:"profile"`
+}
+
+// AWSToken will output a token to authenticate with an EKS cluster as an ExecCredential,
+// so that it can be used as a kubectl exec credential plugin. The AWS credentials are
+// loaded from the profile, or the AWS environment variables if there is no profile.
+func (a *App) AWSToken(ctx context.Context, input *AWSTokenInput) error {
+	awsCfg, err := kaws.NewSession(input.Region, input.Profile, "", "", "", "")
+	if err != nil {
+		return fmt.Errorf("creating aws session: %w", err)
+	}
+
+	token, err := kaws.GetEKSToken(ctx, *awsCfg, input.ClusterName)
+	if err != nil {
+		return fmt.Errorf("getting token for cluster %s: %w", input.ClusterName, err)
+	}
+
+	execCredential := &clientauthv1beta1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clientauthv1beta1.SchemeGroupVersion.String(),
+			Kind:       "ExecCredential",
+		},
+		Status: &clientauthv1beta1.ExecCredentialStatus{
+			Token:               token.Token,
+			ExpirationTimestamp: &metav1.Time{Time: token.Expiration},
+		},
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(execCredential); err != nil {
+		return fmt.Errorf("writing token: %w", err)
+	}
+
+	return nil
+}
+
+// Status of the profiles listed by AWSProfilesList and AWSProfilesPrune
+const (
+	awsProfileActive       = "active"
+	awsProfileExpired      = "expired"
+	awsProfileUnreferenced = "unreferenced"
+	awsProfileDeleted      = "deleted"
+)
+
+// AWSProfilesInput defines the inputs for AWSProfilesList and AWSProfilesPrune
+type AWSProfilesInput struct {
+	CommonConfig
+	HistoryLocationConfig
+
+	CredentialsFile string                 `json:"aws-shared-credentials-file"`
+	Output          *printer.OutputPrinter `json:"output,omitempty"`
+}
+
+type awsProfileOutput struct {
+	Name         string   `json:"name"`
+	PrincipalARN string   `json:"principalARN,omitempty"`
+	Expires      string   `json:"expires,omitempty"`
+	Status       string   `json:"status"`
+	Entries      []string `json:"entries,omitempty"`
+}
+
+// AWSProfilesList will list the kconnect managed profiles in the AWS credentials file
+// and the history entries that use them.
+func (a *App) AWSProfilesList(ctx context.Context, input *AWSProfilesInput) error {
+	outputs, err := a.awsProfiles(input.CredentialsFile)
+	if err != nil {
+		return err
+	}
+
+	return printAWSProfiles(outputs, input.Output)
+}
+
+// AWSProfilesPrune will delete the kconnect managed profiles from the AWS credentials file
+// that have expired or aren't used by a history entry.
+func (a *App) AWSProfilesPrune(ctx context.Context, input *AWSProfilesInput) error {
+	outputs, err := a.pruneAWSProfiles(input.CredentialsFile)
+	if err != nil {
+		return err
+	}
+
+	return printAWSProfiles(outputs, input.Output)
+}
+
+// pruneAWSProfiles deletes the expired and unreferenced kconnect profiles and returns
+// all the kconnect profiles with their status. The keep profiles are never deleted.
+func (a *App) pruneAWSProfiles(credentialsFile string, keep ...string) ([]*awsProfileOutput, error) {
+	outputs, err := a.awsProfiles(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := awsCredentialsPath(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	toDelete := []string{}
+
+	for _, output := range outputs {
+		if slices.Contains(keep, output.Name) {
+			continue
+		}
+
+		if output.Status == awsProfileExpired || output.Status == awsProfileUnreferenced {
+			toDelete = append(toDelete, output.Name)
+		}
+	}
+
+	if err := awsconfig.DeleteProfiles(path, toDelete); err != nil {
+		return nil, fmt.Errorf("deleting aws profiles: %w", err)
+	}
+
+	for _, output := range outputs {
+		if slices.Contains(toDelete, output.Name) {
+			output.Status = awsProfileDeleted
+		}
+	}
+
+	a.logger.Infow("pruned aws profiles", "path", path, "deleted", len(toDelete))
+
+	return outputs, nil
+}
+
+// pruneAWSProfilesAfterUse prunes the kconnect profiles after connecting to EKS clusters
+// if it's enabled with the prune-aws-profiles setting. Each credentials file is pruned once
+// and the profiles used for the connections are kept even when they aren't in the history.
+// A failure is only logged as the connections have already succeeded.
+func (a *App) pruneAWSProfilesAfterUse(inputs ...*UseInput) {
+	inUse := map[string][]string{}
+	paths := []string{}
+
+	for _, input := range inputs {
+		if !awsProfilePruningEnabled(input) {
+			continue
+		}
+
+		path, err := awsCredentialsPath(input.ConfigSet.ValueString("aws-shared-credentials-file"))
+		if err != nil {
+			a.logger.Warnw("failed pruning aws profiles", "error", err.Error())
+			continue
+		}
+
+		if _, ok := inUse[path]; !ok {
+			paths = append(paths, path)
+		}
+
+		inUse[path] = append(inUse[path], input.ConfigSet.ValueString("aws-profile"))
+	}
+
+	for _, path := range paths {
+		if _, err := a.pruneAWSProfiles(path, inUse[path]...); err != nil {
+			a.logger.Warnw("failed pruning aws profiles", "path", path, "error", err.Error())
+		}
+	}
+}
+
+// awsProfilePruningEnabled returns true if the input is for an EKS connection with the
+// prune-aws-profiles setting enabled
+func awsProfilePruningEnabled(input *UseInput) bool {
+	if input.DiscoveryProvider != EKSProviderName || input.ConfigSet == nil {
+		return false
+	}
+
+	item := input.ConfigSet.Get(kaws.PruneProfilesConfigItem)
+	if item == nil {
+		return false
+	}
+
+	enabled, ok := item.Value.(bool)
+
+	return ok && enabled
+}
+
+// awsProfiles returns the kconnect profiles in the AWS credentials file with the history
+// entries that use them.
+func (a *App) awsProfiles(credentialsFile string) ([]*awsProfileOutput, error) {
+	path, err := awsCredentialsPath(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := awsconfig.ListProfiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("listing aws profiles: %w", err)
+	}
+
+	entries, err := a.historyStore.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("getting history entries: %w", err)
+	}
+
+	references := map[string][]string{}
+
+	for i := range entries.Items {
+		entry := &entries.Items[i]
+
+		profileName := entry.Spec.Flags["aws-profile"]
+		if profileName == "" {
+			continue
+		}
+
+		entryPath, err := awsCredentialsPath(entry.Spec.Flags["aws-shared-credentials-file"])
+		if err != nil {
+			return nil, err
+		}
+
+		if entryPath != path {
+			continue
+		}
+
+		name := entry.Name
+		if entry.Spec.Alias != nil && *entry.Spec.Alias != "" {
+			name = *entry.Spec.Alias
+		}
+
+		references[profileName] = append(references[profileName], name)
+	}
+
+	outputs := make([]*awsProfileOutput, 0, len(profiles))
+
+	for _, profile := range profiles {
+		output := &awsProfileOutput{
+			Name:         profile.Name,
+			PrincipalARN: profile.PrincipalARN,
+			Status:       awsProfileActive,
+			Entries:      references[profile.Name],
+		}
+
+		if !profile.Expires.IsZero() {
+			output.Expires = profile.Expires.UTC().Format(time.RFC3339)
+		}
+
+		switch {
+		case profile.IsExpired():
+			output.Status = awsProfileExpired
+		case len(output.Entries) == 0:
+			output.Status = awsProfileUnreferenced
+		}
+
+		outputs = append(outputs, output)
+	}
+
+	return outputs, nil
+}
+
+// awsCredentialsPath returns the path of the AWS credentials file to use
+func awsCredentialsPath(credentialsFile string) (string, error) {
+	if credentialsFile != "" {
+		return credentialsFile, nil
+	}
+
+	path, err := awsconfig.LocateConfigFile()
+	if err != nil {
+		return "", fmt.Errorf("locating aws credentials file: %w", err)
+	}
+
+	return path, nil
+}
+
+func printAWSProfiles(outputs []*awsProfileOutput, output *printer.OutputPrinter) error {
+	outputFormat := printer.OutputPrinterTable
+	if output != nil {
+		outputFormat = *output
+	}
+
+	objPrinter, err := printer.New(outputFormat)
+	if err != nil {
+		return fmt.Errorf("getting printer for output %s: %w", outputFormat, err)
+	}
+
+	if outputFormat != printer.OutputPrinterTable {
+		return objPrinter.Print(outputs, os.Stdout)
+	}
+
+	table := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: metav1.SchemeGroupVersion.String(),
+			Kind:       "Table",
+		},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Profile", Type: "string"},
+			{Name: "Principal", Type: "string"},
+			{Name: "Expires", Type: "string"},
+			{Name: "Status", Type: "string"},
+			{Name: "Entries", Type: "string"},
+		},
+	}
+
+	for _, profile := range outputs {
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []any{profile.Name, profile.PrincipalARN, profile.Expires, profile.Status, strings.Join(profile.Entries, ",")},
+		})
+	}
+
+	return objPrinter.Print(table, os.Stdout)
+}
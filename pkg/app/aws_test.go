@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	historyv1alpha "github.com/fidelity/kconnect/api/v1alpha1"
+	"github.com/fidelity/kconnect/pkg/aws/awsconfig"
+)
+
+func Test_PruneAWSProfiles(t *testing.T) {
+	testCases := []struct {
+		name          string
+		keep          string
+		expectStatus  string
+		expectRemains string
+	}{
+		{
+			name:          "prune expired and unreferenced",
+			expectStatus:  "kconnect-active=active,kconnect-expired=deleted,kconnect-inuse=deleted,kconnect-unreferenced=deleted",
+			expectRemains: "kconnect-active",
+		},
+		{
+			name:          "keep the profile in use",
+			keep:          "kconnect-inuse",
+			expectStatus:  "kconnect-active=active,kconnect-expired=deleted,kconnect-inuse=unreferenced,kconnect-unreferenced=deleted",
+			expectRemains: "kconnect-active,kconnect-inuse",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newTestApp(t)
+			path := writeTestCredentials(t)
+
+			for _, profile := range []string{"kconnect-active", "kconnect-expired"} {
+				entry := historyv1alpha.NewHistoryEntry()
+				entry.Spec.Provider = EKSProviderName
+				entry.Spec.ProviderID = profile
+				entry.Spec.Flags = map[string]string{
+					"aws-profile":                 profile,
+					"aws-shared-credentials-file": path,
+				}
+
+				if err := a.historyStore.Add(entry); err != nil {
+					t.Fatalf("adding history entry: %s", err)
+				}
+			}
+
+			outputs, err := a.pruneAWSProfiles(path, tc.keep)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			status := []string{}
+			for _, output := range outputs {
+				status = append(status, output.Name+"="+output.Status)
+			}
+
+			if actual := strings.Join(status, ","); actual != tc.expectStatus {
+				t.Fatalf("expected status %s but got %s", tc.expectStatus, actual)
+			}
+
+			profiles, err := awsconfig.ListProfiles(path)
+			if err != nil {
+				t.Fatalf("listing profiles: %s", err)
+			}
+
+			remains := []string{}
+			for _, profile := range profiles {
+				remains = append(remains, profile.Name)
+			}
+
+			if actual := strings.Join(remains, ","); actual != tc.expectRemains {
+				t.Fatalf("expected profiles %s but got %s", tc.expectRemains, actual)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading credentials: %s", err)
+			}
+
+			if !strings.Contains(string(data), "[default]") {
+				t.Fatal("expected profiles not managed by kconnect to be kept")
+			}
+		})
+	}
+}
+
+// writeTestCredentials writes an AWS credentials file with a profile that isn't
+// managed by kconnect and kconnect profiles that are active and expired
+func writeTestCredentials(t *testing.T) string {
+	t.Helper()
+
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	content := &strings.Builder{}
+	fmt.Fprintln(content, "[default]\naws_access_key_id = AKID")
+
+	for name, expires := range map[string]string{
+		"kconnect-active":       future,
+		"kconnect-expired":      past,
+		"kconnect-inuse":        future,
+		"kconnect-unreferenced": future,
+	} {
+		fmt.Fprintf(content, "\n[%s]\naws_access_key_id = AKID\nx_security_token_expires = %s\n", name, expires)
+	}
+
+	path := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(path, []byte(content.String()), 0600); err != nil {
+		t.Fatalf("writing credentials: %s", err)
+	}
+
+	return path
+}
@@ -18,9 +18,11 @@ package app
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/fidelity/kconnect/pkg/config"
 	"github.com/fidelity/kconnect/pkg/defaults"
+	khttp "github.com/fidelity/kconnect/pkg/http"
 	"github.com/fidelity/kconnect/pkg/printer"
 )
 
@@ -29,6 +31,9 @@ const (
 	NonInteractiveConfigItem = "non-interactive"
 	NoVersionCheckConfigItem = "no-version-check"
 	ConfigPathConfigItem     = "config"
+	VerbosityConfigItem      = "verbosity"
+	TraceHTTPConfigItem      = "trace-http"
+	HARFileConfigItem        = "har-file"
 )
 
 type HistoryLocationConfig struct {
@@ -103,6 +108,8 @@ type CommonConfig struct {
 	Verbosity           int    `json:"verbosity"`
 	NoInput             bool   `json:"no-input"`
 	DisableVersionCheck bool   `json:"no-version-check"`
+	TraceHTTP           bool   `json:"trace-http"`
+	HARFile             string `json:"har-file"`
 }
 
 func AddCommonConfigItems(cs config.ConfigurationSet) error {
@@ -126,19 +133,38 @@ func AddCommonConfigItems(cs config.ConfigurationSet) error {
 		return fmt.Errorf("adding non-version-check config: %w", err)
 	}
 
+	if _, err := cs.Bool(TraceHTTPConfigItem, false, "Log the full http requests and responses, with sensitive values redacted. Also enabled with a verbosity of 9 or greater"); err != nil {
+		return fmt.Errorf("adding trace-http config: %w", err)
+	}
+
+	if _, err := cs.String(HARFileConfigItem, "", "Path of a HAR file to write the http requests and responses to, with sensitive values redacted"); err != nil {
+		return fmt.Errorf("adding har-file config: %w", err)
+	}
+
 	cs.SetShort("verbosity", "v")                                       //nolint: errcheck
 	cs.SetHistoryIgnore(ConfigPathConfigItem)                           //nolint: errcheck
 	cs.SetHistoryIgnore("verbosity")                                    //nolint: errcheck
 	cs.SetHistoryIgnore(NonInteractiveConfigItem)                       //nolint: errcheck
 	cs.SetHistoryIgnore(NoInputConfigItem)                              //nolint: errcheck
 	cs.SetHistoryIgnore(NoVersionCheckConfigItem)                       //nolint: errcheck
+	cs.SetHistoryIgnore(TraceHTTPConfigItem)                            //nolint: errcheck
+	cs.SetHistoryIgnore(HARFileConfigItem)                              //nolint: errcheck
 	cs.SetDeprecated(NonInteractiveConfigItem, "please use --no-input") //nolint: errcheck
 
 	return nil
 }
 
 type CommonUseConfig struct {
-	Namespace string `json:"namespace,omitempty"`
+	Namespace       string `json:"namespace,omitempty"`
+	All             bool   `json:"all,omitempty"`
+	ClusterFilter   string `json:"cluster-filter,omitempty"`
+	ClusterSelector string `json:"cluster-selector,omitempty"`
+	Strict          bool   `json:"strict,omitempty"`
+	AliasTemplate   string `json:"alias-template,omitempty"`
+
+	ContextNameTemplate string `json:"context-name-template,omitempty"`
+	ClusterNameTemplate string `json:"cluster-name-template,omitempty"`
+	UserNameTemplate    string `json:"user-name-template,omitempty"`
 }
 
 func AddCommonUseConfigItems(cs config.ConfigurationSet) error {
@@ -146,11 +172,192 @@ func AddCommonUseConfigItems(cs config.ConfigurationSet) error {
 		return fmt.Errorf("adding config item: %w", err)
 	}
 
-	cs.SetShort("namespace", "n") //nolint: errcheck
+	if _, err := cs.Bool("all", false, "Generate contexts for all the discovered clusters. The current context isn't changed"); err != nil {
+		return fmt.Errorf("adding all config item: %w", err)
+	}
+
+	if err := AddClusterFilterConfigItems(cs); err != nil {
+		return err
+	}
+
+	if err := AddClusterEndpointConfigItems(cs); err != nil {
+		return err
+	}
+
+	if _, err := cs.String("alias-template", "", "Template used to generate an alias for each cluster when using --all (e.g. {{.Provider}}-{{.ClusterName}})"); err != nil {
+		return fmt.Errorf("adding alias-template config item: %w", err)
+	}
+
+	if _, err := cs.String("context-name-template", "", "Template used to name the context in the kubeconfig (e.g. {{.Provider}}-{{.Region}}-{{.ClusterName}})"); err != nil {
+		return fmt.Errorf("adding context-name-template config item: %w", err)
+	}
+
+	if _, err := cs.String("cluster-name-template", "", "Template used to name the cluster in the kubeconfig (e.g. {{.Provider}}-{{.ClusterName}})"); err != nil {
+		return fmt.Errorf("adding cluster-name-template config item: %w", err)
+	}
+
+	if _, err := cs.String("user-name-template", "", "Template used to name the user in the kubeconfig (e.g. {{.Username}}-{{.ClusterName}})"); err != nil {
+		return fmt.Errorf("adding user-name-template config item: %w", err)
+	}
+
+	if err := AddHTTPConfigItems(cs); err != nil {
+		return err
+	}
+
+	cs.SetShort("namespace", "n")         //nolint: errcheck
+	cs.SetHistoryIgnore("all")            //nolint: errcheck
+	cs.SetHistoryIgnore("alias-template") //nolint: errcheck
 
 	return nil
 }
 
+// AddClusterFilterConfigItems will add the config items used to filter the discovered clusters
+// and to control what happens when some clusters can't be discovered
+func AddClusterFilterConfigItems(cs config.ConfigurationSet) error {
+	if _, err := cs.String("cluster-filter", "", "Regex filter applied to the names of the discovered clusters"); err != nil {
+		return fmt.Errorf("adding cluster-filter config item: %w", err)
+	}
+
+	if _, err := cs.String("cluster-selector", "", "Label selector applied to the discovered clusters (e.g. region=eu-west-1,status=ACTIVE)"); err != nil {
+		return fmt.Errorf("adding cluster-selector config item: %w", err)
+	}
+
+	if _, err := cs.Bool("strict", false, "Fail if any cluster, or group of clusters, can't be discovered instead of warning and using the clusters that were discovered"); err != nil {
+		return fmt.Errorf("adding strict config item: %w", err)
+	}
+
+	cs.SetHistoryIgnore("cluster-filter")   //nolint: errcheck
+	cs.SetHistoryIgnore("cluster-selector") //nolint: errcheck
+	cs.SetHistoryIgnore("strict")           //nolint: errcheck
+
+	return nil
+}
+
+const (
+	ProxyURLConfigItem        = "proxy-url"
+	EndpointRewriteConfigItem = "endpoint-rewrite"
+)
+
+// ClusterEndpointConfig is the configuration used to change how the generated clusters in the
+// kubeconfig are reached. It is saved in the history entry and can be set per provider in the
+// app configuration.
+type ClusterEndpointConfig struct {
+	ProxyURL        string `json:"proxy-url,omitempty"`
+	EndpointRewrite string `json:"endpoint-rewrite,omitempty"`
+}
+
+// AddClusterEndpointConfigItems will add the config items used to change the endpoints of the
+// generated clusters
+func AddClusterEndpointConfigItems(cs config.ConfigurationSet) error {
+	if _, err := cs.String(ProxyURLConfigItem, "", "Proxy URL to set on the generated clusters in the kubeconfig (e.g. socks5://localhost:1080)"); err != nil {
+		return fmt.Errorf("adding %s config item: %w", ProxyURLConfigItem, err)
+	}
+
+	if _, err := cs.String(EndpointRewriteConfigItem, "", "Rules to rewrite the host of the cluster endpoints as regex=replacement, separated by ; (e.g. '^(.*)\\.internal$=$1.corp.example.com')"); err != nil {
+		return fmt.Errorf("adding %s config item: %w", EndpointRewriteConfigItem, err)
+	}
+
+	return nil
+}
+
+const (
+	HTTPTimeoutConfigItem    = "http-timeout"
+	HTTPRetriesConfigItem    = "http-retries"
+	HTTPProxyConfigItem      = "http-proxy"
+	HTTPNoProxyConfigItem    = "http-no-proxy"
+	HTTPCABundleConfigItem   = "http-ca-bundle"
+	HTTPClientCertConfigItem = "http-client-cert"
+	HTTPClientKeyConfigItem  = "http-client-key"
+)
+
+// HTTPConfig is the configuration of the http client used to call the Rancher api, the OIDC
+// config url and Azure AD. The AWS and Azure SDK clients and saml2aws don't use it and take
+// their proxy settings from the environment. It can be set globally or per provider in the
+// app configuration.
+type HTTPConfig struct {
+	HTTPTimeout    *time.Duration `json:"http-timeout,omitempty"`
+	HTTPRetries    *int           `json:"http-retries,omitempty"`
+	HTTPProxy      string         `json:"http-proxy,omitempty"`
+	HTTPNoProxy    string         `json:"http-no-proxy,omitempty"`
+	HTTPCABundle   string         `json:"http-ca-bundle,omitempty"`
+	HTTPClientCert string         `json:"http-client-cert,omitempty"`
+	HTTPClientKey  string         `json:"http-client-key,omitempty"`
+}
+
+// AddHTTPConfigItems will add the config items used to configure the http client
+func AddHTTPConfigItems(cs config.ConfigurationSet) error {
+	if _, err := cs.Duration(HTTPTimeoutConfigItem, khttp.DefaultTimeout, "Timeout for http requests made to Rancher, Azure AD and the OIDC config url"); err != nil {
+		return fmt.Errorf("adding %s config item: %w", HTTPTimeoutConfigItem, err)
+	}
+
+	if _, err := cs.Int(HTTPRetriesConfigItem, khttp.DefaultRetries, "Number of times a http request to Rancher, Azure AD or the OIDC config url is retried on a 429 or 5xx response"); err != nil {
+		return fmt.Errorf("adding %s config item: %w", HTTPRetriesConfigItem, err)
+	}
+
+	if _, err := cs.String(HTTPProxyConfigItem, "", "Proxy to use for http requests to Rancher, Azure AD and the OIDC config url. Defaults to the HTTPS_PROXY environment variable"); err != nil {
+		return fmt.Errorf("adding %s config item: %w", HTTPProxyConfigItem, err)
+	}
+
+	if _, err := cs.String(HTTPNoProxyConfigItem, "", "Comma separated list of hosts that shouldn't use the proxy. Defaults to the NO_PROXY environment variable"); err != nil {
+		return fmt.Errorf("adding %s config item: %w", HTTPNoProxyConfigItem, err)
+	}
+
+	if _, err := cs.String(HTTPCABundleConfigItem, "", "Path to a PEM file with additional certificate authorities to trust for http requests"); err != nil {
+		return fmt.Errorf("adding %s config item: %w", HTTPCABundleConfigItem, err)
+	}
+
+	if _, err := cs.String(HTTPClientCertConfigItem, "", "Path to a PEM client certificate to use for http requests"); err != nil {
+		return fmt.Errorf("adding %s config item: %w", HTTPClientCertConfigItem, err)
+	}
+
+	if _, err := cs.String(HTTPClientKeyConfigItem, "", "Path to the PEM private key of the http client certificate"); err != nil {
+		return fmt.Errorf("adding %s config item: %w", HTTPClientKeyConfigItem, err)
+	}
+
+	cs.SetHistoryIgnore(HTTPTimeoutConfigItem)    //nolint: errcheck
+	cs.SetHistoryIgnore(HTTPRetriesConfigItem)    //nolint: errcheck
+	cs.SetHistoryIgnore(HTTPProxyConfigItem)      //nolint: errcheck
+	cs.SetHistoryIgnore(HTTPNoProxyConfigItem)    //nolint: errcheck
+	cs.SetHistoryIgnore(HTTPCABundleConfigItem)   //nolint: errcheck
+	cs.SetHistoryIgnore(HTTPClientCertConfigItem) //nolint: errcheck
+	cs.SetHistoryIgnore(HTTPClientKeyConfigItem)  //nolint: errcheck
+
+	return nil
+}
+
+// IsEmpty returns true if none of the http settings have been changed from the defaults
+func (c *HTTPConfig) IsEmpty() bool {
+	return (c.HTTPTimeout == nil || *c.HTTPTimeout == khttp.DefaultTimeout) &&
+		(c.HTTPRetries == nil || *c.HTTPRetries == khttp.DefaultRetries) &&
+		c.HTTPProxy == "" && c.HTTPNoProxy == "" && c.HTTPCABundle == "" &&
+		c.HTTPClientCert == "" && c.HTTPClientKey == ""
+}
+
+// ClientConfig converts the settings into the configuration for a http client. Any
+// settings that haven't been supplied use the defaults.
+func (c *HTTPConfig) ClientConfig() (*khttp.ClientConfig, error) {
+	cfg := khttp.DefaultClientConfig()
+	cfg.Proxy = c.HTTPProxy
+	cfg.NoProxy = c.HTTPNoProxy
+	cfg.CABundle = c.HTTPCABundle
+	cfg.ClientCert = c.HTTPClientCert
+	cfg.ClientKey = c.HTTPClientKey
+
+	if c.HTTPTimeout != nil {
+		cfg.Timeout = *c.HTTPTimeout
+	}
+
+	if c.HTTPRetries != nil {
+		if *c.HTTPRetries < 0 {
+			return nil, fmt.Errorf("using %s value %d: %w", HTTPRetriesConfigItem, *c.HTTPRetries, ErrInvalidHTTPRetries)
+		}
+
+		cfg.Retries = *c.HTTPRetries
+	}
+
+	return cfg, nil
+}
+
 type HistoryIdentifierConfig struct {
 	Alias string `json:"alias,omitempty"`
 	ID    string `json:"id,omitempty"`
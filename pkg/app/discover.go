@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fidelity/kconnect/pkg/printer"
+	"github.com/fidelity/kconnect/pkg/provider/discovery"
+)
+
+// DiscoverInput is the input for discovering clusters with 1 or more providers
+type DiscoverInput struct {
+	Output printer.OutputPrinter
+
+	// Providers contains the input for each discovery provider. The same input
+	// as use is reused so that the flags and identity flow are the same.
+	Providers []*UseInput
+}
+
+// Discover will discover the clusters that the users identity has access to using
+// each of the discovery providers and then print them. No cluster is selected and
+// the kubeconfig and history aren't changed.
+func (a *App) Discover(ctx context.Context, input *DiscoverInput) error {
+	objPrinter, err := printer.New(input.Output)
+	if err != nil {
+		return fmt.Errorf("getting printer for output %s: %w", input.Output, err)
+	}
+
+	outputs := []*discovery.DiscoverOutput{}
+	failed := 0
+
+	for _, providerInput := range input.Providers {
+		discoverOutput, err := a.discoverWithProvider(ctx, providerInput)
+		if err != nil {
+			a.logger.Errorw("failed discovering clusters", "provider", providerInput.DiscoveryProvider, "error", err.Error())
+			failed++
+
+			continue
+		}
+
+		outputs = append(outputs, discoverOutput)
+	}
+
+	if input.Output == printer.OutputPrinterTable {
+		err = objPrinter.Print(discovery.ToTable(outputs), os.Stdout)
+	} else {
+		err = objPrinter.Print(outputs, os.Stdout)
+	}
+
+	if err != nil {
+		return fmt.Errorf("printing discovered clusters: %w", err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("discovering clusters for %d of %d providers: %w", failed, len(input.Providers), ErrDiscoverFailed)
+	}
+
+	return nil
+}
+
+func (a *App) discoverWithProvider(ctx context.Context, input *UseInput) (*discovery.DiscoverOutput, error) {
+	clusterProvider, userID, err := a.authenticateAndResolve(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.discoverClusters(ctx, clusterProvider, userID, input)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	endpointRewriteSeparator = ";"
+)
+
+// endpointRewriteRule will replace the host of a cluster endpoint that matches the regex
+type endpointRewriteRule struct {
+	host        *regexp.Regexp
+	replacement string
+}
+
+// parseEndpointRewriteRules will parse the rules in the format regex=replacement separated by ;
+func parseEndpointRewriteRules(rules string) ([]*endpointRewriteRule, error) {
+	parsed := []*endpointRewriteRule{}
+
+	for _, rule := range strings.Split(rules, endpointRewriteSeparator) {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		expr, replacement, found := strings.Cut(rule, "=")
+		if !found || expr == "" || replacement == "" {
+			return nil, fmt.Errorf("parsing rule %s: %w", rule, ErrInvalidEndpointRewrite)
+		}
+
+		hostRegex, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("compiling endpoint rewrite regex %s: %w", expr, err)
+		}
+
+		parsed = append(parsed, &endpointRewriteRule{host: hostRegex, replacement: replacement})
+	}
+
+	return parsed, nil
+}
+
+// applyClusterEndpointConfig will rewrite the server of each cluster in the kubeconfig using the first
+// matching rewrite rule and then set the proxy url. When the host is rewritten the original host is
+// used as the TLS server name so that the certificate of the cluster is still valid.
+func applyClusterEndpointConfig(kubeConfig *api.Config, cfg *ClusterEndpointConfig) error {
+	if cfg.ProxyURL == "" && cfg.EndpointRewrite == "" {
+		return nil
+	}
+
+	if err := validateProxyURL(cfg.ProxyURL); err != nil {
+		return err
+	}
+
+	rules, err := parseEndpointRewriteRules(cfg.EndpointRewrite)
+	if err != nil {
+		return err
+	}
+
+	for _, cluster := range kubeConfig.Clusters {
+		if err := rewriteClusterServer(cluster, rules); err != nil {
+			return err
+		}
+
+		if cfg.ProxyURL != "" {
+			cluster.ProxyURL = cfg.ProxyURL
+		}
+	}
+
+	return nil
+}
+
+func rewriteClusterServer(cluster *api.Cluster, rules []*endpointRewriteRule) error {
+	if len(rules) == 0 || cluster.Server == "" {
+		return nil
+	}
+
+	serverURL, err := url.Parse(cluster.Server)
+	if err != nil {
+		return fmt.Errorf("parsing cluster server %s: %w", cluster.Server, err)
+	}
+
+	host := serverURL.Hostname()
+
+	for _, rule := range rules {
+		if !rule.host.MatchString(host) {
+			continue
+		}
+
+		newHost := rule.host.ReplaceAllString(host, rule.replacement)
+		if port := serverURL.Port(); port != "" {
+			serverURL.Host = net.JoinHostPort(newHost, port)
+		} else {
+			serverURL.Host = newHost
+		}
+
+		if cluster.TLSServerName == "" {
+			cluster.TLSServerName = host
+		}
+
+		cluster.Server = serverURL.String()
+
+		return nil
+	}
+
+	return nil
+}
+
+func validateProxyURL(proxyURL string) error {
+	if proxyURL == "" {
+		return nil
+	}
+
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("parsing proxy url %s: %w", proxyURL, err)
+	}
+
+	switch parsed.Scheme {
+	case "http", "https", "socks5":
+		if parsed.Host == "" {
+			return fmt.Errorf("%s: %w", proxyURL, ErrInvalidProxyURL)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("%s: %w", proxyURL, ErrInvalidProxyURL)
+	}
+}
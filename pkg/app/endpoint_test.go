@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func Test_ApplyClusterEndpointConfig(t *testing.T) {
+	testCases := []struct {
+		name          string
+		server        string
+		cfg           ClusterEndpointConfig
+		expectServer  string
+		expectTLSName string
+		expectProxy   string
+		expectErr     bool
+	}{
+		{
+			name:         "no config",
+			server:       "https://api.dev.internal:6443",
+			expectServer: "https://api.dev.internal:6443",
+		},
+		{
+			name:         "proxy url",
+			server:       "https://api.dev.internal:6443",
+			cfg:          ClusterEndpointConfig{ProxyURL: "socks5://localhost:1080"},
+			expectServer: "https://api.dev.internal:6443",
+			expectProxy:  "socks5://localhost:1080",
+		},
+		{
+			name:      "unsupported proxy scheme",
+			server:    "https://api.dev.internal:6443",
+			cfg:       ClusterEndpointConfig{ProxyURL: "ftp://localhost:21"},
+			expectErr: true,
+		},
+		{
+			name:          "rewrite host keeps port",
+			server:        "https://api.dev.internal:6443",
+			cfg:           ClusterEndpointConfig{EndpointRewrite: `^(.*)\.internal$=$1.corp.example.com`},
+			expectServer:  "https://api.dev.corp.example.com:6443",
+			expectTLSName: "api.dev.internal",
+		},
+		{
+			name:          "first matching rule is used",
+			server:        "https://abc.gr7.eu-west-2.eks.amazonaws.com",
+			cfg:           ClusterEndpointConfig{EndpointRewrite: `nomatch=other;\.eks\.amazonaws\.com$=.eks.corp;.*=last`},
+			expectServer:  "https://abc.gr7.eu-west-2.eks.corp",
+			expectTLSName: "abc.gr7.eu-west-2.eks.amazonaws.com",
+		},
+		{
+			name:         "no matching rule",
+			server:       "https://api.dev.internal:6443",
+			cfg:          ClusterEndpointConfig{EndpointRewrite: `^prod=test`},
+			expectServer: "https://api.dev.internal:6443",
+		},
+		{
+			name:          "rewrite and proxy",
+			server:        "https://api.dev.internal",
+			cfg:           ClusterEndpointConfig{EndpointRewrite: `internal$=corp`, ProxyURL: "http://proxy:3128"},
+			expectServer:  "https://api.dev.corp",
+			expectTLSName: "api.dev.internal",
+			expectProxy:   "http://proxy:3128",
+		},
+		{
+			name:      "invalid rule",
+			server:    "https://api.dev.internal",
+			cfg:       ClusterEndpointConfig{EndpointRewrite: `internal`},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			kubeConfig := api.NewConfig()
+			kubeConfig.Clusters["test"] = &api.Cluster{Server: tc.server}
+
+			err := applyClusterEndpointConfig(kubeConfig, &tc.cfg)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			cluster := kubeConfig.Clusters["test"]
+			if cluster.Server != tc.expectServer {
+				t.Fatalf("expected server %s but got %s", tc.expectServer, cluster.Server)
+			}
+
+			if cluster.TLSServerName != tc.expectTLSName {
+				t.Fatalf("expected tls server name %s but got %s", tc.expectTLSName, cluster.TLSServerName)
+			}
+
+			if cluster.ProxyURL != tc.expectProxy {
+				t.Fatalf("expected proxy url %s but got %s", tc.expectProxy, cluster.ProxyURL)
+			}
+		})
+	}
+}
@@ -33,4 +33,18 @@ var (
 	ErrDiscoveryProviderRequired = errors.New("discovery provider required")
 	ErrIdentityProviderRequired  = errors.New("identity provider required")
 	ErrUnsuportedIdpProtocol     = errors.New("unsupported idp protocol")
+	ErrGroupNameRequired         = errors.New("group name is required")
+	ErrGroupEntriesRequired      = errors.New("at least 1 entry is required for a group")
+	ErrGroupNotFound             = errors.New("group not found")
+	ErrGroupConnectFailed        = errors.New("failed connecting to group entries")
+	ErrContextNotFound           = errors.New("context not found in kubeconfig")
+	ErrEmptyTemplateName         = errors.New("template generated an empty name")
+	ErrUseAllFailed              = errors.New("failed generating kubeconfig for all clusters")
+	ErrDiscoverFailed            = errors.New("failed discovering clusters for all providers")
+	ErrNoRancherToken            = errors.New("no Rancher token, login using the use command or supply a token")
+	ErrRancherTokensCleanFailed  = errors.New("failed deleting Rancher tokens")
+	ErrInvalidHTTPRetries        = errors.New("http retries must be a number greater than or equal to 0")
+	ErrNotAWSEntry               = errors.New("history entry doesn't use aws credentials")
+	ErrInvalidProxyURL           = errors.New("proxy url must be a http, https or socks5 url")
+	ErrInvalidEndpointRewrite    = errors.New("endpoint rewrite rules must be regex=replacement")
 )
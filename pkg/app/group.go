@@ -0,0 +1,570 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	historyv1alpha "github.com/fidelity/kconnect/api/v1alpha1"
+	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/history"
+	"github.com/fidelity/kconnect/pkg/k8s/kubeconfig"
+	"github.com/fidelity/kconnect/pkg/printer"
+	"github.com/fidelity/kconnect/pkg/provider/common"
+	"github.com/fidelity/kconnect/pkg/provider/discovery"
+	"github.com/fidelity/kconnect/pkg/provider/identity"
+	"github.com/fidelity/kconnect/pkg/provider/registry"
+)
+
+const (
+	// GroupPrefix is the prefix used with the to command to indicate
+	// that a group should be connected to
+	GroupPrefix = "@"
+)
+
+// GroupListInput defines the inputs for GroupList
+type GroupListInput struct {
+	CommonConfig
+
+	Output *printer.OutputPrinter `json:"output,omitempty"`
+}
+
+// GroupAddInput defines the inputs for GroupAdd
+type GroupAddInput struct {
+	CommonConfig
+	HistoryLocationConfig
+
+	Name    string
+	Entries []string
+}
+
+// GroupRemoveInput defines the inputs for GroupRemove
+type GroupRemoveInput struct {
+	CommonConfig
+
+	Name    string
+	Entries []string
+}
+
+// GroupList implements the group listing functionality
+func (a *App) GroupList(ctx context.Context, input *GroupListInput) error {
+	zap.S().Debug("listing groups")
+
+	appConfig, err := getAppConfiguration(input.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := appConfig.Get()
+	if err != nil {
+		return fmt.Errorf("getting app config: %w", err)
+	}
+
+	groups := cfg.Spec.Groups
+	if groups == nil {
+		groups = map[string][]string{}
+	}
+
+	objPrinter, err := printer.New(*input.Output)
+	if err != nil {
+		return fmt.Errorf("getting printer for output %s: %w", *input.Output, err)
+	}
+
+	if *input.Output == printer.OutputPrinterTable {
+		return objPrinter.Print(groupsToTable(groups), os.Stdout)
+	}
+
+	return objPrinter.Print(groups, os.Stdout)
+}
+
+// GroupAdd will add connection history entries to a group. If the
+// group doesn't exist it will be created.
+func (a *App) GroupAdd(ctx context.Context, input *GroupAddInput) error {
+	name := strings.TrimPrefix(input.Name, GroupPrefix)
+	zap.S().Infow("adding entries to group", "group", name, "entries", input.Entries)
+
+	if name == "" {
+		return ErrGroupNameRequired
+	}
+
+	if len(input.Entries) == 0 {
+		return ErrGroupEntriesRequired
+	}
+
+	for _, member := range input.Entries {
+		entry, err := a.getHistoryEntryByIDOrAlias(member)
+		if err != nil {
+			return err
+		}
+
+		if entry == nil {
+			return fmt.Errorf("getting history entry %s: %w", member, history.ErrEntryNotFound)
+		}
+	}
+
+	appConfig, err := getAppConfiguration(input.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := appConfig.Get()
+	if err != nil {
+		return fmt.Errorf("getting app config: %w", err)
+	}
+
+	if cfg.Spec.Groups == nil {
+		cfg.Spec.Groups = map[string][]string{}
+	}
+
+	members := cfg.Spec.Groups[name]
+	for _, member := range input.Entries {
+		if !slices.Contains(members, member) {
+			members = append(members, member)
+		}
+	}
+
+	cfg.Spec.Groups[name] = members
+
+	if err := appConfig.Save(cfg); err != nil {
+		return fmt.Errorf("saving app config: %w", err)
+	}
+
+	zap.S().Infof("Command to connect to all the entries in the group: kconnect to %s%s", GroupPrefix, name)
+
+	return nil
+}
+
+// GroupRemove will remove entries from a group. If no entries are
+// specified then the whole group is removed.
+func (a *App) GroupRemove(ctx context.Context, input *GroupRemoveInput) error {
+	name := strings.TrimPrefix(input.Name, GroupPrefix)
+	zap.S().Infow("removing from group", "group", name, "entries", input.Entries)
+
+	if name == "" {
+		return ErrGroupNameRequired
+	}
+
+	appConfig, err := getAppConfiguration(input.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := appConfig.Get()
+	if err != nil {
+		return fmt.Errorf("getting app config: %w", err)
+	}
+
+	members, ok := cfg.Spec.Groups[name]
+	if !ok {
+		return fmt.Errorf("getting group %s: %w", name, ErrGroupNotFound)
+	}
+
+	if len(input.Entries) == 0 {
+		delete(cfg.Spec.Groups, name)
+	} else {
+		members = slices.DeleteFunc(members, func(member string) bool {
+			return slices.Contains(input.Entries, member)
+		})
+		cfg.Spec.Groups[name] = members
+	}
+
+	if err := appConfig.Save(cfg); err != nil {
+		return fmt.Errorf("saving app config: %w", err)
+	}
+
+	return nil
+}
+
+// groupConnection holds the state of connecting to a single entry in a group
+type groupConnection struct {
+	member  string
+	entry   *historyv1alpha.HistoryEntry
+	input   *UseInput
+	id      identity.Identity
+	cluster *discovery.Cluster
+	output  *discovery.GetConfigOutput
+
+	clusterProvider discovery.Provider
+	err             error
+}
+
+type groupIdentity struct {
+	id  identity.Identity
+	err error
+}
+
+// connectToGroup will connect to all the history entries in a group. Authentication
+// is done once per distinct identity and the clusters configs are then generated
+// concurrently. A failure for one entry doesn't stop the other entries being connected.
+func (a *App) connectToGroup(ctx context.Context, params *ConnectToInput, groupName string) error {
+	a.logger.Infow("connecting to group", "group", groupName)
+
+	appConfig, err := getAppConfiguration(params.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := appConfig.Get()
+	if err != nil {
+		return fmt.Errorf("getting app config: %w", err)
+	}
+
+	members, ok := cfg.Spec.Groups[groupName]
+	if !ok {
+		return fmt.Errorf("getting group %s: %w", groupName, ErrGroupNotFound)
+	}
+
+	connections := a.buildGroupConnections(params, members)
+	a.authenticateGroup(ctx, connections)
+	a.getGroupClusterConfigs(ctx, connections)
+	a.writeGroupConfigs(params, connections)
+
+	if err := printGroupSummary(connections); err != nil {
+		return fmt.Errorf("printing group summary: %w", err)
+	}
+
+	failed := 0
+	connected := []*UseInput{}
+
+	for _, conn := range connections {
+		if conn.err != nil {
+			failed++
+			continue
+		}
+
+		connected = append(connected, conn.input)
+	}
+
+	a.pruneAWSProfilesAfterUse(connected...)
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d entries in group %s: %w", failed, len(connections), groupName, ErrGroupConnectFailed)
+	}
+
+	return nil
+}
+
+func (a *App) buildGroupConnections(params *ConnectToInput, members []string) []*groupConnection {
+	connections := []*groupConnection{}
+	seen := map[string]bool{}
+
+	for _, member := range members {
+		conn := &groupConnection{member: member}
+
+		entry, err := a.getHistoryEntryByIDOrAlias(member)
+		if err == nil && entry == nil {
+			err = history.ErrEntryNotFound
+		}
+
+		if err != nil {
+			conn.err = err
+			connections = append(connections, conn)
+
+			continue
+		}
+
+		if seen[entry.Name] {
+			a.logger.Debugw("skipping duplicate group entry", "member", member, "id", entry.Name)
+			continue
+		}
+
+		seen[entry.Name] = true
+
+		conn.entry = entry
+		conn.input, conn.err = a.buildUseInputFromEntry(params, entry)
+		connections = append(connections, conn)
+	}
+
+	return connections
+}
+
+// authenticateGroup will authenticate each distinct identity in the group once
+// and then resolve the config for each of the entries. This is done sequentially
+// as it may require user interaction.
+func (a *App) authenticateGroup(ctx context.Context, connections []*groupConnection) {
+	identities := map[string]*groupIdentity{}
+
+	for _, conn := range connections {
+		if conn.err != nil {
+			continue
+		}
+
+		identityProvider, clusterProvider, err := a.getUseProviders(conn.input)
+		if err != nil {
+			conn.err = err
+			continue
+		}
+
+		conn.clusterProvider = clusterProvider
+
+		key, err := groupIdentityKey(conn.entry)
+		if err != nil {
+			conn.err = err
+			continue
+		}
+
+		groupID, ok := identities[key]
+		if !ok {
+			a.logger.Debugw("authenticating group identity", "provider", identityProvider.Name(), "entry", conn.entry.Name)
+
+			groupID = &groupIdentity{}
+
+			authOutput, err := identityProvider.Authenticate(ctx, &identity.AuthenticateInput{
+				ConfigSet: conn.input.ConfigSet,
+			})
+			if err != nil {
+				groupID.err = fmt.Errorf("authenticating using provider %s: %w", identityProvider.Name(), err)
+			} else {
+				groupID.id = authOutput.Identity
+			}
+
+			identities[key] = groupID
+		}
+
+		if groupID.err != nil {
+			conn.err = groupID.err
+			continue
+		}
+
+		conn.id = groupID.id
+
+		if err := clusterProvider.Resolve(conn.input.ConfigSet, conn.id); err != nil {
+			conn.err = fmt.Errorf("resolving config items: %w", err)
+		}
+	}
+}
+
+// getGroupClusterConfigs will get the cluster and generate the kubeconfig for each
+// of the entries in the group concurrently.
+func (a *App) getGroupClusterConfigs(ctx context.Context, connections []*groupConnection) {
+	var wg sync.WaitGroup
+
+	for _, conn := range connections {
+		if conn.err != nil {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(conn *groupConnection) {
+			defer wg.Done()
+
+			conn.cluster, conn.err = a.findCluster(ctx, conn.clusterProvider, conn.id, conn.input)
+			if conn.err == nil && conn.cluster == nil {
+				conn.err = ErrClusterNotFound
+			}
+
+			if conn.err == nil {
+				conn.output, conn.err = a.getClusterConfig(ctx, conn.clusterProvider, conn.id, conn.input, conn.cluster)
+			}
+		}(conn)
+	}
+
+	wg.Wait()
+}
+
+// writeGroupConfigs will update the history and then write the generated kubeconfigs. The
+// configs are merged so that there is a single write per kubeconfig file.
+func (a *App) writeGroupConfigs(params *ConnectToInput, connections []*groupConnection) {
+	configs := map[string]*api.Config{}
+	pathConnections := map[string][]*groupConnection{}
+	paths := []string{}
+
+	for _, conn := range connections {
+		if conn.err != nil {
+			continue
+		}
+
+		if err := applyNamingTemplates(conn.input, conn.cluster, conn.id, conn.output); err != nil {
+			conn.err = fmt.Errorf("applying naming templates: %w", err)
+			continue
+		}
+
+		historyID, err := a.addHistoryEntry(conn.input, conn.cluster)
+		if err != nil {
+			conn.err = err
+			continue
+		}
+
+		kubeConfig := conn.output.KubeConfig
+		contextName := *conn.output.ContextName
+		addHistoryReference(kubeConfig, contextName, historyID)
+
+		path := conn.input.Kubeconfig
+		if path == "" {
+			path = clientcmd.NewDefaultPathOptions().GetDefaultFilename()
+		}
+
+		merged, ok := configs[path]
+		if !ok {
+			merged = api.NewConfig()
+			merged.CurrentContext = contextName
+			configs[path] = merged
+			paths = append(paths, path)
+		}
+
+		mergeKubeconfig(merged, kubeConfig)
+
+		pathConnections[path] = append(pathConnections[path], conn)
+	}
+
+	for _, path := range paths {
+		if err := kubeconfig.Write(path, configs[path], true, params.SetCurrent); err != nil {
+			for _, conn := range pathConnections[path] {
+				conn.err = fmt.Errorf("writing cluster kubeconfig: %w", err)
+			}
+		}
+	}
+}
+
+// groupIdentityKey creates a key that represents the identity used for a history
+// entry. Entries with the same key can share the same authenticated identity.
+func groupIdentityKey(entry *historyv1alpha.HistoryEntry) (string, error) {
+	idProviderReg, err := registry.GetIdentityProviderRegistration(entry.Spec.Identity)
+	if err != nil {
+		return "", fmt.Errorf("getting identity provider registration: %w", err)
+	}
+
+	cs, err := idProviderReg.ConfigurationItemsFunc(entry.Spec.Provider)
+	if err != nil {
+		return "", fmt.Errorf("getting identity provider config: %w", err)
+	}
+
+	if err := common.AddCommonIdentityConfig(cs); err != nil {
+		return "", fmt.Errorf("adding common identity config items: %w", err)
+	}
+
+	names := []string{}
+	for _, item := range cs.GetAll() {
+		names = append(names, item.Name)
+	}
+
+	sort.Strings(names)
+
+	key := &strings.Builder{}
+	fmt.Fprintf(key, "%s/%s", entry.Spec.Provider, entry.Spec.Identity)
+
+	for _, name := range names {
+		fmt.Fprintf(key, ";%s=%s", name, entry.Spec.Flags[name])
+	}
+
+	return key.String(), nil
+}
+
+func printGroupSummary(connections []*groupConnection) error {
+	table := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: metav1.SchemeGroupVersion.String(),
+			Kind:       "Table",
+		},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Entry", Type: "string"},
+			{Name: "Provider", Type: "string"},
+			{Name: "Context", Type: "string"},
+			{Name: "Status", Type: "string"},
+			{Name: "Error", Type: "string"},
+		},
+	}
+
+	for _, conn := range connections {
+		provider := ""
+		if conn.entry != nil {
+			provider = conn.entry.Spec.Provider
+		}
+
+		contextName := ""
+		if conn.output != nil && conn.output.ContextName != nil {
+			contextName = *conn.output.ContextName
+		}
+
+		status := "connected"
+		errMessage := ""
+
+		if conn.err != nil {
+			status = "failed"
+			errMessage = conn.err.Error()
+		}
+
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []any{conn.member, provider, contextName, status, errMessage},
+		})
+	}
+
+	objPrinter, err := printer.New(printer.OutputPrinterTable)
+	if err != nil {
+		return err
+	}
+
+	return objPrinter.Print(table, os.Stdout)
+}
+
+func groupsToTable(groups map[string][]string) *metav1.Table {
+	table := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: metav1.SchemeGroupVersion.String(),
+			Kind:       "Table",
+		},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Group", Type: "string"},
+			{Name: "Entries", Type: "string"},
+		},
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []any{name, strings.Join(groups[name], ",")},
+		})
+	}
+
+	return table
+}
+
+func getAppConfiguration(path string) (config.AppConfiguration, error) {
+	var appConfig config.AppConfiguration
+
+	var err error
+
+	if path == "" {
+		appConfig, err = config.NewAppConfiguration()
+	} else {
+		appConfig, err = config.NewAppConfigurationWithPath(path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("creating app config: %w", err)
+	}
+
+	return appConfig, nil
+}
@@ -0,0 +1,460 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	historyv1alpha "github.com/fidelity/kconnect/api/v1alpha1"
+	kaws "github.com/fidelity/kconnect/pkg/aws"
+	"github.com/fidelity/kconnect/pkg/aws/awsconfig"
+	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/history"
+	"github.com/fidelity/kconnect/pkg/history/loader"
+	"github.com/fidelity/kconnect/pkg/provider"
+	"github.com/fidelity/kconnect/pkg/provider/discovery"
+	"github.com/fidelity/kconnect/pkg/provider/identity"
+	"github.com/fidelity/kconnect/pkg/provider/registry"
+)
+
+const (
+	testIdentityProviderName  = "test-identity"
+	testDiscoveryProviderName = "test-discovery"
+)
+
+var (
+	errTestAuthenticate = errors.New("test authentication failed")
+	errTestGetConfig    = errors.New("test get config failed")
+
+	registerTestProviders sync.Once
+
+	testIdentityProvider  = &stubIdentityProvider{}
+	testDiscoveryProvider = &stubDiscoveryProvider{}
+)
+
+// stubIdentityProvider is an identity provider that authenticates with the tenant
+// config item. Authentication fails for the bad tenant.
+type stubIdentityProvider struct {
+	lock          sync.Mutex
+	authenticated []string
+}
+
+func (p *stubIdentityProvider) Name() string {
+	return testIdentityProviderName
+}
+
+func (p *stubIdentityProvider) Authenticate(ctx context.Context, input *identity.AuthenticateInput) (*identity.AuthenticateOutput, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	tenant := input.ConfigSet.ValueString("tenant")
+	p.authenticated = append(p.authenticated, tenant)
+
+	if tenant == "bad" {
+		return nil, errTestAuthenticate
+	}
+
+	return &identity.AuthenticateOutput{Identity: &stubIdentity{name: "bob@" + tenant}}, nil
+}
+
+type stubIdentity struct {
+	name string
+}
+
+func (i *stubIdentity) Type() string                 { return "test" }
+func (i *stubIdentity) Name() string                 { return i.name }
+func (i *stubIdentity) IsExpired() bool              { return false }
+func (i *stubIdentity) IdentityProviderName() string { return testIdentityProviderName }
+
+// stubDiscoveryProvider is a discovery provider for a fixed set of clusters. Getting
+// the config for the broken cluster fails.
+type stubDiscoveryProvider struct {
+	clusters map[string]*discovery.Cluster
+
+	lock     sync.Mutex
+	resolved int
+}
+
+func (p *stubDiscoveryProvider) Name() string {
+	return testDiscoveryProviderName
+}
+
+func (p *stubDiscoveryProvider) ListPreReqs() []*provider.PreReq {
+	return nil
+}
+
+func (p *stubDiscoveryProvider) CheckPreReqs() error {
+	return nil
+}
+
+func (p *stubDiscoveryProvider) Validate(cs config.ConfigurationSet) error {
+	return nil
+}
+
+func (p *stubDiscoveryProvider) Resolve(cs config.ConfigurationSet, id identity.Identity) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.resolved++
+
+	return nil
+}
+
+func (p *stubDiscoveryProvider) Discover(ctx context.Context, input *discovery.DiscoverInput) (*discovery.DiscoverOutput, error) {
+	return &discovery.DiscoverOutput{Clusters: p.clusters}, nil
+}
+
+func (p *stubDiscoveryProvider) GetCluster(ctx context.Context, input *discovery.GetClusterInput) (*discovery.GetClusterOutput, error) {
+	return &discovery.GetClusterOutput{Cluster: p.clusters[input.ClusterID]}, nil
+}
+
+func (p *stubDiscoveryProvider) GetConfig(ctx context.Context, input *discovery.GetConfigInput) (*discovery.GetConfigOutput, error) {
+	if input.Cluster.Name == "broken" {
+		return nil, errTestGetConfig
+	}
+
+	contextName := input.Cluster.Name
+	cfg := api.NewConfig()
+	cfg.Clusters["cluster-"+contextName] = &api.Cluster{Server: "https://" + contextName + ".example.com"}
+	cfg.AuthInfos["user-"+contextName] = &api.AuthInfo{Token: "token"}
+	cfg.Contexts[contextName] = &api.Context{Cluster: "cluster-" + contextName, AuthInfo: "user-" + contextName}
+	cfg.CurrentContext = contextName
+
+	return &discovery.GetConfigOutput{KubeConfig: cfg, ContextName: &contextName}, nil
+}
+
+// newTestApp registers the stub providers, resets their state and creates an
+// app with a history store in a temporary directory
+func newTestApp(t *testing.T, clusterNames ...string) *App {
+	t.Helper()
+
+	registerTestProviders.Do(func() {
+		registry.RegisterIdentityPlugin(&registry.IdentityPluginRegistration{ //nolint: errcheck
+			PluginRegistration: registry.PluginRegistration{
+				Name:                   testIdentityProviderName,
+				ConfigurationItemsFunc: testConfigItems,
+			},
+			CreateFunc: func(input *provider.PluginCreationInput) (identity.Provider, error) {
+				return testIdentityProvider, nil
+			},
+		})
+		registry.RegisterDiscoveryPlugin(&registry.DiscoveryPluginRegistration{ //nolint: errcheck
+			PluginRegistration: registry.PluginRegistration{
+				Name:                   testDiscoveryProviderName,
+				ConfigurationItemsFunc: testConfigItems,
+			},
+			SupportedIdentityProviders: []string{testIdentityProviderName},
+			CreateFunc: func(input *provider.PluginCreationInput) (discovery.Provider, error) {
+				return testDiscoveryProvider, nil
+			},
+		})
+	})
+
+	testIdentityProvider.authenticated = nil
+	testDiscoveryProvider.resolved = 0
+	testDiscoveryProvider.clusters = map[string]*discovery.Cluster{}
+
+	for _, name := range clusterNames {
+		testDiscoveryProvider.clusters[name] = &discovery.Cluster{ID: name, Name: name}
+	}
+
+	historyLoader, err := loader.NewFileLoader(filepath.Join(t.TempDir(), "history.yaml"))
+	if err != nil {
+		t.Fatalf("creating history loader: %s", err)
+	}
+
+	store, err := history.NewStore(100, historyLoader)
+	if err != nil {
+		t.Fatalf("creating history store: %s", err)
+	}
+
+	return &App{
+		historyStore: store,
+		logger:       zap.NewNop().Sugar(),
+	}
+}
+
+func testConfigItems(scopeTo string) (config.ConfigurationSet, error) {
+	cs := config.NewConfigurationSet()
+	if _, err := cs.String("tenant", "", "The tenant to authenticate with"); err != nil {
+		return nil, err
+	}
+
+	return cs, nil
+}
+
+// testGroupConnection creates the connection for a group entry using the stub providers
+func testGroupConnection(t *testing.T, tenant, clusterID, kubeconfigPath string) *groupConnection {
+	t.Helper()
+
+	cs, err := testConfigItems("")
+	if err != nil {
+		t.Fatalf("creating config: %s", err)
+	}
+
+	if err := cs.SetValue("tenant", tenant); err != nil {
+		t.Fatalf("setting tenant: %s", err)
+	}
+
+	entry := historyv1alpha.NewHistoryEntry()
+	entry.Spec.Identity = testIdentityProviderName
+	entry.Spec.Provider = testDiscoveryProviderName
+	entry.Spec.ProviderID = clusterID
+	entry.Spec.Flags = map[string]string{"tenant": tenant}
+
+	input := &UseInput{
+		IdentityProvider:  testIdentityProviderName,
+		DiscoveryProvider: testDiscoveryProviderName,
+		ConfigSet:         cs,
+	}
+	input.ClusterID = &clusterID
+	input.Kubeconfig = kubeconfigPath
+
+	return &groupConnection{member: clusterID, entry: entry, input: input}
+}
+
+func Test_AuthenticateGroup(t *testing.T) {
+	a := newTestApp(t)
+
+	connections := []*groupConnection{
+		testGroupConnection(t, "a", "dev", ""),
+		testGroupConnection(t, "a", "prod", ""),
+		testGroupConnection(t, "b", "test", ""),
+		testGroupConnection(t, "bad", "other", ""),
+		testGroupConnection(t, "bad", "another", ""),
+		{member: "missing", err: history.ErrEntryNotFound},
+	}
+
+	a.authenticateGroup(context.Background(), connections)
+
+	// each distinct identity is only authenticated once
+	if len(testIdentityProvider.authenticated) != 3 {
+		t.Fatalf("expected 3 authentications but got %v", testIdentityProvider.authenticated)
+	}
+
+	for _, conn := range connections[:3] {
+		if conn.err != nil {
+			t.Fatalf("unexpected error for %s: %s", conn.member, conn.err)
+		}
+	}
+
+	if connections[0].id != connections[1].id {
+		t.Fatal("expected entries with the same identity config to share the identity")
+	}
+
+	if connections[2].id.Name() != "bob@b" {
+		t.Fatalf("expected identity bob@b but got %s", connections[2].id.Name())
+	}
+
+	for _, conn := range connections[3:5] {
+		if !errors.Is(conn.err, errTestAuthenticate) {
+			t.Fatalf("expected authentication error for %s but got %v", conn.member, conn.err)
+		}
+	}
+
+	if !errors.Is(connections[5].err, history.ErrEntryNotFound) {
+		t.Fatalf("expected existing error to be kept but got %v", connections[5].err)
+	}
+
+	if testDiscoveryProvider.resolved != 3 {
+		t.Fatalf("expected config to be resolved for 3 entries but got %d", testDiscoveryProvider.resolved)
+	}
+}
+
+func Test_GetGroupClusterConfigs(t *testing.T) {
+	a := newTestApp(t, "dev", "prod", "broken")
+
+	testCases := []struct {
+		clusterID     string
+		err           error
+		expectContext string
+		expectErr     error
+	}{
+		{clusterID: "dev", expectContext: "dev"},
+		{clusterID: "prod", expectContext: "prod"},
+		{clusterID: "missing", expectErr: ErrClusterNotFound},
+		{clusterID: "broken", expectErr: errTestGetConfig},
+		{clusterID: "dev", err: errTestAuthenticate, expectErr: errTestAuthenticate},
+	}
+
+	connections := []*groupConnection{}
+
+	for _, tc := range testCases {
+		conn := testGroupConnection(t, "a", tc.clusterID, "")
+		conn.clusterProvider = testDiscoveryProvider
+		conn.id = &stubIdentity{name: "bob@a"}
+		conn.err = tc.err
+		connections = append(connections, conn)
+	}
+
+	a.getGroupClusterConfigs(context.Background(), connections)
+
+	for i, tc := range testCases {
+		conn := connections[i]
+
+		if tc.expectErr != nil {
+			if !errors.Is(conn.err, tc.expectErr) {
+				t.Fatalf("expected error %v for %s but got %v", tc.expectErr, tc.clusterID, conn.err)
+			}
+
+			if conn.output != nil {
+				t.Fatalf("expected no kubeconfig for %s", tc.clusterID)
+			}
+
+			continue
+		}
+
+		if conn.err != nil {
+			t.Fatalf("unexpected error for %s: %s", tc.clusterID, conn.err)
+		}
+
+		if *conn.output.ContextName != tc.expectContext {
+			t.Fatalf("expected context %s but got %s", tc.expectContext, *conn.output.ContextName)
+		}
+	}
+}
+
+func Test_WriteGroupConfigs(t *testing.T) {
+	a := newTestApp(t, "dev", "prod", "test")
+	dir := t.TempDir()
+	sharedPath := filepath.Join(dir, "shared")
+	otherPath := filepath.Join(dir, "other")
+
+	connections := []*groupConnection{
+		testGroupConnection(t, "a", "dev", sharedPath),
+		testGroupConnection(t, "a", "prod", sharedPath),
+		testGroupConnection(t, "a", "test", otherPath),
+		testGroupConnection(t, "a", "failed", sharedPath),
+	}
+	connections[2].input.ContextNameTemplate = "group-{{ .ClusterName }}"
+	connections[3].err = errTestGetConfig
+
+	for _, conn := range connections[:3] {
+		conn.id = &stubIdentity{name: "bob@a"}
+		conn.cluster = testDiscoveryProvider.clusters[*conn.input.ClusterID]
+
+		output, err := testDiscoveryProvider.GetConfig(context.Background(), &discovery.GetConfigInput{Cluster: conn.cluster})
+		if err != nil {
+			t.Fatalf("getting config: %s", err)
+		}
+
+		conn.output = output
+	}
+
+	a.writeGroupConfigs(&ConnectToInput{}, connections)
+
+	for _, conn := range connections[:3] {
+		if conn.err != nil {
+			t.Fatalf("unexpected error for %s: %s", conn.member, conn.err)
+		}
+	}
+
+	expectContexts := map[string][]string{
+		sharedPath: {"dev", "prod"},
+		otherPath:  {"group-test"},
+	}
+
+	for path, contexts := range expectContexts {
+		cfg, err := clientcmd.LoadFromFile(path)
+		if err != nil {
+			t.Fatalf("loading kubeconfig %s: %s", path, err)
+		}
+
+		if len(cfg.Contexts) != len(contexts) {
+			t.Fatalf("expected contexts %v in %s but got %d contexts", contexts, path, len(cfg.Contexts))
+		}
+
+		for _, contextName := range contexts {
+			kubeContext, ok := cfg.Contexts[contextName]
+			if !ok {
+				t.Fatalf("expected context %s in %s", contextName, path)
+			}
+
+			if _, ok := kubeContext.Extensions["kconnect"]; !ok {
+				t.Fatalf("expected history reference in context %s", contextName)
+			}
+		}
+	}
+
+	entries, err := a.historyStore.GetAll()
+	if err != nil {
+		t.Fatalf("getting history: %s", err)
+	}
+
+	if len(entries.Items) != 3 {
+		t.Fatalf("expected 3 history entries but got %d", len(entries.Items))
+	}
+}
+
+func Test_PruneAWSProfilesAfterGroupUse(t *testing.T) {
+	a := newTestApp(t)
+	path := writeTestCredentials(t)
+
+	inputs := []*UseInput{
+		testEKSInput(t, path, "kconnect-inuse", true),
+		testEKSInput(t, path, "kconnect-unreferenced", true),
+		testEKSInput(t, path, "kconnect-active", false),
+	}
+
+	a.pruneAWSProfilesAfterUse(inputs...)
+
+	profiles, err := awsconfig.ListProfiles(path)
+	if err != nil {
+		t.Fatalf("listing profiles: %s", err)
+	}
+
+	remains := []string{}
+	for _, profile := range profiles {
+		remains = append(remains, profile.Name)
+	}
+
+	// the profiles used by the group are kept even though they aren't in the history
+	expect := "kconnect-inuse,kconnect-unreferenced"
+	if actual := strings.Join(remains, ","); actual != expect {
+		t.Fatalf("expected profiles %s but got %s", expect, actual)
+	}
+}
+
+func testEKSInput(t *testing.T, credentialsFile, profile string, prune bool) *UseInput {
+	t.Helper()
+
+	cs := config.NewConfigurationSet()
+	cs.Bool(kaws.PruneProfilesConfigItem, false, "") //nolint: errcheck
+	cs.String("aws-shared-credentials-file", "", "") //nolint: errcheck
+	cs.String("aws-profile", "", "")                 //nolint: errcheck
+
+	for name, value := range map[string]any{
+		kaws.PruneProfilesConfigItem:  prune,
+		"aws-shared-credentials-file": credentialsFile,
+		"aws-profile":                 profile,
+	} {
+		if err := cs.SetValue(name, value); err != nil {
+			t.Fatalf("setting %s: %s", name, err)
+		}
+	}
+
+	return &UseInput{DiscoveryProvider: EKSProviderName, ConfigSet: cs}
+}
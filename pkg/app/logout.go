@@ -19,26 +19,36 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 
 	historyv1alpha "github.com/fidelity/kconnect/api/v1alpha1"
 	"github.com/fidelity/kconnect/pkg/k8s/kubeconfig"
 	"go.uber.org/zap"
-	"gopkg.in/ini.v1"
+	"k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/fidelity/kconnect/pkg/aws/awsconfig"
+	azid "github.com/fidelity/kconnect/pkg/azure/identity"
+	"github.com/fidelity/kconnect/pkg/defaults"
+	"github.com/fidelity/kconnect/pkg/rancher"
+	ksaml "github.com/fidelity/kconnect/pkg/saml"
 )
 
 const (
 	EKSProviderName     = "eks"
 	AKSProviderName     = "aks"
 	RancherProviderName = "rancher"
+
+	// rancherAuthIdentityName is the identity provider that chooses the Rancher
+	// identity provider to use from the auth providers enabled in Rancher
+	rancherAuthIdentityName = "rancher-auth"
 )
 
 type LogoutInput struct {
 	CommonConfig
 	HistoryConfig
 	KubernetesConfig
+	HTTPConfig
 
 	All   bool
 	Alias string
@@ -150,57 +160,122 @@ func (a *App) doLogout(params *LogoutInput, entry *historyv1alpha.HistoryEntry)
 func (a *App) doLogoutEKS(entry *historyv1alpha.HistoryEntry) error {
 	zap.S().Infof("logging out of entry (eks): name: %s, alias: %s", entry.Name, *entry.Spec.Alias)
 
+	if idpEndpoint := entry.Spec.Flags["idp-endpoint"]; idpEndpoint != "" {
+		if err := ksaml.NewAssertionCache().Delete(idpEndpoint, entry.Spec.Flags["username"]); err != nil {
+			return fmt.Errorf("removing cached saml assertion: %w", err)
+		}
+	}
+
 	profileName, ok := entry.Spec.Flags["aws-profile"]
 	if !ok {
 		zap.S().Infof("no aws profile name found for entry %s", entry.Name)
 		return nil
 	}
 
-	path, err := awsconfig.LocateConfigFile()
+	// the profile is in the credentials file that it was saved to when connecting
+	path, err := awsCredentialsPath(entry.Spec.Flags["aws-shared-credentials-file"])
 	if err != nil {
 		return err
 	}
 
-	cfg, err := ini.Load(path)
-	if err != nil {
-		return err
-	}
-
-	cfg.DeleteSection(profileName)
-
-	return cfg.SaveTo(path)
+	return awsconfig.DeleteProfiles(path, []string{profileName})
 }
 
 func (a *App) doLogoutAKS(params *LogoutInput, entry *historyv1alpha.HistoryEntry) error {
 	zap.S().Infof("logging out of entry (aks): name: %s, alias: %s", entry.Name, *entry.Spec.Alias)
+
+	if tenantID := entry.Spec.Flags["tenant-id"]; tenantID != "" {
+		if err := azid.NewTokenCache().Delete(tenantID, entry.Spec.Flags["client-id"]); err != nil {
+			return fmt.Errorf("removing cached azure ad tokens: %w", err)
+		}
+	}
+
 	return a.deleteUserFromKubeconfigByEntryID(params.Kubeconfig, entry.Name)
 }
 
 func (a *App) doLogoutRancher(params *LogoutInput, entry *historyv1alpha.HistoryEntry) error {
 	zap.S().Infof("logging out of entry (rancher): name: %s, alias: %s", entry.Name, *entry.Spec.Alias)
+
+	if apiEndpoint := entry.Spec.Flags[rancher.APIEndpointConfigName]; apiEndpoint != "" {
+		if err := a.revokeRancherTokens(params, entry, apiEndpoint); err != nil {
+			return err
+		}
+	}
+
 	return a.deleteUserFromKubeconfigByEntryID(params.Kubeconfig, entry.Name)
 }
 
-func (a *App) deleteUserFromKubeconfigByEntryID(kubeconfigPath, entryID string) error {
-	config, err := kubeconfig.Read(kubeconfigPath)
+// revokeRancherTokens will revoke the token for the kubeconfig user of the entry and the token
+// that was stored when logging in. Failing to revoke a token isn't an error so that the user
+// can still logout when Rancher can't be reached.
+func (a *App) revokeRancherTokens(params *LogoutInput, entry *historyv1alpha.HistoryEntry, apiEndpoint string) error {
+	resolver, err := rancher.NewStaticEndpointsResolver(apiEndpoint)
+	if err != nil {
+		return fmt.Errorf("creating endpoint resolver: %w", err)
+	}
+
+	httpClient, err := a.httpClientFor(&params.HTTPConfig)
 	if err != nil {
 		return err
 	}
 
-	kubeconfigUser := ""
+	config, err := kubeconfig.Read(params.Kubeconfig)
+	if err != nil {
+		return err
+	}
 
-	for context := range config.Contexts {
-		historyRef, err := historyv1alpha.GetHistoryReferenceFromContext(config.Contexts[context])
-		if err != nil && errors.Is(err, historyv1alpha.ErrNoHistoryExtension) {
-			return err
+	kubeconfigUser, err := getKubeconfigUserByEntryID(config, entry.Name)
+	if err != nil {
+		return err
+	}
+
+	if authInfo, ok := config.AuthInfos[kubeconfigUser]; ok && authInfo.Token != "" {
+		if err := rancher.DeleteToken(httpClient, resolver, authInfo.Token, rancher.TokenName(authInfo.Token)); err != nil {
+			zap.S().Warnw("failed revoking Rancher kubeconfig token", "error", err.Error())
 		}
+	}
 
-		if historyRef.EntryID == entryID {
-			kubeconfigUser = config.Contexts[context].AuthInfo
-			break
+	storedTokens, err := rancher.ListStoredTokens(apiEndpoint)
+	if err != nil {
+		return fmt.Errorf("listing stored Rancher tokens: %w", err)
+	}
+
+	username := entry.Spec.Flags[defaults.UsernameConfigItem]
+
+	for _, storedToken := range storedTokens {
+		// The identity provider used by rancher-auth isn't saved in the history entry
+		if storedToken.Username != username || (storedToken.IdentityProvider != entry.Spec.Identity && entry.Spec.Identity != rancherAuthIdentityName) {
+			continue
+		}
+
+		if err := rancher.DeleteToken(httpClient, resolver, storedToken.Token, rancher.TokenName(storedToken.Token)); err != nil {
+			zap.S().Warnw("failed revoking stored Rancher token", "error", err.Error())
+		}
+
+		store, err := rancher.NewIdentityStore(apiEndpoint, username, storedToken.IdentityProvider)
+		if err != nil {
+			return fmt.Errorf("creating Rancher identity store: %w", err)
+		}
+
+		if err := store.Delete(); err != nil {
+			return fmt.Errorf("deleting stored Rancher token: %w", err)
 		}
 	}
 
+	return nil
+}
+
+func (a *App) deleteUserFromKubeconfigByEntryID(kubeconfigPath, entryID string) error {
+	config, err := kubeconfig.Read(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	kubeconfigUser, err := getKubeconfigUserByEntryID(config, entryID)
+	if err != nil {
+		return err
+	}
+
 	if kubeconfigUser == "" {
 		zap.S().Infof("no user found in kubeconfig for entry: %S", entryID)
 		return nil
@@ -210,3 +285,18 @@ func (a *App) deleteUserFromKubeconfigByEntryID(kubeconfigPath, entryID string)
 
 	return kubeconfig.Write(kubeconfigPath, config, false, false)
 }
+
+func getKubeconfigUserByEntryID(config *api.Config, entryID string) (string, error) {
+	for context := range config.Contexts {
+		historyRef, err := historyv1alpha.GetHistoryReferenceFromContext(config.Contexts[context])
+		if err != nil && errors.Is(err, historyv1alpha.ErrNoHistoryExtension) {
+			return "", err
+		}
+
+		if historyRef.EntryID == entryID {
+			return config.Contexts[context].AuthInfo, nil
+		}
+	}
+
+	return "", nil
+}
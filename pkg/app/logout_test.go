@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"strings"
+	"testing"
+
+	historyv1alpha "github.com/fidelity/kconnect/api/v1alpha1"
+	"github.com/fidelity/kconnect/pkg/aws/awsconfig"
+)
+
+func Test_LogoutEKSCredentialsFile(t *testing.T) {
+	a := newTestApp(t)
+	path := writeTestCredentials(t)
+
+	// the default credentials file isn't used when the entry has its own credentials file
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "")
+	t.Setenv("HOME", t.TempDir())
+
+	alias := "dev"
+	entry := historyv1alpha.NewHistoryEntry()
+	entry.Spec.Alias = &alias
+	entry.Spec.Provider = EKSProviderName
+	entry.Spec.Flags = map[string]string{
+		"aws-profile":                 "kconnect-active",
+		"aws-shared-credentials-file": path,
+	}
+
+	if err := a.doLogoutEKS(entry); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	profiles, err := awsconfig.ListProfiles(path)
+	if err != nil {
+		t.Fatalf("listing profiles: %s", err)
+	}
+
+	remains := []string{}
+	for _, profile := range profiles {
+		remains = append(remains, profile.Name)
+	}
+
+	expect := "kconnect-expired,kconnect-inuse,kconnect-unreferenced"
+	if actual := strings.Join(remains, ","); actual != expect {
+		t.Fatalf("expected profiles %s but got %s", expect, actual)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fidelity/kconnect/pkg/printer"
+	"github.com/fidelity/kconnect/pkg/rancher"
+)
+
+// Status of the tokens listed by RancherTokens
+const (
+	rancherTokenActive  = "active"
+	rancherTokenExpired = "expired"
+	rancherTokenStored  = "stored"
+	rancherTokenDeleted = "deleted"
+)
+
+// RancherTokensInput defines the inputs for RancherTokens
+type RancherTokensInput struct {
+	CommonConfig
+	HTTPConfig
+
+	APIEndpoint string                 `json:"api-endpoint"`
+	Token       string                 `json:"token"`
+	Clean       bool                   `json:"clean"`
+	Output      *printer.OutputPrinter `json:"output,omitempty"`
+}
+
+type rancherTokenOutput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Created     string `json:"created"`
+	ExpiresAt   string `json:"expiresAt,omitempty"`
+	Status      string `json:"status"`
+}
+
+// RancherTokens will list the Rancher tokens that were created by kconnect. When cleaning, the
+// expired tokens and the tokens created on this machine that are no longer stored are deleted.
+func (a *App) RancherTokens(ctx context.Context, input *RancherTokensInput) error {
+	a.logger.Infow("listing Rancher tokens", "api-endpoint", input.APIEndpoint, "clean", input.Clean)
+
+	resolver, err := rancher.NewStaticEndpointsResolver(input.APIEndpoint)
+	if err != nil {
+		return fmt.Errorf("creating endpoint resolver: %w", err)
+	}
+
+	stored, err := rancher.ListStoredTokens(input.APIEndpoint)
+	if err != nil {
+		return fmt.Errorf("listing stored Rancher tokens: %w", err)
+	}
+
+	storedNames := map[string]bool{}
+	authToken := input.Token
+
+	for _, storedToken := range stored {
+		storedNames[rancher.TokenName(storedToken.Token)] = true
+
+		if authToken == "" && !storedToken.IsExpired() {
+			authToken = storedToken.Token
+		}
+	}
+
+	if authToken == "" {
+		return ErrNoRancherToken
+	}
+
+	httpClient, err := a.httpClientFor(&input.HTTPConfig)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := rancher.ListTokens(httpClient, resolver, authToken)
+	if err != nil {
+		return err
+	}
+
+	hostDescription := rancher.TokenDescription()
+	outputs := []*rancherTokenOutput{}
+	failed := 0
+
+	for i := range tokens {
+		token := &tokens[i]
+		if !token.CreatedByKconnect() {
+			continue
+		}
+
+		output := &rancherTokenOutput{
+			Name:        token.Name,
+			Description: token.Description,
+			Created:     token.Created,
+			ExpiresAt:   token.ExpiresAt,
+			Status:      rancherTokenActive,
+		}
+
+		switch {
+		case token.Expired:
+			output.Status = rancherTokenExpired
+		case storedNames[token.Name] || token.Current:
+			output.Status = rancherTokenStored
+		}
+
+		removable := output.Status == rancherTokenExpired || (output.Status == rancherTokenActive && token.Description == hostDescription)
+		if input.Clean && removable {
+			if err := rancher.DeleteToken(httpClient, resolver, authToken, token.Name); err != nil {
+				a.logger.Errorw("failed deleting Rancher token", "name", token.Name, "error", err.Error())
+				failed++
+			} else {
+				output.Status = rancherTokenDeleted
+			}
+		}
+
+		outputs = append(outputs, output)
+	}
+
+	if err := printRancherTokens(outputs, input.Output); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("deleting %d tokens: %w", failed, ErrRancherTokensCleanFailed)
+	}
+
+	return nil
+}
+
+func printRancherTokens(outputs []*rancherTokenOutput, output *printer.OutputPrinter) error {
+	outputFormat := printer.OutputPrinterTable
+	if output != nil {
+		outputFormat = *output
+	}
+
+	objPrinter, err := printer.New(outputFormat)
+	if err != nil {
+		return fmt.Errorf("getting printer for output %s: %w", outputFormat, err)
+	}
+
+	if outputFormat != printer.OutputPrinterTable {
+		return objPrinter.Print(outputs, os.Stdout)
+	}
+
+	table := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: metav1.SchemeGroupVersion.String(),
+			Kind:       "Table",
+		},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string"},
+			{Name: "Description", Type: "string"},
+			{Name: "Created", Type: "string"},
+			{Name: "Expires", Type: "string"},
+			{Name: "Status", Type: "string"},
+		},
+	}
+
+	for _, token := range outputs {
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []any{token.Name, token.Description, token.Created, token.ExpiresAt, token.Status},
+		})
+	}
+
+	return objPrinter.Print(table, os.Stdout)
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+
+	azid "github.com/fidelity/kconnect/pkg/azure/id"
+	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/provider/discovery"
+	"github.com/fidelity/kconnect/pkg/provider/identity"
+)
+
+// aliasTemplateData is the data available when generating an alias from a template
+type aliasTemplateData struct {
+	Provider    string
+	ClusterName string
+	ClusterID   string
+}
+
+// executeTemplate will parse and execute a named template with the supplied data
+func executeTemplate(name, text string, data any) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing %s template: %w", name, err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return "", fmt.Errorf("executing %s template: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// executeNameTemplate will execute a naming template and check that the name
+// it generates isn't empty
+func executeNameTemplate(name, text string, data any) (string, error) {
+	generated, err := executeTemplate(name, text, data)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(generated) == "" {
+		return "", fmt.Errorf("executing %s template %q: %w", name, text, ErrEmptyTemplateName)
+	}
+
+	return generated, nil
+}
+
+// namingTemplateData is the data available to the context, cluster and user
+// naming templates
+type namingTemplateData struct {
+	Provider     string
+	Identity     string
+	ClusterName  string
+	ClusterID    string
+	Region       string
+	Account      string
+	Subscription string
+	Alias        string
+	Username     string
+	Labels       map[string]string
+}
+
+func newNamingTemplateData(input *UseInput, cluster *discovery.Cluster, userID identity.Identity) *namingTemplateData {
+	data := &namingTemplateData{
+		Provider:     input.DiscoveryProvider,
+		Identity:     input.IdentityProvider,
+		ClusterName:  cluster.Name,
+		ClusterID:    cluster.ID,
+		Region:       configValue(input.ConfigSet, "region"),
+		Subscription: configValue(input.ConfigSet, "subscription-id"),
+		Username:     input.Username,
+		Labels:       cluster.Labels,
+	}
+
+	if input.Alias != nil {
+		data.Alias = *input.Alias
+	}
+
+	if data.Username == "" && userID != nil {
+		data.Username = userID.Name()
+	}
+
+	// EKS cluster ids are ARNs and AKS cluster ids contain the subscription
+	if clusterARN, err := arn.Parse(cluster.ID); err == nil {
+		data.Account = clusterARN.AccountID
+		data.Region = clusterARN.Region
+	} else if resourceID, err := azid.FromClusterID(cluster.ID); err == nil && data.Subscription == "" {
+		data.Subscription = resourceID.SubscriptionID
+	}
+
+	if data.Labels == nil {
+		data.Labels = map[string]string{}
+	}
+
+	return data
+}
+
+// applyNamingTemplates will rename the cluster, user and context in the generated kubeconfig
+// using the naming templates. The names are changed consistently so that the context
+// refers to the renamed cluster and user.
+func applyNamingTemplates(input *UseInput, cluster *discovery.Cluster, userID identity.Identity, output *discovery.GetConfigOutput) error {
+	if input.ContextNameTemplate == "" && input.ClusterNameTemplate == "" && input.UserNameTemplate == "" {
+		return nil
+	}
+
+	data := newNamingTemplateData(input, cluster, userID)
+	kubeConfig := output.KubeConfig
+	contextName := *output.ContextName
+
+	kubeContext, ok := kubeConfig.Contexts[contextName]
+	if !ok {
+		return fmt.Errorf("getting context %s: %w", contextName, ErrContextNotFound)
+	}
+
+	if input.ClusterNameTemplate != "" {
+		newName, err := executeNameTemplate("cluster-name", input.ClusterNameTemplate, data)
+		if err != nil {
+			return err
+		}
+
+		if kubeCluster, ok := kubeConfig.Clusters[kubeContext.Cluster]; ok {
+			delete(kubeConfig.Clusters, kubeContext.Cluster)
+			kubeConfig.Clusters[newName] = kubeCluster
+		}
+
+		kubeContext.Cluster = newName
+	}
+
+	if input.UserNameTemplate != "" {
+		newName, err := executeNameTemplate("user-name", input.UserNameTemplate, data)
+		if err != nil {
+			return err
+		}
+
+		if authInfo, ok := kubeConfig.AuthInfos[kubeContext.AuthInfo]; ok {
+			delete(kubeConfig.AuthInfos, kubeContext.AuthInfo)
+			kubeConfig.AuthInfos[newName] = authInfo
+		}
+
+		kubeContext.AuthInfo = newName
+	}
+
+	if input.ContextNameTemplate != "" {
+		newName, err := executeNameTemplate("context-name", input.ContextNameTemplate, data)
+		if err != nil {
+			return err
+		}
+
+		delete(kubeConfig.Contexts, contextName)
+		kubeConfig.Contexts[newName] = kubeContext
+
+		if kubeConfig.CurrentContext == contextName {
+			kubeConfig.CurrentContext = newName
+		}
+
+		output.ContextName = &newName
+	}
+
+	return nil
+}
+
+func configValue(cs config.ConfigurationSet, name string) string {
+	if cs == nil || !cs.ExistsWithValue(name) {
+		return ""
+	}
+
+	return cs.ValueString(name)
+}
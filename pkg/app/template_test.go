@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/provider/discovery"
+)
+
+func Test_ApplyNamingTemplates(t *testing.T) {
+	testCases := []struct {
+		name            string
+		contextTemplate string
+		clusterTemplate string
+		userTemplate    string
+		expectContext   string
+		expectCluster   string
+		expectUser      string
+		expectErr       bool
+	}{
+		{
+			name:          "no templates",
+			expectContext: "kconnect-123@eks-dev",
+			expectCluster: "eks-dev",
+			expectUser:    "kconnect-123",
+		},
+		{
+			name:            "all templates",
+			contextTemplate: "{{.Alias}}",
+			clusterTemplate: "{{.Provider}}-{{.Account}}-{{.Region}}-{{.ClusterName}}",
+			userTemplate:    "{{.Username}}",
+			expectContext:   "dev-alias",
+			expectCluster:   "eks-123456789012-eu-west-2-dev",
+			expectUser:      "bob",
+		},
+		{
+			name:            "context template only",
+			contextTemplate: "{{.ClusterName}}",
+			expectContext:   "dev",
+			expectCluster:   "eks-dev",
+			expectUser:      "kconnect-123",
+		},
+		{
+			name:            "invalid template",
+			contextTemplate: "{{.Unknown}}",
+			expectErr:       true,
+		},
+		{
+			name:            "empty cluster name",
+			clusterTemplate: "{{.Subscription}}",
+			expectErr:       true,
+		},
+		{
+			name:         "empty user name",
+			userTemplate: "{{index .Labels \"missing\"}} ",
+			expectErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			alias := "dev-alias"
+			contextName := "kconnect-123@eks-dev"
+			output := &discovery.GetConfigOutput{
+				KubeConfig: &api.Config{
+					Clusters:       map[string]*api.Cluster{"eks-dev": {Server: "https://dev"}},
+					AuthInfos:      map[string]*api.AuthInfo{"kconnect-123": {}},
+					Contexts:       map[string]*api.Context{contextName: {Cluster: "eks-dev", AuthInfo: "kconnect-123"}},
+					CurrentContext: contextName,
+				},
+				ContextName: &contextName,
+			}
+
+			input := &UseInput{
+				DiscoveryProvider: "eks",
+				ConfigSet:         config.NewConfigurationSet(),
+			}
+			input.Alias = &alias
+			input.Username = "bob"
+			input.ContextNameTemplate = tc.contextTemplate
+			input.ClusterNameTemplate = tc.clusterTemplate
+			input.UserNameTemplate = tc.userTemplate
+
+			cluster := &discovery.Cluster{
+				ID:   "arn:aws:eks:eu-west-2:123456789012:cluster/dev",
+				Name: "dev",
+			}
+
+			err := applyNamingTemplates(input, cluster, nil, output)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if *output.ContextName != tc.expectContext {
+				t.Fatalf("expected context %s but got %s", tc.expectContext, *output.ContextName)
+			}
+
+			if output.KubeConfig.CurrentContext != tc.expectContext {
+				t.Fatalf("expected current context %s but got %s", tc.expectContext, output.KubeConfig.CurrentContext)
+			}
+
+			kubeContext, ok := output.KubeConfig.Contexts[tc.expectContext]
+			if !ok {
+				t.Fatalf("expected context %s to exist", tc.expectContext)
+			}
+
+			if kubeContext.Cluster != tc.expectCluster {
+				t.Fatalf("expected cluster %s but got %s", tc.expectCluster, kubeContext.Cluster)
+			}
+
+			if _, ok := output.KubeConfig.Clusters[tc.expectCluster]; !ok {
+				t.Fatalf("expected cluster %s to exist", tc.expectCluster)
+			}
+
+			if kubeContext.AuthInfo != tc.expectUser {
+				t.Fatalf("expected user %s but got %s", tc.expectUser, kubeContext.AuthInfo)
+			}
+
+			if _, ok := output.KubeConfig.AuthInfos[tc.expectUser]; !ok {
+				t.Fatalf("expected user %s to exist", tc.expectUser)
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -43,11 +44,16 @@ type ConnectToInput struct {
 	AliasOrIDORPosition string
 	Password            string `json:"password"`
 	SetCurrent          bool   `json:"set-current,omitempty"`
+	FreshLogin          bool   `json:"fresh-login,omitempty"`
 }
 
 func (a *App) ConnectTo(ctx context.Context, params *ConnectToInput) error {
 	a.logger.Debug("running connectto")
 
+	if strings.HasPrefix(params.AliasOrIDORPosition, GroupPrefix) {
+		return a.connectToGroup(ctx, params, strings.TrimPrefix(params.AliasOrIDORPosition, GroupPrefix))
+	}
+
 	entry, err := a.getHistoryEntry(params)
 	if err != nil {
 		return fmt.Errorf("getting history entry: %w", err)
@@ -57,16 +63,32 @@ func (a *App) ConnectTo(ctx context.Context, params *ConnectToInput) error {
 		return history.ErrEntryNotFound
 	}
 
+	useParams, err := a.buildUseInputFromEntry(params, entry)
+	if err != nil {
+		return err
+	}
+
+	return a.Use(ctx, useParams)
+}
+
+// buildUseInputFromEntry will create the input for use from a history entry
+func (a *App) buildUseInputFromEntry(params *ConnectToInput, entry *historyv1alpha.HistoryEntry) (*UseInput, error) {
 	historyID := entry.ObjectMeta.Name
 
 	cs, err := a.buildConnectToConfig(params.ConfigFile, entry.Spec.Provider, entry.Spec.Identity, entry)
 	if err != nil {
-		return fmt.Errorf("building connectTo config set: %w", err)
+		return nil, fmt.Errorf("building connectTo config set: %w", err)
 	}
 
 	if params.Password != "" {
 		if err := cs.SetValue("password", params.Password); err != nil {
-			return fmt.Errorf("setting password config item: %w", err)
+			return nil, fmt.Errorf("setting password config item: %w", err)
+		}
+	}
+
+	if params.FreshLogin && cs.Exists("fresh-login") {
+		if err := cs.SetValue("fresh-login", true); err != nil {
+			return nil, fmt.Errorf("setting fresh-login config item: %w", err)
 		}
 	}
 
@@ -77,7 +99,7 @@ func (a *App) ConnectTo(ctx context.Context, params *ConnectToInput) error {
 	}
 
 	if err := config.Unmarshall(cs, useParams); err != nil {
-		return fmt.Errorf("unmarshalling config into use params: %w", err)
+		return nil, fmt.Errorf("unmarshalling config into use params: %w", err)
 	}
 
 	useParams.EntryID = historyID
@@ -92,7 +114,7 @@ func (a *App) ConnectTo(ctx context.Context, params *ConnectToInput) error {
 		useParams.Kubeconfig = entry.Spec.ConfigFile
 	}
 
-	return a.Use(ctx, useParams)
+	return useParams, nil
 }
 
 func (a *App) getHistoryEntry(params *ConnectToInput) (*historyv1alpha.HistoryEntry, error) {
@@ -132,7 +154,11 @@ func (a *App) getHistoryEntry(params *ConnectToInput) (*historyv1alpha.HistoryEn
 		return entry, nil
 	}
 
-	entry, err := a.historyStore.GetByID(idOrAliasORPosition)
+	return a.getHistoryEntryByIDOrAlias(idOrAliasORPosition)
+}
+
+func (a *App) getHistoryEntryByIDOrAlias(idOrAlias string) (*historyv1alpha.HistoryEntry, error) {
+	entry, err := a.historyStore.GetByID(idOrAlias)
 	if err != nil {
 		return nil, fmt.Errorf("getting history entry by id: %w", err)
 	}
@@ -141,7 +167,7 @@ func (a *App) getHistoryEntry(params *ConnectToInput) (*historyv1alpha.HistoryEn
 		return entry, nil
 	}
 
-	entry, err = a.historyStore.GetByAlias(idOrAliasORPosition)
+	entry, err = a.historyStore.GetByAlias(idOrAlias)
 	if err != nil {
 		return nil, fmt.Errorf("getting history entry by alias: %w", err)
 	}
@@ -283,6 +309,13 @@ func (a *App) buildConnectToConfig(configFile string, discoveryProvider string,
 			}
 
 			configItem.Value = boolVal
+		case config.ItemTypeDuration:
+			durationVal, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, err
+			}
+
+			configItem.Value = durationVal
 		default:
 			return nil, fmt.Errorf("trying to set config item %s of type %s: %w", configItem.Name, configItem.Type, ErrUnknownConfigItemType)
 		}
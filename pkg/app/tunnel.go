@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"k8s.io/client-go/tools/clientcmd"
+
+	historyv1alpha "github.com/fidelity/kconnect/api/v1alpha1"
+	"github.com/fidelity/kconnect/pkg/history"
+	"github.com/fidelity/kconnect/pkg/k8s/kubeconfig"
+	"github.com/fidelity/kconnect/pkg/tunnel"
+)
+
+// TunnelInput defines the inputs for Tunnel
+type TunnelInput struct {
+	CommonConfig
+	HistoryLocationConfig
+	KubernetesConfig
+
+	Entry          string
+	JumpHost       string `json:"jump-host"`
+	SSHKeyFile     string `json:"ssh-key-file"`
+	KnownHostsFile string `json:"known-hosts-file"`
+	ListenAddress  string `json:"listen-address"`
+}
+
+// Tunnel will start a local SOCKS5 and HTTP CONNECT proxy that forwards connections through a
+// jump host using SSH. The clusters of the contexts for the history entry are changed to use the
+// proxy until the context is cancelled, when their previous proxy url is restored.
+func (a *App) Tunnel(ctx context.Context, input *TunnelInput) error {
+	entry, err := a.getHistoryEntryByIDOrAlias(input.Entry)
+	if err != nil {
+		return fmt.Errorf("getting history entry: %w", err)
+	}
+
+	if entry == nil {
+		return history.ErrEntryNotFound
+	}
+
+	kubeconfigPath := input.Kubeconfig
+	if kubeconfigPath == "" {
+		kubeconfigPath = entry.Spec.ConfigFile
+	}
+
+	if kubeconfigPath == "" {
+		kubeconfigPath = clientcmd.NewDefaultPathOptions().GetDefaultFilename()
+	}
+
+	sshClient, err := tunnel.DialSSH(&tunnel.SSHConfig{
+		JumpHost:       input.JumpHost,
+		KeyFile:        input.SSHKeyFile,
+		KnownHostsFile: input.KnownHostsFile,
+	})
+	if err != nil {
+		return fmt.Errorf("connecting to jump host: %w", err)
+	}
+	defer sshClient.Close() //nolint: errcheck
+
+	listener, err := net.Listen("tcp", input.ListenAddress)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", input.ListenAddress, err)
+	}
+
+	proxyURL := "socks5://" + listener.Addr().String()
+
+	previous, err := setEntryProxyURL(kubeconfigPath, entry.Name, proxyURL)
+	if err != nil {
+		listener.Close() //nolint: errcheck
+		return err
+	}
+
+	defer func() {
+		if err := restoreEntryProxyURL(kubeconfigPath, proxyURL, previous); err != nil {
+			a.logger.Errorw("failed restoring the proxy url of the clusters", "kubeconfig", kubeconfigPath, "error", err.Error())
+		}
+	}()
+
+	a.logger.Infow("tunnel started, press Ctrl+C to stop it", "entry", input.Entry, "jump-host", input.JumpHost, "proxy-url", proxyURL)
+
+	if err := tunnel.NewForwarder(sshClient.Dial).Serve(ctx, listener); err != nil {
+		return fmt.Errorf("forwarding connections: %w", err)
+	}
+
+	a.logger.Info("tunnel stopped")
+
+	return nil
+}
+
+// setEntryProxyURL will set the proxy url of the clusters used by the contexts that reference
+// the history entry. The previous proxy url of each cluster is returned.
+func setEntryProxyURL(kubeconfigPath, entryID, proxyURL string) (map[string]string, error) {
+	cfg, err := kubeconfig.Read(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := map[string]string{}
+
+	for _, kubeContext := range cfg.Contexts {
+		historyRef, err := historyv1alpha.GetHistoryReferenceFromContext(kubeContext)
+		if err != nil {
+			if errors.Is(err, historyv1alpha.ErrNoHistoryExtension) {
+				continue
+			}
+
+			return nil, fmt.Errorf("getting history reference from context: %w", err)
+		}
+
+		cluster, ok := cfg.Clusters[kubeContext.Cluster]
+		if !ok || historyRef.EntryID != entryID {
+			continue
+		}
+
+		if _, done := previous[kubeContext.Cluster]; !done {
+			previous[kubeContext.Cluster] = cluster.ProxyURL
+			cluster.ProxyURL = proxyURL
+		}
+	}
+
+	if len(previous) == 0 {
+		return nil, fmt.Errorf("finding context for entry %s in %s: %w", entryID, kubeconfigPath, ErrContextNotFound)
+	}
+
+	if err := kubeconfig.Write(kubeconfigPath, cfg, false, false); err != nil {
+		return nil, err
+	}
+
+	return previous, nil
+}
+
+// restoreEntryProxyURL will set the proxy url of the clusters back to what it was before the
+// tunnel was started. Clusters that have been changed since the tunnel was started are left alone.
+func restoreEntryProxyURL(kubeconfigPath, proxyURL string, previous map[string]string) error {
+	cfg, err := kubeconfig.Read(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	for clusterName, previousURL := range previous {
+		if cluster, ok := cfg.Clusters[clusterName]; ok && cluster.ProxyURL == proxyURL {
+			cluster.ProxyURL = previousURL
+		}
+	}
+
+	return kubeconfig.Write(kubeconfigPath, cfg, false, false)
+}
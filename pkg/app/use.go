@@ -19,15 +19,21 @@ package app
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
+	"regexp"
 	"slices"
 	"strconv"
+	"time"
 
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
 
 	historyv1alpha "github.com/fidelity/kconnect/api/v1alpha1"
 	"github.com/fidelity/kconnect/pkg/config"
+	khttp "github.com/fidelity/kconnect/pkg/http"
 	"github.com/fidelity/kconnect/pkg/k8s/kubeconfig"
 	"github.com/fidelity/kconnect/pkg/prompt"
 	"github.com/fidelity/kconnect/pkg/provider"
@@ -41,6 +47,8 @@ import (
 type UseInput struct {
 	CommonConfig
 	CommonUseConfig
+	ClusterEndpointConfig
+	HTTPConfig
 	HistoryConfig
 	KubernetesConfig
 	common.IdentityProviderConfig
@@ -59,108 +67,241 @@ type UseInput struct {
 func (a *App) Use(ctx context.Context, input *UseInput) error {
 	a.logger.Debug("use command")
 
-	identityProvider, err := a.getIdentityProvider(&input.IdentityProvider, &input.DiscoveryProvider)
+	clusterProvider, userID, err := a.authenticateAndResolve(ctx, input)
 	if err != nil {
-		return fmt.Errorf("getting identity provider: %w", err)
+		return err
+	}
+
+	if input.All {
+		return a.useAll(ctx, clusterProvider, userID, input)
+	}
+
+	cluster, err := a.findCluster(ctx, clusterProvider, userID, input)
+	if err != nil {
+		return err
+	}
+
+	if cluster == nil {
+		return nil
+	}
+
+	if err := a.chooseNamespace(ctx, clusterProvider, cluster, input); err != nil {
+		return err
+	}
+
+	output, err := a.getClusterConfig(ctx, clusterProvider, userID, input, cluster)
+	if err != nil {
+		return err
+	}
+
+	if !input.IgnoreAlias {
+		if err := a.resolveAndCheckAlias(input); err != nil {
+			return fmt.Errorf("resolving and checking alias: %w", err)
+		}
+	}
+
+	if err := applyNamingTemplates(input, cluster, userID, output); err != nil {
+		return fmt.Errorf("applying naming templates: %w", err)
+	}
+
+	historyID, err := a.addHistoryEntry(input, cluster)
+	if err != nil {
+		return err
+	}
+
+	kubeConfig := output.KubeConfig
+	addHistoryReference(kubeConfig, *output.ContextName, historyID)
+
+	if input.Kubeconfig == "" {
+		a.logger.Debug("no kubeconfig supplied, setting default")
+
+		pathOptions := clientcmd.NewDefaultPathOptions()
+		input.Kubeconfig = pathOptions.GetDefaultFilename()
+	}
+
+	if err := kubeconfig.Write(input.Kubeconfig, kubeConfig, true, input.SetCurrent); err != nil {
+		return fmt.Errorf("writing cluster kubeconfig: %w", err)
+	}
+
+	a.pruneAWSProfilesAfterUse(input)
+
+	return nil
+}
+
+// getUseProviders will create the identity and discovery providers for the
+// use input and check they can be used together.
+func (a *App) getUseProviders(input *UseInput) (identity.Provider, discovery.Provider, error) {
+	httpClient, err := a.httpClientFor(&input.HTTPConfig)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	clusterProvider, err := a.getDiscoveryProvider(&input.DiscoveryProvider, &input.IdentityProvider)
+	identityProvider, err := a.getIdentityProvider(&input.IdentityProvider, &input.DiscoveryProvider, httpClient)
 	if err != nil {
-		return fmt.Errorf("getting discovery provider: %w", err)
+		return nil, nil, fmt.Errorf("getting identity provider: %w", err)
+	}
+
+	clusterProvider, err := a.getDiscoveryProvider(&input.DiscoveryProvider, &input.IdentityProvider, httpClient)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting discovery provider: %w", err)
 	}
 
 	if !isIdpSupported(identityProvider.Name(), clusterProvider) {
-		return fmt.Errorf("using identity provider %s: %w", input.IdentityProvider, ErrUnsuportedIdpProtocol)
+		return nil, nil, fmt.Errorf("using identity provider %s: %w", input.IdentityProvider, ErrUnsuportedIdpProtocol)
 	}
 
-	err = clusterProvider.CheckPreReqs()
+	return identityProvider, clusterProvider, nil
+}
+
+// authenticateAndResolve will authenticate the user using the identity provider and then
+// resolve the config items of the discovery provider with the resulting identity.
+func (a *App) authenticateAndResolve(ctx context.Context, input *UseInput) (discovery.Provider, identity.Identity, error) {
+	identityProvider, clusterProvider, err := a.getUseProviders(input)
 	if err != nil {
-		//TODO: how to report this???
-		fmt.Fprintf(os.Stderr, "\033[33m%s\033[0m\n", err.Error())
+		return nil, nil, err
 	}
 
 	authOutput, err := identityProvider.Authenticate(ctx, &identity.AuthenticateInput{
 		ConfigSet: input.ConfigSet,
 	})
 	if err != nil {
-		return fmt.Errorf("authenticating using provider %s: %w", identityProvider.Name(), err)
+		return nil, nil, fmt.Errorf("authenticating using provider %s: %w", identityProvider.Name(), err)
 	}
 
 	if err := clusterProvider.Resolve(input.ConfigSet, authOutput.Identity); err != nil {
-		return fmt.Errorf("resolving config items: %w", err)
+		return nil, nil, fmt.Errorf("resolving config items: %w", err)
 	}
 
-	var cluster *discovery.Cluster
+	// the prerequisites are checked after resolving as they can depend on the config
+	if err := clusterProvider.CheckPreReqs(); err != nil {
+		//TODO: how to report this???
+		fmt.Fprintf(os.Stderr, "\033[33m%s\033[0m\n", err.Error())
+	}
+
+	return clusterProvider, authOutput.Identity, nil
+}
+
+// findCluster will find the cluster to use, either by discovery or by its id. If no
+// cluster is found then nil is returned.
+func (a *App) findCluster(ctx context.Context, clusterProvider discovery.Provider, identity identity.Identity, input *UseInput) (*discovery.Cluster, error) {
 	if input.ClusterID == nil || *input.ClusterID == "" {
-		cluster, err = a.discoverCluster(ctx, clusterProvider, authOutput.Identity, input)
-	} else {
-		cluster, err = a.getCluster(ctx, clusterProvider, authOutput.Identity, input)
+		return a.discoverCluster(ctx, clusterProvider, identity, input)
 	}
 
-	if err != nil {
-		return err
+	return a.getCluster(ctx, clusterProvider, identity, input)
+}
+
+// chooseNamespace will let the discovery provider choose the namespace for the selected
+// cluster if it supports it and no namespace was supplied. This may prompt the user so
+// it's only done when connecting to a single cluster.
+func (a *App) chooseNamespace(ctx context.Context, clusterProvider discovery.Provider, cluster *discovery.Cluster, input *UseInput) error {
+	if input.Namespace != "" {
+		return nil
 	}
 
-	if cluster == nil {
+	chooser, ok := clusterProvider.(discovery.NamespaceChooser)
+	if !ok {
 		return nil
 	}
 
+	namespace, err := chooser.ChooseNamespace(ctx, cluster)
+	if err != nil {
+		return fmt.Errorf("choosing namespace for %s: %w", cluster.Name, err)
+	}
+
+	input.Namespace = namespace
+
+	return nil
+}
+
+// getClusterConfig will generate the kubeconfig for the cluster. This is called concurrently
+// when generating the kubeconfig for many clusters.
+func (a *App) getClusterConfig(ctx context.Context, clusterProvider discovery.Provider, identity identity.Identity, input *UseInput, cluster *discovery.Cluster) (*discovery.GetConfigOutput, error) {
 	output, err := clusterProvider.GetConfig(ctx, &discovery.GetConfigInput{
 		Cluster:   cluster,
 		Namespace: &input.Namespace,
-		Identity:  authOutput.Identity,
+		Identity:  identity,
 	})
 	if err != nil {
-		return fmt.Errorf("creating kubeconfig for %s: %w", cluster.Name, err)
+		return nil, fmt.Errorf("creating kubeconfig for %s: %w", cluster.Name, err)
 	}
 
-	if !input.IgnoreAlias {
-		if err := a.resolveAndCheckAlias(input); err != nil {
-			return fmt.Errorf("resolving and checking alias: %w", err)
-		}
+	if err := applyClusterEndpointConfig(output.KubeConfig, &input.ClusterEndpointConfig); err != nil {
+		return nil, fmt.Errorf("changing endpoint for %s: %w", cluster.Name, err)
 	}
 
-	historyID := input.EntryID
-	if !input.NoHistory {
-		entry := historyv1alpha.NewHistoryEntry()
-		entry.Spec.Alias = input.Alias
-		entry.Spec.ConfigFile = input.Kubeconfig
-		entry.Spec.Flags = a.filterConfig(input)
-		entry.Spec.Identity = input.IdentityProvider
-		entry.Spec.Provider = input.DiscoveryProvider
-		entry.Spec.ProviderID = cluster.ID
+	return output, nil
+}
 
-		if err := a.historyStore.Add(entry); err != nil {
-			return fmt.Errorf("adding connection to history: %w", err)
-		}
+// addHistoryEntry will add an entry to the history for the cluster connection. If
+// history is disabled then the existing entry id (if any) is returned.
+func (a *App) addHistoryEntry(input *UseInput, cluster *discovery.Cluster) (string, error) {
+	if input.NoHistory {
+		return input.EntryID, nil
+	}
 
-		historyID = entry.ObjectMeta.Name
+	entry := a.newHistoryEntry(input, cluster)
+
+	if err := a.historyStore.Add(entry); err != nil {
+		return "", fmt.Errorf("adding connection to history: %w", err)
 	}
 
-	kubeConfig := output.KubeConfig
-	contextName := *output.ContextName
+	return entry.ObjectMeta.Name, nil
+}
+
+// newHistoryEntry creates the history entry for the cluster connection
+func (a *App) newHistoryEntry(input *UseInput, cluster *discovery.Cluster) *historyv1alpha.HistoryEntry {
+	entry := historyv1alpha.NewHistoryEntry()
+	entry.Spec.Alias = input.Alias
+	entry.Spec.ConfigFile = input.Kubeconfig
+	entry.Spec.Flags = a.filterConfig(input)
+	entry.Spec.Identity = input.IdentityProvider
+	entry.Spec.Provider = input.DiscoveryProvider
+	entry.Spec.ProviderID = cluster.ID
+
+	return entry
+}
+
+// mergeKubeconfig will merge the clusters, users and contexts from src into dst
+func mergeKubeconfig(dst, src *api.Config) {
+	maps.Copy(dst.Clusters, src.Clusters)
+	maps.Copy(dst.AuthInfos, src.AuthInfos)
+	maps.Copy(dst.Contexts, src.Contexts)
+}
 
-	if historyID != "" {
-		historyRef := historyv1alpha.NewHistoryReference(historyID)
-		kubeConfig.Contexts[contextName].Extensions = make(map[string]runtime.Object)
-		kubeConfig.Contexts[contextName].Extensions["kconnect"] = historyRef
+// addHistoryReference will add a reference to the history entry to the context
+func addHistoryReference(kubeConfig *api.Config, contextName, historyID string) {
+	if historyID == "" {
+		return
 	}
 
-	if input.Kubeconfig == "" {
-		a.logger.Debug("no kubeconfig supplied, setting default")
+	historyRef := historyv1alpha.NewHistoryReference(historyID)
+	kubeConfig.Contexts[contextName].Extensions = make(map[string]runtime.Object)
+	kubeConfig.Contexts[contextName].Extensions["kconnect"] = historyRef
+}
 
-		pathOptions := clientcmd.NewDefaultPathOptions()
-		input.Kubeconfig = pathOptions.GetDefaultFilename()
+func (a *App) discoverCluster(ctx context.Context, clusterProvider discovery.Provider, identity identity.Identity, params *UseInput) (*discovery.Cluster, error) {
+	discoverOutput, err := a.discoverClusters(ctx, clusterProvider, identity, params)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := kubeconfig.Write(input.Kubeconfig, kubeConfig, true, input.SetCurrent); err != nil {
-		return fmt.Errorf("writing cluster kubeconfig: %w", err)
+	if discoverOutput.Clusters == nil || len(discoverOutput.Clusters) == 0 {
+		a.logger.Warn("no clusters discovered")
+		return nil, nil
 	}
 
-	return nil
+	cluster, err := a.selectCluster(discoverOutput)
+	if err != nil {
+		return nil, fmt.Errorf("selecting cluster: %w", err)
+	}
+
+	return cluster, nil
 }
 
-func (a *App) discoverCluster(ctx context.Context, clusterProvider discovery.Provider, identity identity.Identity, params *UseInput) (*discovery.Cluster, error) {
+// discoverClusters will discover the clusters using the discovery provider and then
+// apply the cluster filter and selector
+func (a *App) discoverClusters(ctx context.Context, clusterProvider discovery.Provider, identity identity.Identity, params *UseInput) (*discovery.DiscoverOutput, error) {
 	a.logger.Infow("discovering clusters", "provider", params.DiscoveryProvider)
 
 	discoverOutput, err := clusterProvider.Discover(ctx, &discovery.DiscoverInput{
@@ -171,17 +312,61 @@ func (a *App) discoverCluster(ctx context.Context, clusterProvider discovery.Pro
 		return nil, fmt.Errorf("discovering clusters using %s: %w", clusterProvider.Name(), err)
 	}
 
-	if discoverOutput.Clusters == nil || len(discoverOutput.Clusters) == 0 {
-		a.logger.Warn("no clusters discovered")
-		return nil, nil
+	if err := a.checkDiscoverWarnings(discoverOutput, params.Strict); err != nil {
+		return nil, fmt.Errorf("discovering clusters using %s: %w", clusterProvider.Name(), err)
 	}
 
-	cluster, err := a.selectCluster(discoverOutput)
+	if err := filterClusters(discoverOutput, params.ClusterFilter, params.ClusterSelector); err != nil {
+		return nil, err
+	}
+
+	return discoverOutput, nil
+}
+
+// checkDiscoverWarnings will log a summary of the clusters or scopes that couldn't be
+// discovered. When strict is set any warning is returned as an error instead.
+func (a *App) checkDiscoverWarnings(discoverOutput *discovery.DiscoverOutput, strict bool) error {
+	if len(discoverOutput.Warnings) == 0 {
+		return nil
+	}
+
+	if strict {
+		return discoverOutput.WarningsError()
+	}
+
+	a.logger.Warnw("some clusters couldn't be discovered, using the clusters that were discovered", "provider", discoverOutput.DiscoveryProvider, "warnings", len(discoverOutput.Warnings))
+
+	for _, warning := range discoverOutput.Warnings {
+		a.logger.Warnw("discovery warning", "scope", warning.Scope, "id", warning.ID, "error", warning.Message)
+	}
+
+	return nil
+}
+
+// filterClusters will remove any discovered clusters whose name doesn't match the filter
+// or whose labels don't match the selector
+func filterClusters(discoverOutput *discovery.DiscoverOutput, filter, selector string) error {
+	if filter == "" && selector == "" {
+		return nil
+	}
+
+	filterRegex, err := regexp.Compile(filter)
 	if err != nil {
-		return nil, fmt.Errorf("selecting cluster: %w", err)
+		return fmt.Errorf("compiling cluster filter %s: %w", filter, err)
 	}
 
-	return cluster, nil
+	labelSelector, err := labels.Parse(selector)
+	if err != nil {
+		return fmt.Errorf("parsing cluster selector %s: %w", selector, err)
+	}
+
+	for clusterID, cluster := range discoverOutput.Clusters {
+		if !filterRegex.MatchString(cluster.Name) || !labelSelector.Matches(cluster.SelectorSet()) {
+			delete(discoverOutput.Clusters, clusterID)
+		}
+	}
+
+	return nil
 }
 
 func (a *App) getCluster(ctx context.Context, clusterProvider discovery.Provider, identity identity.Identity, params *UseInput) (*discovery.Cluster, error) {
@@ -222,6 +407,9 @@ func (a *App) filterConfig(params *UseInput) map[string]string {
 		case config.ItemTypeInt:
 			intVal := configItem.Value.(int)
 			val = strconv.Itoa(intVal)
+		case config.ItemTypeDuration:
+			durationVal := configItem.Value.(time.Duration)
+			val = durationVal.String()
 		}
 
 		filteredConfig[configItem.Name] = val
@@ -293,7 +481,7 @@ func (a *App) resolveAlias() (string, error) {
 	return alias, nil
 }
 
-func (a *App) getDiscoveryProvider(name *string, scopedToIdentityProvider *string) (discovery.Provider, error) {
+func (a *App) getDiscoveryProvider(name *string, scopedToIdentityProvider *string, httpClient khttp.Client) (discovery.Provider, error) {
 	if name == nil || *name == "" {
 		return nil, ErrDiscoveryProviderRequired
 	}
@@ -303,7 +491,7 @@ func (a *App) getDiscoveryProvider(name *string, scopedToIdentityProvider *strin
 		IsInteractive: a.interactive,
 		ItemSelector:  a.itemSelector,
 		ScopedTo:      scopedToIdentityProvider,
-		HTTPClient:    a.httpClient,
+		HTTPClient:    httpClient,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("getting discovery provider %s: %w", *name, err)
@@ -312,7 +500,7 @@ func (a *App) getDiscoveryProvider(name *string, scopedToIdentityProvider *strin
 	return prov, nil
 }
 
-func (a *App) getIdentityProvider(name *string, scopedToDiscoveryProvider *string) (identity.Provider, error) {
+func (a *App) getIdentityProvider(name *string, scopedToDiscoveryProvider *string, httpClient khttp.Client) (identity.Provider, error) {
 	if name == nil || *name == "" {
 		return nil, ErrIdentityProviderRequired
 	}
@@ -322,7 +510,7 @@ func (a *App) getIdentityProvider(name *string, scopedToDiscoveryProvider *strin
 		IsInteractive: a.interactive,
 		ItemSelector:  a.itemSelector,
 		ScopedTo:      scopedToDiscoveryProvider,
-		HTTPClient:    a.httpClient,
+		HTTPClient:    httpClient,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("getting identity provider %s: %w", *name, err)
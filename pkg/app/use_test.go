@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/pkg/provider/discovery"
+)
+
+func Test_FilterClusters(t *testing.T) {
+	testCases := []struct {
+		name      string
+		filter    string
+		selector  string
+		expectIDs []string
+		expectErr bool
+	}{
+		{
+			name:      "no filter or selector",
+			expectIDs: []string{"dev", "prod", "test"},
+		},
+		{
+			name:      "name filter",
+			filter:    "^(dev|test)$",
+			expectIDs: []string{"dev", "test"},
+		},
+		{
+			name:      "label selector",
+			selector:  "region=eu-west-2",
+			expectIDs: []string{"dev", "prod"},
+		},
+		{
+			name:      "status and version selector",
+			selector:  "status=ACTIVE,version!=1.21",
+			expectIDs: []string{"prod"},
+		},
+		{
+			name:      "filter and selector",
+			filter:    "dev",
+			selector:  "env in (prod)",
+			expectIDs: []string{},
+		},
+		{
+			name:      "invalid selector",
+			selector:  "region in",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			discoverOutput := &discovery.DiscoverOutput{
+				Clusters: map[string]*discovery.Cluster{
+					"dev":  testCluster("dev", "1.21", "ACTIVE", map[string]string{"region": "eu-west-2", "env": "dev"}),
+					"prod": testCluster("prod", "1.22", "ACTIVE", map[string]string{"region": "eu-west-2", "env": "prod"}),
+					"test": testCluster("test", "1.22", "CREATING", map[string]string{"region": "us-east-1", "env": "test"}),
+				},
+			}
+
+			err := filterClusters(discoverOutput, tc.filter, tc.selector)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			ids := []string{}
+			for id := range discoverOutput.Clusters {
+				ids = append(ids, id)
+			}
+
+			sort.Strings(ids)
+
+			if len(ids) != len(tc.expectIDs) {
+				t.Fatalf("expected clusters %v but got %v", tc.expectIDs, ids)
+			}
+
+			for i := range ids {
+				if ids[i] != tc.expectIDs[i] {
+					t.Fatalf("expected clusters %v but got %v", tc.expectIDs, ids)
+				}
+			}
+		})
+	}
+}
+
+func Test_CheckDiscoverWarnings(t *testing.T) {
+	testCases := []struct {
+		name      string
+		warnings  []*discovery.Warning
+		strict    bool
+		expectErr bool
+	}{
+		{
+			name: "no warnings",
+		},
+		{
+			name:   "no warnings strict",
+			strict: true,
+		},
+		{
+			name:     "warnings",
+			warnings: []*discovery.Warning{discovery.NewWarning(discovery.WarningScopeCluster, "dev", errors.New("access denied"))},
+		},
+		{
+			name:      "warnings strict",
+			warnings:  []*discovery.Warning{discovery.NewWarning(discovery.WarningScopeCluster, "dev", errors.New("access denied"))},
+			strict:    true,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &App{logger: zap.NewNop().Sugar()}
+			discoverOutput := &discovery.DiscoverOutput{
+				Clusters: map[string]*discovery.Cluster{
+					"prod": testCluster("prod", "1.22", "ACTIVE", map[string]string{}),
+				},
+				Warnings: tc.warnings,
+			}
+
+			err := a.checkDiscoverWarnings(discoverOutput, tc.strict)
+			if !tc.expectErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, discovery.ErrPartialDiscovery) {
+				t.Fatalf("expected partial discovery error but got %v", err)
+			}
+		})
+	}
+}
+
+func testCluster(name, version, status string, labels map[string]string) *discovery.Cluster {
+	return &discovery.Cluster{
+		ID:      name,
+		Name:    name,
+		Version: &version,
+		Status:  &status,
+		Labels:  labels,
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/fidelity/kconnect/pkg/k8s/kubeconfig"
+	"github.com/fidelity/kconnect/pkg/provider/discovery"
+	"github.com/fidelity/kconnect/pkg/provider/identity"
+)
+
+// clusterConfigResult holds the result of generating the kubeconfig for a cluster
+type clusterConfigResult struct {
+	cluster *discovery.Cluster
+	output  *discovery.GetConfigOutput
+	err     error
+}
+
+// useAll will generate a kubeconfig context for all the discovered clusters that match
+// the cluster filter. The configs are generated concurrently and then written to
+// the kubeconfig in a single write. A history entry is created for each cluster.
+func (a *App) useAll(ctx context.Context, clusterProvider discovery.Provider, identity identity.Identity, input *UseInput) error {
+	if input.ClusterID != nil && *input.ClusterID != "" {
+		a.logger.Warnw("cluster id is ignored when generating contexts for all clusters", "id", *input.ClusterID)
+	}
+
+	if input.Alias != nil && *input.Alias != "" {
+		a.logger.Warnw("alias is ignored when generating contexts for all clusters, use --alias-template instead", "alias", *input.Alias)
+	}
+
+	if chooser, ok := clusterProvider.(discovery.NamespaceChooser); ok && chooser.NamespaceChoiceEnabled() {
+		a.logger.Warnw("the namespace can't be chosen when generating contexts for all clusters, use --namespace instead")
+	}
+
+	discoverOutput, err := a.discoverClusters(ctx, clusterProvider, identity, input)
+	if err != nil {
+		return err
+	}
+
+	if len(discoverOutput.Clusters) == 0 {
+		a.logger.Warn("no clusters discovered")
+		return nil
+	}
+
+	results := a.getAllClusterConfigs(ctx, clusterProvider, identity, input, discoverOutput)
+
+	kubeConfig := api.NewConfig()
+	failed := 0
+
+	for _, result := range results {
+		if result.err != nil {
+			a.logger.Warnw("failed generating kubeconfig for cluster", "cluster", result.cluster.Name, "error", result.err.Error())
+			failed++
+
+			continue
+		}
+
+		alias, err := a.templateAlias(input, result.cluster)
+		if err != nil {
+			return fmt.Errorf("generating alias for cluster %s: %w", result.cluster.Name, err)
+		}
+
+		clusterInput := *input
+		clusterInput.Alias = &alias
+
+		if err := applyNamingTemplates(&clusterInput, result.cluster, identity, result.output); err != nil {
+			a.logger.Warnw("failed applying naming templates for cluster", "cluster", result.cluster.Name, "error", err.Error())
+			failed++
+
+			continue
+		}
+
+		historyID, err := a.addHistoryEntry(&clusterInput, result.cluster)
+		if err != nil {
+			return err
+		}
+
+		contextName := *result.output.ContextName
+		addHistoryReference(result.output.KubeConfig, contextName, historyID)
+		mergeKubeconfig(kubeConfig, result.output.KubeConfig)
+
+		a.logger.Infow("generated context for cluster", "cluster", result.cluster.Name, "context", contextName)
+	}
+
+	if len(kubeConfig.Contexts) > 0 {
+		if input.Kubeconfig == "" {
+			a.logger.Debug("no kubeconfig supplied, setting default")
+
+			pathOptions := clientcmd.NewDefaultPathOptions()
+			input.Kubeconfig = pathOptions.GetDefaultFilename()
+		}
+
+		if err := kubeconfig.Write(input.Kubeconfig, kubeConfig, true, false); err != nil {
+			return fmt.Errorf("writing cluster kubeconfig: %w", err)
+		}
+	}
+
+	a.pruneAWSProfilesAfterUse(input)
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d clusters: %w", failed, len(results), ErrUseAllFailed)
+	}
+
+	return nil
+}
+
+// getAllClusterConfigs will concurrently generate the kubeconfig for each of the discovered
+// clusters using the same provider, so GetConfig mustn't change the provider or prompt the
+// user. The results are sorted by cluster name.
+func (a *App) getAllClusterConfigs(ctx context.Context, clusterProvider discovery.Provider, identity identity.Identity, input *UseInput, discoverOutput *discovery.DiscoverOutput) []*clusterConfigResult {
+	results := make([]*clusterConfigResult, 0, len(discoverOutput.Clusters))
+	for _, cluster := range discoverOutput.Clusters {
+		results = append(results, &clusterConfigResult{cluster: cluster})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].cluster.Name < results[j].cluster.Name
+	})
+
+	var wg sync.WaitGroup
+
+	for _, result := range results {
+		wg.Add(1)
+
+		go func(result *clusterConfigResult) {
+			defer wg.Done()
+
+			result.output, result.err = a.getClusterConfig(ctx, clusterProvider, identity, input, result.cluster)
+		}(result)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// templateAlias will generate the alias for a cluster using the alias template. If
+// the generated alias is already used by a different connection then no alias is used.
+// A connection is only the same if the history would treat it as the same entry, so
+// the same cluster with different flags (e.g. another role or region) doesn't get the
+// alias as that would create a duplicate alias in the history.
+func (a *App) templateAlias(input *UseInput, cluster *discovery.Cluster) (string, error) {
+	if input.AliasTemplate == "" {
+		return "", nil
+	}
+
+	alias, err := executeTemplate("alias", input.AliasTemplate, &aliasTemplateData{
+		Provider:    input.DiscoveryProvider,
+		ClusterName: cluster.Name,
+		ClusterID:   cluster.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	entry, err := a.historyStore.GetByAlias(alias)
+	if err != nil {
+		return "", fmt.Errorf("getting history by alias: %w", err)
+	}
+
+	if entry == nil {
+		return alias, nil
+	}
+
+	// the kubeconfig file is ignored when the history checks if a connection exists
+	connection := a.newHistoryEntry(input, cluster)
+	connection.Spec.ConfigFile = entry.Spec.ConfigFile
+
+	if !entry.Equals(connection) {
+		a.logger.Warnw("alias already in use by another connection, no alias will be set", "alias", alias, "cluster", cluster.Name)
+		return "", nil
+	}
+
+	return alias, nil
+}
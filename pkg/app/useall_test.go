@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	historyv1alpha "github.com/fidelity/kconnect/api/v1alpha1"
+	"github.com/fidelity/kconnect/pkg/provider/discovery"
+)
+
+func Test_TemplateAlias(t *testing.T) {
+	testCases := []struct {
+		name        string
+		template    string
+		clusterID   string
+		tenant      string
+		expectAlias string
+		expectErr   bool
+	}{
+		{
+			name:        "no template",
+			clusterID:   "dev",
+			expectAlias: "",
+		},
+		{
+			name:        "template",
+			template:    "{{ .Provider }}-{{ .ClusterName }}",
+			clusterID:   "dev",
+			expectAlias: "test-discovery-dev",
+		},
+		{
+			name:        "alias used by the same connection",
+			template:    "{{ .ClusterID }}-alias",
+			clusterID:   "prod",
+			tenant:      "a",
+			expectAlias: "prod-alias",
+		},
+		{
+			name:        "alias used by the same cluster with different flags",
+			template:    "{{ .ClusterID }}-alias",
+			clusterID:   "prod",
+			tenant:      "b",
+			expectAlias: "",
+		},
+		{
+			name:        "alias used by another connection",
+			template:    "prod-alias",
+			clusterID:   "dev",
+			expectAlias: "",
+		},
+		{
+			name:      "invalid template",
+			template:  "{{ .Missing }}",
+			clusterID: "dev",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newTestApp(t)
+
+			alias := "prod-alias"
+			entry := historyv1alpha.NewHistoryEntry()
+			entry.Spec.Alias = &alias
+			entry.Spec.Identity = testIdentityProviderName
+			entry.Spec.Provider = testDiscoveryProviderName
+			entry.Spec.ProviderID = "prod"
+			entry.Spec.Flags = map[string]string{"tenant": "a"}
+
+			if err := a.historyStore.Add(entry); err != nil {
+				t.Fatalf("adding history entry: %s", err)
+			}
+
+			input := testGroupConnection(t, tc.tenant, tc.clusterID, "").input
+			input.AliasTemplate = tc.template
+
+			actual, err := a.templateAlias(input, &discovery.Cluster{ID: tc.clusterID, Name: tc.clusterID})
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if actual != tc.expectAlias {
+				t.Fatalf("expected alias %q but got %q", tc.expectAlias, actual)
+			}
+		})
+	}
+}
+
+func Test_GetAllClusterConfigs(t *testing.T) {
+	a := newTestApp(t, "test", "broken", "dev", "prod")
+
+	discoverOutput, err := testDiscoveryProvider.Discover(context.Background(), &discovery.DiscoverInput{})
+	if err != nil {
+		t.Fatalf("discovering clusters: %s", err)
+	}
+
+	results := a.getAllClusterConfigs(context.Background(), testDiscoveryProvider, &stubIdentity{name: "bob"}, &UseInput{}, discoverOutput)
+
+	expectNames := []string{"broken", "dev", "prod", "test"}
+	if len(results) != len(expectNames) {
+		t.Fatalf("expected %d results but got %d", len(expectNames), len(results))
+	}
+
+	for i, result := range results {
+		if result.cluster.Name != expectNames[i] {
+			t.Fatalf("expected result %d to be %s but got %s", i, expectNames[i], result.cluster.Name)
+		}
+
+		if result.cluster.Name == "broken" {
+			if !errors.Is(result.err, errTestGetConfig) {
+				t.Fatalf("expected error for broken cluster but got %v", result.err)
+			}
+
+			continue
+		}
+
+		if result.err != nil {
+			t.Fatalf("unexpected error for %s: %s", result.cluster.Name, result.err)
+		}
+
+		if *result.output.ContextName != result.cluster.Name {
+			t.Fatalf("expected context %s but got %s", result.cluster.Name, *result.output.ContextName)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/versent/saml2aws/v2/pkg/awsconfig"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultRoleSessionName is the session name used when assuming a role if one isn't supplied
+	DefaultRoleSessionName = "kconnect"
+	// MaxRoleChainingDuration is the longest session AWS allows when a role is assumed
+	// using the temporary credentials of another role
+	MaxRoleChainingDuration = time.Hour
+)
+
+// AssumeRoleInput is the input used to assume a role or chain of roles
+type AssumeRoleInput struct {
+	// RoleARNs are the roles to assume in turn. Each role is assumed using the
+	// credentials from the previous role.
+	RoleARNs []string
+	// ExternalIDs are the external ids for the roles. They are matched to the roles by position.
+	ExternalIDs  []string
+	SessionName  string
+	Duration     time.Duration
+	MFASerial    string
+	MFATokenCode string
+	Region       string
+	// FromRoleCredentials is set when the starting credentials are the temporary credentials
+	// of a role, for example from AssumeRoleWithSAML, so every role is assumed by role chaining
+	FromRoleCredentials bool
+}
+
+// AssumeRoleChain will assume each of the roles in turn starting with the supplied credentials. The
+// MFA details are only used when assuming the first role as the later roles are assumed using
+// temporary credentials. The duration of the roles assumed by role chaining is limited to 1 hour.
+func AssumeRoleChain(ctx context.Context, cfg aws.Config, input *AssumeRoleInput) (*awsconfig.AWSCredentials, error) {
+	if len(input.RoleARNs) == 0 {
+		return nil, ErrNoRoleToAssume
+	}
+
+	sessionName := input.SessionName
+	if sessionName == "" {
+		sessionName = DefaultRoleSessionName
+	}
+
+	var creds *awsconfig.AWSCredentials
+
+	for i, roleARN := range input.RoleARNs {
+		assumeRoleInput := &sts.AssumeRoleInput{
+			RoleArn:         aws.String(roleARN),
+			RoleSessionName: aws.String(sessionName),
+		}
+
+		if duration := roleDuration(input, i); duration > 0 {
+			assumeRoleInput.DurationSeconds = aws.Int32(int32(duration.Seconds()))
+		}
+
+		if i < len(input.ExternalIDs) && input.ExternalIDs[i] != "" {
+			assumeRoleInput.ExternalId = aws.String(input.ExternalIDs[i])
+		}
+
+		if i == 0 && input.MFASerial != "" {
+			assumeRoleInput.SerialNumber = aws.String(input.MFASerial)
+			assumeRoleInput.TokenCode = aws.String(input.MFATokenCode)
+		}
+
+		if creds != nil {
+			cfg = cfg.Copy()
+			cfg.Credentials = aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(creds.AWSAccessKey, creds.AWSSecretKey, creds.AWSSessionToken))
+		}
+
+		zap.S().Debugw("assuming role", "role", roleARN, "hop", i+1)
+
+		out, err := sts.NewFromConfig(cfg).AssumeRole(ctx, assumeRoleInput)
+		if err != nil {
+			return nil, fmt.Errorf("assuming role %s: %w", roleARN, err)
+		}
+
+		creds = &awsconfig.AWSCredentials{
+			AWSAccessKey:     aws.ToString(out.Credentials.AccessKeyId),
+			AWSSecretKey:     aws.ToString(out.Credentials.SecretAccessKey),
+			AWSSessionToken:  aws.ToString(out.Credentials.SessionToken),
+			AWSSecurityToken: aws.ToString(out.Credentials.SessionToken),
+			PrincipalARN:     aws.ToString(out.AssumedRoleUser.Arn),
+			Expires:          out.Credentials.Expiration.Local(),
+			Region:           input.Region,
+		}
+	}
+
+	return creds, nil
+}
+
+// roleDuration returns the session duration for the role at the position in the chain
+func roleDuration(input *AssumeRoleInput, position int) time.Duration {
+	chained := position > 0 || input.FromRoleCredentials
+	if !chained || input.Duration <= MaxRoleChainingDuration {
+		return input.Duration
+	}
+
+	zap.S().Warnw("limiting the session duration of the role as it's assumed by role chaining",
+		"role", input.RoleARNs[position], "duration", MaxRoleChainingDuration.String())
+
+	return MaxRoleChainingDuration
+}
+
+// SplitList will split a comma separated config value. Empty entries are kept so that
+// positional values can be skipped.
+func SplitList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	values := strings.Split(value, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+
+	return values
+}
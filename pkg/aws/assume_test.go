@@ -0,0 +1,196 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// stubSTS is a stubbed STS api that records the roles assumed
+type stubSTS struct {
+	lock      sync.Mutex
+	roles     []string
+	durations []string
+	mfaTokens []string
+}
+
+func (s *stubSTS) handler(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	r.ParseForm() //nolint: errcheck
+	if r.Form.Get("Action") != "AssumeRole" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	roleARN := r.Form.Get("RoleArn")
+	s.roles = append(s.roles, roleARN)
+	s.durations = append(s.durations, r.Form.Get("DurationSeconds"))
+	s.mfaTokens = append(s.mfaTokens, r.Form.Get("TokenCode"))
+
+	roleName := roleARN[strings.LastIndex(roleARN, "/")+1:]
+
+	w.Header().Set("Content-Type", "text/xml")
+	fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <AssumedRoleUser>
+      <Arn>arn:aws:sts::123456789012:assumed-role/%s/%s</Arn>
+      <AssumedRoleId>AROATEST:%s</AssumedRoleId>
+    </AssumedRoleUser>
+    <Credentials>
+      <AccessKeyId>ASIA%d</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>token</SessionToken>
+      <Expiration>%s</Expiration>
+    </Credentials>
+  </AssumeRoleResult>
+  <ResponseMetadata><RequestId>1</RequestId></ResponseMetadata>
+</AssumeRoleResponse>`, roleName, r.Form.Get("RoleSessionName"), r.Form.Get("RoleSessionName"), len(s.roles),
+		time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+}
+
+func Test_AssumeRoleChain(t *testing.T) {
+	testCases := []struct {
+		name            string
+		input           *AssumeRoleInput
+		expectDurations []string
+		expectMFATokens []string
+		expectARN       string
+		expectErr       error
+	}{
+		{
+			name:      "no roles",
+			input:     &AssumeRoleInput{},
+			expectErr: ErrNoRoleToAssume,
+		},
+		{
+			name: "single role with long session",
+			input: &AssumeRoleInput{
+				RoleARNs: []string{"arn:aws:iam::123456789012:role/first"},
+				Duration: 8 * time.Hour,
+			},
+			expectDurations: []string{"28800"},
+			expectMFATokens: []string{""},
+			expectARN:       "arn:aws:sts::123456789012:assumed-role/first/kconnect",
+		},
+		{
+			name: "chained roles limited to an hour",
+			input: &AssumeRoleInput{
+				RoleARNs:     []string{"arn:aws:iam::123456789012:role/first", "arn:aws:iam::123456789012:role/second"},
+				Duration:     8 * time.Hour,
+				MFASerial:    "arn:aws:iam::123456789012:mfa/bob",
+				MFATokenCode: "123456",
+				SessionName:  "bob",
+			},
+			expectDurations: []string{"28800", "3600"},
+			expectMFATokens: []string{"123456", ""},
+			expectARN:       "arn:aws:sts::123456789012:assumed-role/second/bob",
+		},
+		{
+			name: "chained roles with short session",
+			input: &AssumeRoleInput{
+				RoleARNs: []string{"arn:aws:iam::123456789012:role/first", "arn:aws:iam::123456789012:role/second"},
+				Duration: 30 * time.Minute,
+			},
+			expectDurations: []string{"1800", "1800"},
+			expectMFATokens: []string{"", ""},
+			expectARN:       "arn:aws:sts::123456789012:assumed-role/second/kconnect",
+		},
+		{
+			name: "starting from role credentials",
+			input: &AssumeRoleInput{
+				RoleARNs:            []string{"arn:aws:iam::123456789012:role/first"},
+				Duration:            8 * time.Hour,
+				FromRoleCredentials: true,
+			},
+			expectDurations: []string{"3600"},
+			expectMFATokens: []string{""},
+			expectARN:       "arn:aws:sts::123456789012:assumed-role/first/kconnect",
+		},
+		{
+			name: "default duration",
+			input: &AssumeRoleInput{
+				RoleARNs:            []string{"arn:aws:iam::123456789012:role/first"},
+				FromRoleCredentials: true,
+			},
+			expectDurations: []string{""},
+			expectMFATokens: []string{""},
+			expectARN:       "arn:aws:sts::123456789012:assumed-role/first/kconnect",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &stubSTS{}
+			server := httptest.NewServer(http.HandlerFunc(stub.handler))
+			t.Cleanup(server.Close)
+
+			cfg := aws.Config{
+				Region:       "eu-west-2",
+				Credentials:  credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+				BaseEndpoint: aws.String(server.URL),
+				HTTPClient:   server.Client(),
+			}
+
+			creds, err := AssumeRoleChain(context.Background(), cfg, tc.input)
+			if tc.expectErr != nil {
+				if !errors.Is(err, tc.expectErr) {
+					t.Fatalf("expected error %v but got %v", tc.expectErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if strings.Join(stub.roles, ",") != strings.Join(tc.input.RoleARNs, ",") {
+				t.Fatalf("expected roles %v to be assumed but got %v", tc.input.RoleARNs, stub.roles)
+			}
+
+			if strings.Join(stub.durations, ",") != strings.Join(tc.expectDurations, ",") {
+				t.Fatalf("expected durations %v but got %v", tc.expectDurations, stub.durations)
+			}
+
+			if strings.Join(stub.mfaTokens, ",") != strings.Join(tc.expectMFATokens, ",") {
+				t.Fatalf("expected mfa tokens %v but got %v", tc.expectMFATokens, stub.mfaTokens)
+			}
+
+			if creds.PrincipalARN != tc.expectARN {
+				t.Fatalf("expected principal %s but got %s", tc.expectARN, creds.PrincipalARN)
+			}
+
+			expectKey := fmt.Sprintf("ASIA%d", len(tc.input.RoleARNs))
+			if creds.AWSAccessKey != expectKey {
+				t.Fatalf("expected the credentials of the last role %s but got %s", expectKey, creds.AWSAccessKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ProfilePrefix is the prefix of the names of the profiles in the AWS credentials
+	// file that are managed by kconnect
+	ProfilePrefix = "kconnect-"
+
+	expiresKey      = "x_security_token_expires"
+	principalARNKey = "x_principal_arn"
+)
+
+// Profile represents a kconnect managed profile in the AWS credentials file
+type Profile struct {
+	Name         string
+	PrincipalARN string
+	Expires      time.Time
+}
+
+// IsExpired returns true if the credentials in the profile have expired. Profiles
+// without an expiry never expire.
+func (p *Profile) IsExpired() bool {
+	if p.Expires.IsZero() {
+		return false
+	}
+
+	return time.Now().After(p.Expires)
+}
+
+// IsManagedProfile returns true if the profile is managed by kconnect
+func IsManagedProfile(name string) bool {
+	return strings.HasPrefix(name, ProfilePrefix)
+}
+
+// ListProfiles returns the kconnect managed profiles in an AWS credentials file
+// sorted by name.
+func ListProfiles(filename string) ([]*Profile, error) {
+	cfg, err := LoadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", filename, err)
+	}
+
+	profiles := []*Profile{}
+
+	for _, section := range cfg.Sections() {
+		if !IsManagedProfile(section.Name()) {
+			continue
+		}
+
+		profile := &Profile{
+			Name:         section.Name(),
+			PrincipalARN: section.Key(principalARNKey).String(),
+		}
+
+		if section.HasKey(expiresKey) {
+			expires, err := section.Key(expiresKey).TimeFormat(time.RFC3339)
+			if err != nil {
+				return nil, fmt.Errorf("parsing expiry of profile %s: %w", profile.Name, err)
+			}
+
+			profile.Expires = expires
+		}
+
+		profiles = append(profiles, profile)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+
+	return profiles, nil
+}
+
+// DeleteProfiles removes profiles from an AWS credentials file. The other sections
+// and comments in the file are kept and the file is replaced atomically.
+func DeleteProfiles(filename string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	cfg, err := LoadFile(filename)
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", filename, err)
+	}
+
+	for _, name := range names {
+		cfg.DeleteSection(name)
+	}
+
+	return SaveFile(cfg, filename)
+}
+
+// SaveProfile writes the values to a profile in an AWS credentials file. The values
+// are mapped to keys using their ini struct tags and any other keys in the profile
+// are kept. The file is replaced atomically so that tools reading it at the same time,
+// such as the credential process, never see a partially written file.
+func SaveProfile(filename, profile string, values any) error {
+	cfg, err := LoadFile(filename)
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", filename, err)
+	}
+
+	section, err := cfg.NewSection(profile)
+	if err != nil {
+		return fmt.Errorf("creating profile %s: %w", profile, err)
+	}
+
+	if err := section.ReflectFrom(values); err != nil {
+		return fmt.Errorf("setting values of profile %s: %w", profile, err)
+	}
+
+	return SaveFile(cfg, filename)
+}
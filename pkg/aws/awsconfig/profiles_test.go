@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testCredentials struct {
+	AccessKey string    `ini:"aws_access_key_id"`
+	Expires   time.Time `ini:"x_security_token_expires"`
+}
+
+func Test_SaveProfile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "aws", "credentials")
+
+	if err := SaveProfile(filename, "default", &testCredentials{AccessKey: "DEFAULT"}); err != nil {
+		t.Fatalf("unexpected error creating file: %s", err)
+	}
+
+	if err := os.WriteFile(filename, []byte("# managed by hand\n[default]\naws_access_key_id = DEFAULT\nregion = eu-west-1\n\n[kconnect-dev]\naws_access_key_id = OLD\nregion = eu-west-2\n"), 0o600); err != nil {
+		t.Fatalf("writing credentials: %s", err)
+	}
+
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	if err := SaveProfile(filename, "kconnect-dev", &testCredentials{AccessKey: "NEW", Expires: expires}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("reading credentials: %s", err)
+	}
+
+	if !strings.Contains(string(data), "# managed by hand") {
+		t.Fatalf("expected comments to be kept but got:\n%s", data)
+	}
+
+	cfg, err := LoadFile(filename)
+	if err != nil {
+		t.Fatalf("loading credentials: %s", err)
+	}
+
+	expectValues := map[string]map[string]string{
+		"default": {
+			"aws_access_key_id": "DEFAULT",
+			"region":            "eu-west-1",
+		},
+		"kconnect-dev": {
+			"aws_access_key_id": "NEW",
+			"region":            "eu-west-2",
+		},
+	}
+
+	for section, keys := range expectValues {
+		for key, expect := range keys {
+			if value := cfg.Section(section).Key(key).String(); value != expect {
+				t.Fatalf("expected %s %s to be %q but got %q", section, key, expect, value)
+			}
+		}
+	}
+
+	profiles, err := ListProfiles(filename)
+	if err != nil {
+		t.Fatalf("listing profiles: %s", err)
+	}
+
+	if len(profiles) != 1 || !profiles[0].Expires.Equal(expires) {
+		t.Fatalf("expected kconnect-dev to expire at %s but got %v", expires, profiles)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsconfig
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"gopkg.in/ini.v1"
+)
+
+const (
+	credentialProcessKey = "credential_process"
+	defaultProfileName   = "default"
+	profilePrefix        = "profile "
+)
+
+// LocateSharedConfigFile returns the path of the AWS shared config file
+// (normally ~/.aws/config).
+func LocateSharedConfigFile() (string, error) {
+	filename := os.Getenv("AWS_CONFIG_FILE")
+
+	if filename != "" {
+		return filename, nil
+	}
+
+	var name string
+
+	var err error
+
+	if runtime.GOOS == "windows" {
+		name = path.Join(os.Getenv("USERPROFILE"), ".aws", "config")
+	} else {
+		name, err = homedir.Expand("~/.aws/config")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	name, err = resolveSymlink(name)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to resolve symlink")
+	}
+
+	return name, nil
+}
+
+// LoadFile loads an AWS ini file, an empty file is returned if it doesn't exist.
+// Like the AWS tools, # and ; are only comments at the start of a line so that
+// values such as SSO start urls are kept when the file is saved.
+func LoadFile(filename string) (*ini.File, error) {
+	return ini.LoadSources(ini.LoadOptions{Loose: true, IgnoreInlineComment: true}, filename)
+}
+
+// SaveFile writes an AWS ini file atomically. The file is written to a temporary
+// file in the same directory which then replaces the original.
+func SaveFile(cfg *ini.File, filename string) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("creating directory %s: %w", dir, err)
+	}
+
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name()) //nolint: errcheck
+
+	if _, err := cfg.WriteTo(tmpFile); err != nil {
+		tmpFile.Close() //nolint: errcheck
+		return fmt.Errorf("writing %s: %w", filename, err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("closing temporary file: %w", err)
+	}
+
+	if info, err := os.Stat(filename); err == nil {
+		if err := os.Chmod(tmpFile.Name(), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("setting file permissions: %w", err)
+		}
+	}
+
+	if err := os.Rename(tmpFile.Name(), filename); err != nil {
+		return fmt.Errorf("replacing %s: %w", filename, err)
+	}
+
+	return nil
+}
+
+// ConfigSectionName returns the name of the section in the AWS shared config
+// file for a profile.
+func ConfigSectionName(profile string) string {
+	if profile == defaultProfileName {
+		return profile
+	}
+
+	return profilePrefix + profile
+}
+
+// SetCredentialProcess will write a profile to the AWS shared config file that
+// sources its credentials from the supplied command. Any other settings in the
+// profile are kept.
+func SetCredentialProcess(filename, profile, command string) error {
+	cfg, err := LoadFile(filename)
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", filename, err)
+	}
+
+	section := cfg.Section(ConfigSectionName(profile))
+	section.Key(credentialProcessKey).SetValue(command)
+
+	return SaveFile(cfg, filename)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSharedConfig = `# profiles managed by hand
+[profile sso]
+; the start url contains a fragment
+sso_start_url = https://x.awsapps.com/start#/
+sso_region = eu-west-2
+role_session_name = bob;admin
+
+[default]
+region = eu-west-1
+`
+
+func Test_SetCredentialProcessRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(filename, []byte(testSharedConfig), 0o600); err != nil {
+		t.Fatalf("writing config: %s", err)
+	}
+
+	if err := SetCredentialProcess(filename, "kconnect-dev", "kconnect aws credential-process --entry-id 123"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("reading config: %s", err)
+	}
+
+	content := string(data)
+	for _, expect := range []string{
+		"# profiles managed by hand",
+		"; the start url contains a fragment",
+		"[profile kconnect-dev]",
+	} {
+		if !strings.Contains(content, expect) {
+			t.Fatalf("expected config to contain %q but got:\n%s", expect, content)
+		}
+	}
+
+	cfg, err := LoadFile(filename)
+	if err != nil {
+		t.Fatalf("loading config: %s", err)
+	}
+
+	expectValues := map[string]map[string]string{
+		"profile sso": {
+			"sso_start_url":     "https://x.awsapps.com/start#/",
+			"sso_region":        "eu-west-2",
+			"role_session_name": "bob;admin",
+		},
+		"default": {
+			"region": "eu-west-1",
+		},
+		"profile kconnect-dev": {
+			"credential_process": "kconnect aws credential-process --entry-id 123",
+		},
+	}
+
+	for section, keys := range expectValues {
+		for key, expect := range keys {
+			if value := cfg.Section(section).Key(key).String(); value != expect {
+				t.Fatalf("expected %s %s to be %q but got %q", section, key, expect, value)
+			}
+		}
+	}
+
+	if len(cfg.Section("profile sso").Keys()) != 3 {
+		t.Fatalf("expected the sso profile to have 3 keys but got %v", cfg.Section("profile sso").KeyStrings())
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("getting file info: %s", err)
+	}
+
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected permissions to be kept but got %s", info.Mode().Perm())
+	}
+}
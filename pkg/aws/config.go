@@ -27,6 +27,20 @@ const (
 	AccessKeyConfigItem    = "access-key"
 	SecretKeyConfigItem    = "secret-key"
 	SessionTokenConfigItem = "session-token"
+
+	AssumeRoleARNConfigItem        = "assume-role-arn"
+	AssumeRoleExternalIDConfigItem = "assume-role-external-id"
+	RoleSessionNameConfigItem      = "role-session-name"
+	RoleSessionDurationConfigItem  = "role-session-duration"
+	MFASerialConfigItem            = "mfa-serial"
+	MFATokenConfigItem             = "mfa-token"
+
+	CredentialSourceConfigItem     = "credential-source"
+	WebIdentityRoleARNConfigItem   = "web-identity-role-arn"
+	WebIdentityTokenFileConfigItem = "web-identity-token-file"
+	CredentialProcessConfigItem    = "credential-process"
+
+	PruneProfilesConfigItem = "prune-aws-profiles"
 )
 
 // SharedConfig will return shared configuration items for AWS based cluster and identity providers
@@ -37,6 +51,8 @@ func SharedConfig() config.ConfigurationSet {
 	cs.String("static-profile", "", "AWS profile to use. Only for advanced use cases") //nolint: errcheck
 	cs.SetHidden("static-profile")                                                     //nolint: errcheck
 
+	cs.Bool(PruneProfilesConfigItem, false, "Remove the expired and unused kconnect profiles from the AWS credentials file after connecting") //nolint: errcheck
+
 	return cs
 }
 
@@ -56,3 +72,23 @@ func AddIAMConfigs(cs config.ConfigurationSet) {
 	cs.String(SecretKeyConfigItem, "", "AWS secret key to use")       //nolint: errcheck
 	cs.String(SessionTokenConfigItem, "", "AWS session token to use") //nolint: errcheck
 }
+
+// AddAssumeRoleConfigs adds the config items used when assuming the roles in assume-role-arn
+func AddAssumeRoleConfigs(cs config.ConfigurationSet) {
+	cs.String(AssumeRoleExternalIDConfigItem, "", "Comma separated list of external ids for the roles in assume-role-arn, matched by position") //nolint: errcheck
+	cs.String(RoleSessionNameConfigItem, "", "The session name to use when assuming roles. Defaults to kconnect")                               //nolint: errcheck
+	cs.String(RoleSessionDurationConfigItem, "", "How long the assumed role credentials are valid for (e.g. 1h). Defaults to the role setting. Roles assumed using another role are limited to 1h") //nolint: errcheck
+	cs.String(MFASerialConfigItem, "", "The serial number or ARN of the MFA device to use when assuming the first role")                        //nolint: errcheck
+	cs.String(MFATokenConfigItem, "", "The MFA token code. You will be prompted for it if a MFA serial is supplied")                            //nolint: errcheck
+
+	cs.SetHistoryIgnore(MFATokenConfigItem) //nolint: errcheck
+}
+
+// AddCredentialSourceConfigs adds the config items used to choose where the AWS credentials come from
+func AddCredentialSourceConfigs(cs config.ConfigurationSet) {
+	cs.String(CredentialSourceConfigItem, "", "Where to get the AWS credentials from. Possible values: default,profile,static,web-identity,process,container,imds. Worked out from the other flags if not supplied") //nolint: errcheck
+
+	cs.String(WebIdentityRoleARNConfigItem, "", "ARN of the role to assume with the web identity token")                       //nolint: errcheck
+	cs.String(WebIdentityTokenFileConfigItem, "", "Path to the web identity token file (e.g. an OIDC token from CI)")          //nolint: errcheck
+	cs.String(CredentialProcessConfigItem, "", "Command to run that returns the AWS credentials in credential_process format") //nolint: errcheck
+}
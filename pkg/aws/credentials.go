@@ -0,0 +1,217 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/endpointcreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/processcreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// CredentialSource is where the credentials for the aws-iam identity come from
+type CredentialSource string
+
+var (
+	// CredentialSourceDefault uses the default AWS credential chain
+	CredentialSourceDefault = CredentialSource("default")
+	// CredentialSourceProfile uses a profile from the shared config files. This includes
+	// profiles that use SSO or credential_process.
+	CredentialSourceProfile = CredentialSource("profile")
+	// CredentialSourceStatic uses the supplied access key, secret key and session token
+	CredentialSourceStatic = CredentialSource("static")
+	// CredentialSourceWebIdentity assumes a role using a web identity token file
+	CredentialSourceWebIdentity = CredentialSource("web-identity")
+	// CredentialSourceProcess runs a command that returns the credentials
+	CredentialSourceProcess = CredentialSource("process")
+	// CredentialSourceContainer uses the ECS/EKS container credentials endpoint
+	CredentialSourceContainer = CredentialSource("container")
+	// CredentialSourceIMDS uses the EC2 instance metadata service
+	CredentialSourceIMDS = CredentialSource("imds")
+
+	// CredentialSources is the list of supported credential sources
+	CredentialSources = []CredentialSource{
+		CredentialSourceDefault,
+		CredentialSourceProfile,
+		CredentialSourceStatic,
+		CredentialSourceWebIdentity,
+		CredentialSourceProcess,
+		CredentialSourceContainer,
+		CredentialSourceIMDS,
+	}
+)
+
+const (
+	containerCredentialsHost = "http://169.254.170.2"
+
+	envContainerFullURI       = "AWS_CONTAINER_CREDENTIALS_FULL_URI"
+	envContainerRelativeURI   = "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"
+	envContainerAuthToken     = "AWS_CONTAINER_AUTHORIZATION_TOKEN"
+	envContainerAuthTokenFile = "AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"
+)
+
+// CredentialsConfig is the configuration used to get the AWS credentials
+type CredentialsConfig struct {
+	Source                   CredentialSource
+	Region                   string
+	Profile                  string
+	AccessKey                string
+	SecretKey                string
+	SessionToken             string
+	AWSSharedCredentialsFile string
+	WebIdentityRoleARN       string
+	WebIdentityTokenFile     string
+	CredentialProcess        string
+	RoleSessionName          string
+}
+
+// ResolveSource returns the credential source to use. If a source hasn't been supplied then it's
+// worked out from the other configuration.
+func (c *CredentialsConfig) ResolveSource() CredentialSource {
+	switch {
+	case c.Source != "":
+		return c.Source
+	case c.Profile != "":
+		return CredentialSourceProfile
+	case c.AccessKey != "":
+		return CredentialSourceStatic
+	case c.WebIdentityRoleARN != "" && c.WebIdentityTokenFile != "":
+		return CredentialSourceWebIdentity
+	case c.CredentialProcess != "":
+		return CredentialSourceProcess
+	default:
+		return CredentialSourceDefault
+	}
+}
+
+// NewSessionFromCredentials will create a new AWS session using the credential source. The
+// resolved source is returned so that it can be recorded.
+func NewSessionFromCredentials(ctx context.Context, credsCfg *CredentialsConfig) (*aws.Config, CredentialSource, error) {
+	source := credsCfg.ResolveSource()
+
+	switch source {
+	case CredentialSourceDefault, CredentialSourceProfile, CredentialSourceStatic:
+		sess, err := NewSession(credsCfg.Region, credsCfg.Profile, credsCfg.AccessKey, credsCfg.SecretKey, credsCfg.SessionToken, credsCfg.AWSSharedCredentialsFile)
+
+		return sess, source, err
+	}
+
+	provider, err := credentialsProvider(ctx, source, credsCfg)
+	if err != nil {
+		return nil, source, err
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx,
+		config.WithRegion(credsCfg.Region),
+		config.WithCredentialsProvider(aws.NewCredentialsCache(provider)),
+	)
+	if err != nil {
+		return nil, source, fmt.Errorf("creating new aws session in region %s using %s credentials: %w", credsCfg.Region, source, err)
+	}
+
+	return &awsCfg, source, nil
+}
+
+func credentialsProvider(ctx context.Context, source CredentialSource, credsCfg *CredentialsConfig) (aws.CredentialsProvider, error) {
+	switch source {
+	case CredentialSourceWebIdentity:
+		if credsCfg.WebIdentityRoleARN == "" || credsCfg.WebIdentityTokenFile == "" {
+			return nil, ErrWebIdentityConfig
+		}
+
+		// The web identity token is used to authenticate so the sts client has no credentials
+		stsCfg, err := config.LoadDefaultConfig(ctx,
+			config.WithRegion(credsCfg.Region),
+			config.WithCredentialsProvider(aws.AnonymousCredentials{}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("creating sts client: %w", err)
+		}
+
+		sessionName := credsCfg.RoleSessionName
+		if sessionName == "" {
+			sessionName = DefaultRoleSessionName
+		}
+
+		return stscreds.NewWebIdentityRoleProvider(
+			sts.NewFromConfig(stsCfg),
+			credsCfg.WebIdentityRoleARN,
+			stscreds.IdentityTokenFile(credsCfg.WebIdentityTokenFile),
+			func(o *stscreds.WebIdentityRoleOptions) {
+				o.RoleSessionName = sessionName
+			},
+		), nil
+	case CredentialSourceProcess:
+		if credsCfg.CredentialProcess == "" {
+			return nil, ErrCredentialProcessRequired
+		}
+
+		return processcreds.NewProvider(credsCfg.CredentialProcess), nil
+	case CredentialSourceContainer:
+		return containerCredentialsProvider()
+	case CredentialSourceIMDS:
+		return ec2rolecreds.New(), nil
+	default:
+		return nil, fmt.Errorf("creating credentials provider for %s: %w", source, ErrUnknownCredentialSource)
+	}
+}
+
+// containerCredentialsProvider returns a provider for the container credentials endpoint using
+// the environment variables that ECS and EKS Pod Identity set
+func containerCredentialsProvider() (aws.CredentialsProvider, error) {
+	endpoint := os.Getenv(envContainerFullURI)
+	if relativeURI := os.Getenv(envContainerRelativeURI); relativeURI != "" {
+		endpoint = containerCredentialsHost + relativeURI
+	}
+
+	if endpoint == "" {
+		return nil, ErrNoContainerCredentials
+	}
+
+	authToken := os.Getenv(envContainerAuthToken)
+	if tokenFile := os.Getenv(envContainerAuthTokenFile); tokenFile != "" {
+		data, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading container authorization token: %w", err)
+		}
+
+		authToken = strings.TrimSpace(string(data))
+	}
+
+	return endpointcreds.New(endpoint, func(o *endpointcreds.Options) {
+		o.AuthorizationToken = authToken
+	}), nil
+}
+
+// GetCallerARN returns the ARN of the principal that the credentials belong to
+func GetCallerARN(ctx context.Context, cfg aws.Config) (string, error) {
+	out, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf("getting caller identity: %w", err)
+	}
+
+	return aws.ToString(out.Arn), nil
+}
@@ -23,4 +23,10 @@ var (
 	ErrUnexpectedIdentity  = errors.New("unexpected identity type")
 	ErrNoPartitionSupplied = errors.New("no AWS partition supplied")
 	ErrPartitionNotFound   = errors.New("AWS partition not found")
+	ErrNoRoleToAssume      = errors.New("no role to assume")
+
+	ErrUnknownCredentialSource   = errors.New("unknown credential source")
+	ErrWebIdentityConfig         = errors.New("web-identity-role-arn and web-identity-token-file are required for web identity credentials")
+	ErrCredentialProcessRequired = errors.New("credential-process is required for process credentials")
+	ErrNoContainerCredentials    = errors.New("container credentials endpoint environment variables not set")
 )
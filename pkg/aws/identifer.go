@@ -22,6 +22,8 @@ import (
 	"hash/fnv"
 
 	"github.com/versent/saml2aws/v2/pkg/awsconfig"
+
+	kawsconfig "github.com/fidelity/kconnect/pkg/aws/awsconfig"
 )
 
 var (
@@ -42,3 +44,9 @@ func CreateIDFromCreds(creds *awsconfig.AWSCredentials) (string, error) {
 
 	return fmt.Sprintf("%d", h.Sum32()), nil
 }
+
+// ProfileName returns the name of the kconnect managed profile in the AWS credentials
+// file for an identifier created by CreateIDFromCreds
+func ProfileName(identifier string) string {
+	return kawsconfig.ProfilePrefix + identifier
+}
@@ -55,9 +55,23 @@ func (s *awsIdentityStore) Save(userID identity.Identity) error {
 		return fmt.Errorf("expected AWSIdentity but got a %T: %w", userID, ErrUnexpectedIdentity)
 	}
 
+	filename := s.configProvider.Filename
+	if filename == "" {
+		path, err := kawsconfig.LocateConfigFile()
+		if err != nil {
+			return fmt.Errorf("locating aws credentials file: %w", err)
+		}
+
+		filename = path
+	}
+
 	awsCreds := MapIdentityToCreds(awsIdentity)
 
-	return s.configProvider.Save(awsCreds)
+	if err := kawsconfig.SaveProfile(filename, s.configProvider.Profile, awsCreds); err != nil {
+		return fmt.Errorf("saving aws credentials: %w", err)
+	}
+
+	return nil
 }
 
 func (s *awsIdentityStore) Load() (identity.Identity, error) {
@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+const (
+	tokenPrefix         = "k8s-aws-v1."
+	clusterIDHeader     = "x-k8s-aws-id"
+	presignExpiryHeader = "X-Amz-Expires"
+	presignExpirySecs   = "60"
+
+	// TokenLifetime is how long the EKS authentication tokens can be used for. The tokens
+	// are valid for 15 minutes but are refreshed 1 minute early.
+	TokenLifetime = 14 * time.Minute
+)
+
+// EKSToken is a bearer token used to authenticate with an EKS cluster
+type EKSToken struct {
+	Token      string
+	Expiration time.Time
+}
+
+// GetEKSToken creates a token to authenticate with an EKS cluster. This is the same token
+// that aws-iam-authenticator and aws eks get-token create, a presigned STS GetCallerIdentity
+// request for the cluster.
+func GetEKSToken(ctx context.Context, cfg aws.Config, clusterName string) (*EKSToken, error) {
+	presignClient := sts.NewPresignClient(sts.NewFromConfig(cfg))
+
+	request, err := presignClient.PresignGetCallerIdentity(ctx, &sts.GetCallerIdentityInput{}, func(opts *sts.PresignOptions) {
+		opts.ClientOptions = append(opts.ClientOptions, func(o *sts.Options) {
+			o.APIOptions = append(o.APIOptions,
+				smithyhttp.SetHeaderValue(clusterIDHeader, clusterName),
+				smithyhttp.SetHeaderValue(presignExpiryHeader, presignExpirySecs),
+			)
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("presigning get caller identity request: %w", err)
+	}
+
+	return &EKSToken{
+		Token:      tokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(request.URL)),
+		Expiration: time.Now().Add(TokenLifetime),
+	}, nil
+}
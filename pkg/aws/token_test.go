@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+func Test_GetEKSToken(t *testing.T) {
+	cfg := aws.Config{
+		Region:      "eu-west-2",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+	}
+
+	token, err := GetEKSToken(context.Background(), cfg, "dev")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(token.Token, tokenPrefix) {
+		t.Fatalf("expected token to start with %s but got %s", tokenPrefix, token.Token)
+	}
+
+	presignedURL, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token.Token, tokenPrefix))
+	if err != nil {
+		t.Fatalf("decoding token: %s", err)
+	}
+
+	parsed, err := url.Parse(string(presignedURL))
+	if err != nil {
+		t.Fatalf("parsing presigned url: %s", err)
+	}
+
+	if parsed.Host != "sts.eu-west-2.amazonaws.com" {
+		t.Fatalf("expected regional sts endpoint but got %s", parsed.Host)
+	}
+
+	query := parsed.Query()
+
+	if action := query.Get("Action"); action != "GetCallerIdentity" {
+		t.Fatalf("expected GetCallerIdentity action but got %s", action)
+	}
+
+	if expires := query.Get(presignExpiryHeader); expires != presignExpirySecs {
+		t.Fatalf("expected expiry of %s but got %s", presignExpirySecs, expires)
+	}
+
+	if signed := query.Get("X-Amz-SignedHeaders"); !strings.Contains(signed, clusterIDHeader) {
+		t.Fatalf("expected %s to be signed but got %s", clusterIDHeader, signed)
+	}
+
+	if remaining := time.Until(token.Expiration); remaining <= 0 || remaining > TokenLifetime {
+		t.Fatalf("expected token to expire within %s but got %s", TokenLifetime, remaining)
+	}
+}
@@ -185,12 +185,100 @@ func (c *AzureADClient) GetOauth2TokenFromUsernamePassword(cfg *AuthenticationCo
 		"client_info": "1",
 	}
 
+	return c.requestToken(cfg.Endpoints.TokenEndpoint, params)
+}
+
+// GetDeviceCode will start the device authorization flow for the resource
+func (c *AzureADClient) GetDeviceCode(cfg *AuthenticationConfig, resource string) (*DeviceCodeResponse, error) {
+	params := map[string]string{
+		"client_id": cfg.ClientID,
+	}
+	setResourceParams(params, cfg.Endpoints.DeviceCodeEndpoint, resource)
+
+	resp, err := c.postForm(cfg.Endpoints.DeviceCodeEndpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("getting device code: %w", err)
+	}
+
+	deviceCode := &DeviceCodeResponse{}
+	if err := json.Unmarshal([]byte(resp.Body()), deviceCode); err != nil {
+		return nil, fmt.Errorf("unmarshalling device code response: %w", err)
+	}
+
+	if deviceCode.VerificationURI == "" {
+		deviceCode.VerificationURI = deviceCode.VerificationURL
+	}
+
+	return deviceCode, nil
+}
+
+// GetOauth2TokenFromDeviceCode will get a token once the user has completed the device authorization
+// flow. An OIDCErrorResponse with an error of authorization_pending is returned until they have.
+func (c *AzureADClient) GetOauth2TokenFromDeviceCode(cfg *AuthenticationConfig, resource string, deviceCode string) (*OauthToken, error) {
+	params := map[string]string{
+		"grant_type": "urn:ietf:params:oauth:grant-type:device_code",
+		"client_id":  cfg.ClientID,
+	}
+	setResourceParams(params, cfg.Endpoints.TokenEndpoint, resource)
+
+	if isV2Endpoint(cfg.Endpoints.TokenEndpoint) {
+		params["device_code"] = deviceCode
+	} else {
+		params["code"] = deviceCode
+	}
+
+	return c.requestToken(cfg.Endpoints.TokenEndpoint, params)
+}
+
+// GetOauth2TokenFromAuthCode will exchange an authorization code for a token
+func (c *AzureADClient) GetOauth2TokenFromAuthCode(cfg *AuthenticationConfig, resource string, code string, redirectURI string, codeVerifier string) (*OauthToken, error) {
+	params := map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     cfg.ClientID,
+		"code":          code,
+		"redirect_uri":  redirectURI,
+		"code_verifier": codeVerifier,
+	}
+	setResourceParams(params, cfg.Endpoints.TokenEndpoint, resource)
+
+	return c.requestToken(cfg.Endpoints.TokenEndpoint, params)
+}
+
+// GetOauth2TokenFromRefreshToken will use a refresh token to get a token for the resource
+func (c *AzureADClient) GetOauth2TokenFromRefreshToken(cfg *AuthenticationConfig, resource string, refreshToken string) (*OauthToken, error) {
+	params := map[string]string{
+		"grant_type":    "refresh_token",
+		"client_id":     cfg.ClientID,
+		"refresh_token": refreshToken,
+	}
+	setResourceParams(params, cfg.Endpoints.TokenEndpoint, resource)
+
+	return c.requestToken(cfg.Endpoints.TokenEndpoint, params)
+}
+
+// requestToken will post the params to the token endpoint. If the request fails the
+// OIDCErrorResponse from Azure AD is returned as the error.
+func (c *AzureADClient) requestToken(tokenEndpoint string, params map[string]string) (*OauthToken, error) {
+	resp, err := c.postForm(tokenEndpoint, params)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &OauthToken{}
+	if err := json.Unmarshal([]byte(resp.Body()), token); err != nil {
+		return nil, fmt.Errorf("unmarshalling oauth token: %w", err)
+	}
+
+	return token, nil
+}
+
+func (c *AzureADClient) postForm(endpoint string, params map[string]string) (khttp.ClientResponse, error) {
 	headers := make(map[string]string)
 	headers["Content-Type"] = "application/x-www-form-urlencoded; charset=utf-8"
 
 	body := c.encodeQueryParams(params)
 
-	resp, err := c.httpClient.Post(cfg.Endpoints.TokenEndpoint, body, headers)
+	resp, err := c.httpClient.Post(endpoint, body, headers)
 	if err != nil {
 		return nil, err
 	}
@@ -204,12 +292,26 @@ func (c *AzureADClient) GetOauth2TokenFromUsernamePassword(cfg *AuthenticationCo
 		return nil, oidcResp
 	}
 
-	token := &OauthToken{}
-	if err := json.Unmarshal([]byte(resp.Body()), token); err != nil {
-		return nil, fmt.Errorf("unmarshalling oauth token: %w", err)
+	return resp, nil
+}
+
+// setResourceParams will set the resource for the request. The v2.0 endpoints returned by
+// the OIDC endpoints resolver use scopes instead of resources.
+func setResourceParams(params map[string]string, endpoint, resource string) {
+	if isV2Endpoint(endpoint) {
+		params["scope"] = resourceScope(resource)
+		return
 	}
 
-	return token, nil
+	params["resource"] = resource
+}
+
+func isV2Endpoint(endpoint string) bool {
+	return strings.Contains(endpoint, "/v2.0/")
+}
+
+func resourceScope(resource string) string {
+	return fmt.Sprintf("%s/.default offline_access openid profile", resource)
 }
 
 func (c *AzureADClient) createEnvelope(cfg *AuthenticationConfig, endpoint *wstrust.Endpoint) (string, error) {
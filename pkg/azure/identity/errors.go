@@ -26,4 +26,11 @@ var (
 	ErrUnknownAccountType            = errors.New("unknown account type")
 	ErrOIDCResponse                  = errors.New("oidc error")
 	ErrResourceRequired              = errors.New("you must supply a resource")
+	ErrInteractiveLoginTimeout       = errors.New("timed out waiting for the interactive login")
+	ErrInteractiveLoginDeclined      = errors.New("the user declined the interactive login")
+	ErrInvalidLoginState             = errors.New("invalid state returned from the interactive login")
+	ErrUnknownLoginMode              = errors.New("unknown aad login mode")
+	ErrInvalidTokenCache             = errors.New("the token cache is invalid")
+	ErrNoCachedToken                 = errors.New("no cached token")
+	ErrNotActiveDirectoryIdentity    = errors.New("not an active directory identity")
 )
@@ -17,9 +17,21 @@ limitations under the License.
 package identity
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
 	"github.com/Azure/go-autorest/autorest"
+	"go.uber.org/zap"
 
 	khttp "github.com/fidelity/kconnect/pkg/http"
+	"github.com/fidelity/kconnect/pkg/utils"
 )
 
 type AuthorizerIdentity struct {
@@ -64,17 +76,35 @@ type ActiveDirectoryIdentity struct {
 	idProviderName           string
 	httpClient               khttp.Client
 	interactiveLoginRequired bool
+
+	loginMode    LoginMode
+	openURL      func(url string) error
+	output       io.Writer
+	refreshToken string
+
+	tokenCache *TokenCache
+	tokens     map[string]*CachedToken
 }
 
-func NewActiveDirectoryIdentity(authCfg *AuthenticationConfig, userRealm *UserRealm, idProviderName string, httpClient khttp.Client, interactiveLoginRequired bool) *ActiveDirectoryIdentity {
-	return &ActiveDirectoryIdentity{
+func NewActiveDirectoryIdentity(authCfg *AuthenticationConfig, userRealm *UserRealm, idProviderName string, httpClient khttp.Client, interactiveLoginRequired bool, opts ...CloneOption) *ActiveDirectoryIdentity {
+	id := &ActiveDirectoryIdentity{
 		//authorizer:     authorizer,
 		idProviderName:           idProviderName,
 		realm:                    userRealm,
 		authCfg:                  authCfg,
 		httpClient:               httpClient,
 		interactiveLoginRequired: interactiveLoginRequired,
+		loginMode:                LoginModePassword,
+		openURL:                  openURL,
+		output:                   os.Stderr,
+		tokens:                   map[string]*CachedToken{},
+	}
+
+	for _, opt := range opts {
+		opt(id)
 	}
+
+	return id
 }
 
 func (a *ActiveDirectoryIdentity) Type() string {
@@ -85,25 +115,60 @@ func (a *ActiveDirectoryIdentity) Name() string {
 	return a.authCfg.Username
 }
 
+// IsExpired returns true if the identity has tokens but none of them can be used or refreshed
 func (a *ActiveDirectoryIdentity) IsExpired() bool {
-	//TODO: how do we do this properly
-	return false
+	if len(a.tokens) == 0 {
+		return false
+	}
+
+	for _, cached := range a.tokens {
+		if cached.IsValid() || cached.CanRefresh() {
+			return false
+		}
+	}
+
+	return true
 }
 
 func (a *ActiveDirectoryIdentity) IdentityProviderName() string {
 	return a.idProviderName
 }
 
+// GetOAuthToken will get a token for the resource. If the identity has a token cache then a cached
+// token is used, or refreshed silently, before the user is logged in again.
 func (a *ActiveDirectoryIdentity) GetOAuthToken(resource string) (*OauthToken, error) {
 	if len(resource) == 0 {
 		return nil, ErrResourceRequired
 	}
 
+	identityClient := NewClient(a.httpClient)
+
+	if token := a.getCachedToken(identityClient, resource); token != nil {
+		return token, nil
+	}
+
+	token, err := a.getOAuthToken(identityClient, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	a.cacheToken(resource, token, a.refreshToken)
+
+	return token, nil
+}
+
+func (a *ActiveDirectoryIdentity) getOAuthToken(identityClient Client, resource string) (*OauthToken, error) {
 	var token *OauthToken
 
 	var err error
 
-	identityClient := NewClient(a.httpClient)
+	switch {
+	case a.loginMode == LoginModeDeviceCode || a.loginMode == LoginModeBrowser:
+		return a.getInteractiveToken(identityClient, a.loginMode, resource)
+	case a.refreshToken != "":
+		// A previous password login fell back to an interactive login
+		return a.getInteractiveToken(identityClient, LoginModeDeviceCode, resource)
+	}
 
 	switch a.realm.AccountType {
 	case AccountTypeFederated:
@@ -131,6 +196,10 @@ func (a *ActiveDirectoryIdentity) GetOAuthToken(resource string) (*OauthToken, e
 			token, err = identityClient.GetOauth2TokenFromUsernamePassword(a.authCfg, resource)
 		}
 
+		if isInteractionRequired(err) {
+			return a.getInteractiveToken(identityClient, LoginModeDeviceCode, resource)
+		}
+
 		if err != nil {
 			return nil, err //TODO: specific error
 		}
@@ -142,6 +211,10 @@ func (a *ActiveDirectoryIdentity) GetOAuthToken(resource string) (*OauthToken, e
 				token, err = identityClient.GetOauth2TokenFromUsernamePassword(a.authCfg, resource)
 			}
 
+			if isInteractionRequired(err) {
+				return a.getInteractiveToken(identityClient, LoginModeDeviceCode, resource)
+			}
+
 			if err != nil {
 				return nil, err //TODO: specific error
 			}
@@ -155,6 +228,193 @@ func (a *ActiveDirectoryIdentity) GetOAuthToken(resource string) (*OauthToken, e
 	return token, nil
 }
 
+// getInteractiveToken will get a token for the resource using the refresh token from a previous
+// interactive login. If there isn't a refresh token, or it can't be used, the user will login
+// using the login mode.
+func (a *ActiveDirectoryIdentity) getInteractiveToken(identityClient Client, mode LoginMode, resource string) (*OauthToken, error) {
+	if a.refreshToken != "" {
+		token, err := identityClient.GetOauth2TokenFromRefreshToken(a.authCfg, resource, a.refreshToken)
+		if err == nil {
+			a.setRefreshToken(token)
+
+			return token, nil
+		}
+
+		zap.S().Debugw("failed using refresh token, logging in interactively", "error", err.Error())
+	}
+
+	input := &InteractiveLoginInput{
+		Client:   identityClient,
+		AuthCfg:  a.authCfg,
+		Resource: resource,
+		OpenURL:  a.openURL,
+		Output:   a.output,
+		Timeout:  DefaultInteractiveLoginTimeout,
+	}
+
+	var token *OauthToken
+
+	var err error
+
+	switch mode {
+	case LoginModeDeviceCode:
+		zap.S().Info("logging in to Azure AD using a device code")
+		token, err = DeviceCodeLogin(context.Background(), input)
+	case LoginModeBrowser:
+		zap.S().Info("logging in to Azure AD using the browser")
+		token, err = BrowserLogin(context.Background(), input)
+	default:
+		return nil, fmt.Errorf("logging in with mode %s: %w", mode, ErrUnknownLoginMode)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("logging in to azure ad: %w", err)
+	}
+
+	a.setRefreshToken(token)
+
+	return token, nil
+}
+
+// getCachedToken returns the token for the resource from the token cache. A cached token that is
+// close to expiring is refreshed using the refresh token. Nil is returned if there isn't a cached
+// token that can be used.
+func (a *ActiveDirectoryIdentity) getCachedToken(identityClient Client, resource string) *OauthToken {
+	if a.tokenCache == nil || a.authCfg.Endpoints == nil {
+		return nil
+	}
+
+	tenant := a.authCfg.Authority.Tenant
+
+	cached, err := a.tokenCache.Find(tenant, a.authCfg.ClientID, a.authCfg.Username, resource)
+	if err != nil {
+		zap.S().Warnw("failed reading azure ad token cache", "error", err.Error())
+		return nil
+	}
+
+	if cached != nil && cached.IsValid() {
+		zap.S().Debugw("using cached azure ad token", "resource", resource)
+		a.tokens[resource] = cached
+
+		return cached.OauthToken()
+	}
+
+	// Any refresh token for the tenant and client can be used to get a token for the resource
+	if cached == nil || !cached.CanRefresh() {
+		cached, err = a.tokenCache.FindRefreshable(tenant, a.authCfg.ClientID, a.authCfg.Username)
+		if err != nil || cached == nil {
+			return nil
+		}
+	}
+
+	zap.S().Debugw("refreshing cached azure ad token", "resource", resource)
+
+	token, err := identityClient.GetOauth2TokenFromRefreshToken(a.authCfg, resource, cached.RefreshToken)
+	if err != nil {
+		zap.S().Debugw("failed refreshing cached azure ad token", "error", err.Error())
+		return nil
+	}
+
+	a.cacheToken(resource, token, cached.RefreshToken)
+
+	return token
+}
+
+// cacheToken will save the token in the token cache. Azure AD doesn't always return a new refresh
+// token so the refresh token that was used to get the token is kept.
+func (a *ActiveDirectoryIdentity) cacheToken(resource string, token *OauthToken, refreshToken string) {
+	if a.tokenCache == nil {
+		return
+	}
+
+	if token.RefreshToken != "" {
+		refreshToken = token.RefreshToken
+	}
+
+	if a.authCfg.Username == "" {
+		a.authCfg.Username = usernameFromIDToken(token.IDToken)
+	}
+
+	cached := &CachedToken{
+		Tenant:       a.authCfg.Authority.Tenant,
+		ClientID:     a.authCfg.ClientID,
+		Resource:     resource,
+		Username:     a.authCfg.Username,
+		LoginMode:    a.loginMode,
+		AADHost:      a.authCfg.Authority.Host,
+		AuthorityURI: a.authCfg.Authority.AuthorityURI,
+		AccessToken:  token.AccessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt(token),
+		RefreshedAt:  time.Now(),
+	}
+	if a.authCfg.Endpoints != nil {
+		cached.Endpoints = *a.authCfg.Endpoints
+	}
+
+	a.tokens[resource] = cached
+
+	if err := a.tokenCache.Put(cached); err != nil {
+		zap.S().Warnw("failed saving azure ad token to the cache", "error", err.Error())
+	}
+}
+
+func (a *ActiveDirectoryIdentity) setRefreshToken(token *OauthToken) {
+	if token.RefreshToken != "" {
+		a.refreshToken = token.RefreshToken
+	}
+
+	if a.authCfg.Username == "" {
+		a.authCfg.Username = usernameFromIDToken(token.IDToken)
+	}
+}
+
+func isInteractionRequired(err error) bool {
+	oidcErr := &OIDCErrorResponse{}
+
+	return errors.As(err, &oidcErr) && oidcErr.IsInteractionRequired()
+}
+
+// usernameFromIDToken returns the username from the claims of the id token. The token
+// has come directly from Azure AD so its signature isn't verified.
+func usernameFromIDToken(idToken string) string {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 { //nolint: gomnd
+		return ""
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+
+	claims := struct {
+		PreferredUsername string `json:"preferred_username"`
+		UPN               string `json:"upn"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+
+	if claims.PreferredUsername != "" {
+		return claims.PreferredUsername
+	}
+
+	return claims.UPN
+}
+
+// openURL will display the login url and try to open it in the browser. Failing to open the
+// browser isn't an error as the user can open the url themselves.
+func openURL(url string) error {
+	fmt.Fprintf(os.Stderr, "Login to Azure AD using the following url:\n\n%s\n\n", url)
+
+	if err := utils.OpenBrowser(url); err != nil {
+		zap.S().Debugw("failed opening browser", "error", err.Error())
+	}
+
+	return nil
+}
+
 func (a *ActiveDirectoryIdentity) Clone(opts ...CloneOption) *ActiveDirectoryIdentity {
 	copyID := &ActiveDirectoryIdentity{
 		authCfg: &AuthenticationConfig{
@@ -179,6 +439,15 @@ func (a *ActiveDirectoryIdentity) Clone(opts ...CloneOption) *ActiveDirectoryIde
 		idProviderName:           a.idProviderName,
 		httpClient:               a.httpClient,
 		interactiveLoginRequired: a.interactiveLoginRequired,
+		loginMode:                a.loginMode,
+		openURL:                  a.openURL,
+		output:                   a.output,
+		refreshToken:             a.refreshToken,
+		tokenCache:               a.tokenCache,
+		tokens:                   map[string]*CachedToken{},
+	}
+	for resource, cached := range a.tokens {
+		copyID.tokens[resource] = cached
 	}
 	if a.authCfg.Endpoints != nil {
 		copyID.authCfg.Endpoints = &Endpoints{
@@ -203,3 +472,52 @@ func WithClientID(clientID string) CloneOption {
 		a.authCfg.ClientID = clientID
 	}
 }
+
+// WithTenant changes the tenant that tokens are requested from. A refresh token from the
+// original tenant is kept so that the user doesn't need to login again.
+func WithTenant(tenantID string) CloneOption {
+	return func(a *ActiveDirectoryIdentity) {
+		oldTenant := "/" + a.authCfg.Authority.Tenant + "/"
+		newTenant := "/" + tenantID + "/"
+
+		a.authCfg.Authority.Tenant = tenantID
+		a.authCfg.Authority.AuthorityURI = strings.Replace(a.authCfg.Authority.AuthorityURI, oldTenant, newTenant, 1)
+
+		if a.authCfg.Endpoints != nil {
+			a.authCfg.Endpoints.AuthorizationEndpoint = strings.Replace(a.authCfg.Endpoints.AuthorizationEndpoint, oldTenant, newTenant, 1)
+			a.authCfg.Endpoints.TokenEndpoint = strings.Replace(a.authCfg.Endpoints.TokenEndpoint, oldTenant, newTenant, 1)
+			a.authCfg.Endpoints.DeviceCodeEndpoint = strings.Replace(a.authCfg.Endpoints.DeviceCodeEndpoint, oldTenant, newTenant, 1)
+		}
+
+		for _, cached := range a.tokens {
+			if a.refreshToken == "" && cached.CanRefresh() {
+				a.refreshToken = cached.RefreshToken
+			}
+		}
+
+		a.tokens = map[string]*CachedToken{}
+	}
+}
+
+// WithLoginMode sets how the user logs in to Azure AD
+func WithLoginMode(mode LoginMode) CloneOption {
+	return func(a *ActiveDirectoryIdentity) {
+		a.loginMode = mode
+	}
+}
+
+// WithTokenCache sets the cache that the tokens are saved in so they can be reused
+func WithTokenCache(cache *TokenCache) CloneOption {
+	return func(a *ActiveDirectoryIdentity) {
+		a.tokenCache = cache
+	}
+}
+
+// WithInteractiveIO sets the function used to open the browser login url and where the
+// device code instructions are written
+func WithInteractiveIO(openURL func(url string) error, output io.Writer) CloneOption {
+	return func(a *ActiveDirectoryIdentity) {
+		a.openURL = openURL
+		a.output = output
+	}
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package identity
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultInteractiveLoginTimeout is how long to wait for the user to login interactively
+	DefaultInteractiveLoginTimeout = 5 * time.Minute
+
+	slowDownInterval = 5 * time.Second
+)
+
+var (
+	// deviceCodePollInterval is used when Azure AD doesn't return a polling interval
+	deviceCodePollInterval = 5 * time.Second
+)
+
+// InteractiveLoginInput is the input for the interactive login flows
+type InteractiveLoginInput struct {
+	Client   Client
+	AuthCfg  *AuthenticationConfig
+	Resource string
+
+	// OpenURL is called with the url that the user must login with
+	OpenURL func(url string) error
+	// Output is where the device code instructions are written
+	Output  io.Writer
+	Timeout time.Duration
+}
+
+// DeviceCodeLogin will login using the OAuth device authorization flow. The user is asked to
+// enter a code at the verification url and the token endpoint is polled until they have.
+func DeviceCodeLogin(ctx context.Context, input *InteractiveLoginInput) (*OauthToken, error) {
+	deviceCode, err := input.Client.GetDeviceCode(input.AuthCfg, input.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	message := deviceCode.Message
+	if message == "" {
+		message = fmt.Sprintf("To sign in, use a web browser to open the page %s and enter the code %s to authenticate.", deviceCode.VerificationURI, deviceCode.UserCode)
+	}
+
+	fmt.Fprintf(input.Output, "%s\n", message)
+
+	timeout := loginTimeout(input)
+	if expiresIn, err := deviceCode.ExpiresIn.Int64(); err == nil && expiresIn > 0 {
+		timeout = time.Duration(expiresIn) * time.Second
+	}
+
+	interval := deviceCodePollInterval
+	if seconds, err := deviceCode.Interval.Int64(); err == nil && seconds > 0 {
+		interval = time.Duration(seconds) * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ErrInteractiveLoginTimeout
+		case <-time.After(interval):
+		}
+
+		token, err := input.Client.GetOauth2TokenFromDeviceCode(input.AuthCfg, input.Resource, deviceCode.DeviceCode)
+		if err == nil {
+			return token, nil
+		}
+
+		oidcErr := &OIDCErrorResponse{}
+		if !errors.As(err, &oidcErr) {
+			return nil, fmt.Errorf("getting token from device code: %w", err)
+		}
+
+		switch oidcErr.ErrorType {
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += slowDownInterval
+		case "authorization_declined":
+			return nil, ErrInteractiveLoginDeclined
+		case "expired_token", "code_expired":
+			return nil, ErrInteractiveLoginTimeout
+		default:
+			return nil, fmt.Errorf("getting token from device code: %w", err)
+		}
+	}
+}
+
+// BrowserLogin will login using the OAuth authorization code flow with PKCE. A listener is
+// started on the loopback interface to receive the authorization code once the user has
+// logged in using the browser.
+func BrowserLogin(ctx context.Context, input *InteractiveLoginInput) (*OauthToken, error) {
+	codeVerifier, err := randomString()
+	if err != nil {
+		return nil, fmt.Errorf("generating code verifier: %w", err)
+	}
+
+	state, err := randomString()
+	if err != nil {
+		return nil, fmt.Errorf("generating state: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("starting loopback listener: %w", err)
+	}
+	defer listener.Close()
+
+	redirectURI := fmt.Sprintf("http://localhost:%d/", listener.Addr().(*net.TCPAddr).Port)
+
+	results := make(chan authCodeResult, 1)
+	server := &http.Server{
+		Handler:           authCodeHandler(state, results),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			zap.S().Debugw("loopback listener stopped", "error", err.Error())
+		}
+	}()
+	defer server.Close()
+
+	loginURL, err := authorizeURL(input, redirectURI, state, codeChallenge(codeVerifier))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := input.OpenURL(loginURL); err != nil {
+		return nil, fmt.Errorf("opening login url: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout(input))
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+		return nil, ErrInteractiveLoginTimeout
+	case result := <-results:
+		if result.err != nil {
+			return nil, result.err
+		}
+
+		return input.Client.GetOauth2TokenFromAuthCode(input.AuthCfg, input.Resource, result.code, redirectURI, codeVerifier)
+	}
+}
+
+func loginTimeout(input *InteractiveLoginInput) time.Duration {
+	if input.Timeout <= 0 {
+		return DefaultInteractiveLoginTimeout
+	}
+
+	return input.Timeout
+}
+
+type authCodeResult struct {
+	code string
+	err  error
+}
+
+func authCodeHandler(state string, results chan<- authCodeResult) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		result := authCodeResult{code: query.Get("code")}
+
+		switch {
+		case query.Get("error") != "":
+			result.err = &OIDCErrorResponse{ErrorType: query.Get("error"), ErrorDescription: query.Get("error_description")}
+		case query.Get("state") != state:
+			result.err = ErrInvalidLoginState
+		case result.code == "":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if result.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "Login failed: %s. You can close this window.", result.err)
+		} else {
+			fmt.Fprint(w, "Login successful. You can close this window and return to kconnect.")
+		}
+
+		select {
+		case results <- result:
+		default:
+		}
+	})
+}
+
+func authorizeURL(input *InteractiveLoginInput, redirectURI, state, challenge string) (string, error) {
+	u, err := url.Parse(input.AuthCfg.Endpoints.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("parsing authorization endpoint: %w", err)
+	}
+
+	params := map[string]string{
+		"client_id":             input.AuthCfg.ClientID,
+		"response_type":         "code",
+		"redirect_uri":          redirectURI,
+		"state":                 state,
+		"code_challenge":        challenge,
+		"code_challenge_method": "S256",
+		"prompt":                "select_account",
+	}
+	setResourceParams(params, input.AuthCfg.Endpoints.AuthorizationEndpoint, input.Resource)
+
+	if input.AuthCfg.Username != "" {
+		params["login_hint"] = input.AuthCfg.Username
+	}
+
+	query := u.Query()
+	for k, v := range params {
+		query.Set(k, v)
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+func codeChallenge(verifier string) string {
+	hash := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+func randomString() (string, error) {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package identity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	khttp "github.com/fidelity/kconnect/pkg/http"
+)
+
+// fakeAAD is a fake Azure AD used to test the interactive logins
+type fakeAAD struct {
+	server *httptest.Server
+
+	lock          sync.Mutex
+	pendingPolls  int
+	codeChallenge string
+	ropcError     *OIDCErrorResponse
+	grants        []string
+}
+
+func newFakeAAD(t *testing.T) *fakeAAD {
+	t.Helper()
+
+	fake := &fakeAAD{}
+	fake.server = httptest.NewServer(http.HandlerFunc(fake.handler))
+	t.Cleanup(fake.server.Close)
+
+	return fake
+}
+
+func (f *fakeAAD) authority() *AuthorityConfig {
+	return &AuthorityConfig{
+		Tenant:       "tenant",
+		AuthorityURI: f.server.URL + "/tenant/",
+	}
+}
+
+func (f *fakeAAD) handler(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	base := f.server.URL + "/tenant"
+
+	switch r.URL.Path {
+	case "/tenant/v2.0/.well-known/openid-configuration":
+		fmt.Fprintf(w, `{"authorization_endpoint":"%[1]s/oauth2/v2.0/authorize","token_endpoint":"%[1]s/oauth2/v2.0/token","issuer":"%[1]s/v2.0"}`, base)
+	case "/tenant/oauth2/v2.0/devicecode":
+		fmt.Fprint(w, `{"user_code":"ABC123","device_code":"device-1","verification_uri":"https://microsoft.com/devicelogin","expires_in":"60","interval":"0"}`)
+	case "/tenant/oauth2/v2.0/authorize":
+		f.codeChallenge = r.URL.Query().Get("code_challenge")
+		redirect := fmt.Sprintf("%s?code=auth-code&state=%s", r.URL.Query().Get("redirect_uri"), r.URL.Query().Get("state"))
+		http.Redirect(w, r, redirect, http.StatusFound)
+	case "/tenant/oauth2/v2.0/token", "/tenant/oauth2/token":
+		r.ParseForm() //nolint: errcheck
+		f.token(w, r.Form)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeAAD) token(w http.ResponseWriter, form url.Values) {
+	grant := form.Get("grant_type")
+	f.grants = append(f.grants, grant)
+
+	var oidcErr *OIDCErrorResponse
+
+	switch grant {
+	case "password":
+		oidcErr = f.ropcError
+	case "urn:ietf:params:oauth:grant-type:device_code":
+		if f.pendingPolls > 0 {
+			f.pendingPolls--
+			oidcErr = &OIDCErrorResponse{ErrorType: "authorization_pending"}
+		}
+	case "authorization_code":
+		if codeChallenge(form.Get("code_verifier")) != f.codeChallenge || form.Get("code") != "auth-code" {
+			oidcErr = &OIDCErrorResponse{ErrorType: "invalid_grant"}
+		}
+	case "refresh_token":
+		if form.Get("refresh_token") != "refresh-1" {
+			oidcErr = &OIDCErrorResponse{ErrorType: "invalid_grant"}
+		}
+	}
+
+	if oidcErr != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(oidcErr) //nolint: errcheck
+
+		return
+	}
+
+	resource := form.Get("resource")
+	if resource == "" {
+		resource = strings.Fields(form.Get("scope"))[0]
+	}
+
+	fmt.Fprintf(w, `{"token_type":"Bearer","access_token":"%s %s","refresh_token":"refresh-1","expires_in":"3600"}`, grant, resource)
+}
+
+func (f *fakeAAD) authCfg(t *testing.T) *AuthenticationConfig {
+	t.Helper()
+
+	cfg := &AuthenticationConfig{
+		Authority: f.authority(),
+		ClientID:  "client-1",
+	}
+
+	endpoints, err := NewOIDCEndpointsResolver(khttp.NewHTTPClient()).Resolve(cfg.Authority)
+	if err != nil {
+		t.Fatalf("resolving endpoints: %s", err)
+	}
+
+	cfg.Endpoints = endpoints
+
+	return cfg
+}
+
+func Test_DeviceCodeLogin(t *testing.T) {
+	deviceCodePollInterval = 10 * time.Millisecond
+
+	fake := newFakeAAD(t)
+	fake.pendingPolls = 2
+
+	output := &bytes.Buffer{}
+
+	token, err := DeviceCodeLogin(context.Background(), &InteractiveLoginInput{
+		Client:   NewClient(khttp.NewHTTPClient()),
+		AuthCfg:  fake.authCfg(t),
+		Resource: "https://management.azure.com/",
+		Output:   output,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if token.AccessToken != "urn:ietf:params:oauth:grant-type:device_code https://management.azure.com//.default" {
+		t.Fatalf("unexpected access token %s", token.AccessToken)
+	}
+
+	if !strings.Contains(output.String(), "ABC123") {
+		t.Fatalf("expected the user code in the output but got %s", output.String())
+	}
+}
+
+func Test_BrowserLogin(t *testing.T) {
+	fake := newFakeAAD(t)
+
+	token, err := BrowserLogin(context.Background(), &InteractiveLoginInput{
+		Client:   NewClient(khttp.NewHTTPClient()),
+		AuthCfg:  fake.authCfg(t),
+		Resource: "6dae42f8-4368-4678-94ff-3960e28e3630",
+		OpenURL: func(loginURL string) error {
+			// simulate the browser following the redirects back to the loopback listener
+			resp, err := http.Get(loginURL) //nolint: gosec,noctx
+			if err != nil {
+				return err
+			}
+
+			return resp.Body.Close()
+		},
+		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if token.AccessToken != "authorization_code 6dae42f8-4368-4678-94ff-3960e28e3630/.default" {
+		t.Fatalf("unexpected access token %s", token.AccessToken)
+	}
+}
+
+func Test_BrowserLoginTimeout(t *testing.T) {
+	fake := newFakeAAD(t)
+
+	_, err := BrowserLogin(context.Background(), &InteractiveLoginInput{
+		Client:   NewClient(khttp.NewHTTPClient()),
+		AuthCfg:  fake.authCfg(t),
+		Resource: "https://management.azure.com/",
+		OpenURL:  func(string) error { return nil },
+		Timeout:  50 * time.Millisecond,
+	})
+	if !errors.Is(err, ErrInteractiveLoginTimeout) {
+		t.Fatalf("expected ErrInteractiveLoginTimeout but got: %v", err)
+	}
+}
+
+func Test_PasswordLoginFallsBackToDeviceCode(t *testing.T) {
+	deviceCodePollInterval = 10 * time.Millisecond
+
+	fake := newFakeAAD(t)
+	fake.ropcError = &OIDCErrorResponse{ErrorType: "invalid_grant", ErrorCodes: []int{50076}}
+
+	authCfg := &AuthenticationConfig{
+		Authority: fake.authority(),
+		ClientID:  "client-1",
+		Username:  "bob@example.com",
+		Password:  "password",
+	}
+
+	endpoints, err := NewOAuthEndpointsResolver(khttp.NewHTTPClient()).Resolve(authCfg.Authority)
+	if err != nil {
+		t.Fatalf("resolving endpoints: %s", err)
+	}
+
+	// The fake only serves the v2.0 device code endpoint
+	endpoints.DeviceCodeEndpoint = fake.server.URL + "/tenant/oauth2/v2.0/devicecode"
+	authCfg.Endpoints = endpoints
+
+	realm := &UserRealm{AccountType: AccountTypeManaged}
+	id := NewActiveDirectoryIdentity(authCfg, realm, "aad", khttp.NewHTTPClient(), false, WithInteractiveIO(nil, &bytes.Buffer{}))
+
+	token, err := id.GetOAuthToken("https://management.azure.com/")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(token.AccessToken, "urn:ietf:params:oauth:grant-type:device_code") {
+		t.Fatalf("expected token from the device code flow but got %s", token.AccessToken)
+	}
+
+	// The refresh token from the device code login is used for other resources
+	token, err = id.GetOAuthToken("6dae42f8-4368-4678-94ff-3960e28e3630")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(token.AccessToken, "refresh_token") {
+		t.Fatalf("expected token from the refresh token but got %s", token.AccessToken)
+	}
+
+	expectedGrants := []string{"password", "urn:ietf:params:oauth:grant-type:device_code", "refresh_token"}
+	if strings.Join(fake.grants, ",") != strings.Join(expectedGrants, ",") {
+		t.Fatalf("expected grants %v but got %v", expectedGrants, fake.grants)
+	}
+}
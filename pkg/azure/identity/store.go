@@ -0,0 +1,344 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package identity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/fidelity/kconnect/pkg/defaults"
+	"github.com/fidelity/kconnect/pkg/encryptedfile"
+	khttp "github.com/fidelity/kconnect/pkg/http"
+	"github.com/fidelity/kconnect/pkg/provider/identity"
+)
+
+const (
+	tokenCacheFileName    = "aad-tokens.enc"
+	tokenCacheKeyFileName = "aad-tokens.key"
+
+	// TokenExpiryMargin is how long before it expires that a cached access token is refreshed
+	TokenExpiryMargin = 5 * time.Minute
+	// RefreshTokenLifetime is how long an unused refresh token is assumed to be valid for. Azure AD
+	// doesn't return the expiry of refresh tokens and the default inactivity limit is 90 days.
+	RefreshTokenLifetime = 90 * 24 * time.Hour
+)
+
+// CachedToken is an Azure AD token that has been saved in the token cache
+type CachedToken struct {
+	Tenant       string    `json:"tenant"`
+	ClientID     string    `json:"clientId"`
+	Resource     string    `json:"resource"`
+	Username     string    `json:"username,omitempty"`
+	LoginMode    LoginMode `json:"loginMode,omitempty"`
+	AADHost      AADHost   `json:"aadHost"`
+	AuthorityURI string    `json:"authorityUri"`
+	Endpoints    Endpoints `json:"endpoints"`
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	RefreshedAt  time.Time `json:"refreshedAt"`
+}
+
+// IsValid returns true if the access token can be used without refreshing it
+func (t *CachedToken) IsValid() bool {
+	return t.AccessToken != "" && time.Now().Add(TokenExpiryMargin).Before(t.ExpiresAt)
+}
+
+// CanRefresh returns true if the token has a refresh token that should still be accepted
+func (t *CachedToken) CanRefresh() bool {
+	return t.RefreshToken != "" && time.Since(t.RefreshedAt) < RefreshTokenLifetime
+}
+
+// OauthToken returns the cached access token as an oauth token
+func (t *CachedToken) OauthToken() *OauthToken {
+	return &OauthToken{
+		Type:         "Bearer",
+		Resource:     t.Resource,
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.RefreshToken,
+		ExpiresOn:    json.Number(fmt.Sprintf("%d", t.ExpiresAt.Unix())),
+	}
+}
+
+type tokenCacheFile struct {
+	Tokens []*CachedToken `json:"tokens"`
+}
+
+// TokenCachePath returns the path of the file that the Azure AD tokens are cached in
+func TokenCachePath() string {
+	return path.Join(defaults.AppDirectory(), tokenCacheFileName)
+}
+
+// TokenCache is a file based cache of Azure AD tokens. The file is only readable by the user
+// and is obfuscated by encrypting it with a key stored alongside it, which doesn't protect it
+// from anyone who can read the user's files.
+type TokenCache struct {
+	file *encryptedfile.File
+}
+
+// NewTokenCache will create a token cache that uses the default paths in the app directory
+func NewTokenCache() *TokenCache {
+	return newTokenCache(TokenCachePath(), path.Join(defaults.AppDirectory(), tokenCacheKeyFileName))
+}
+
+func newTokenCache(cachePath, keyPath string) *TokenCache {
+	return &TokenCache{
+		file: encryptedfile.New(cachePath, keyPath),
+	}
+}
+
+// Find returns the cached token for the resource. Nil is returned if there isn't a cached token.
+func (c *TokenCache) Find(tenant, clientID, username, resource string) (*CachedToken, error) {
+	tokens, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, token := range tokens {
+		if token.matches(tenant, clientID, username) && token.Resource == resource {
+			return token, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// FindRefreshable returns the most recently refreshed token for the tenant and client that has a
+// usable refresh token. Nil is returned if there isn't one.
+func (c *TokenCache) FindRefreshable(tenant, clientID, username string) (*CachedToken, error) {
+	tokens, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *CachedToken
+
+	for _, token := range tokens {
+		if !token.matches(tenant, clientID, username) || !token.CanRefresh() {
+			continue
+		}
+
+		if found == nil || token.RefreshedAt.After(found.RefreshedAt) {
+			found = token
+		}
+	}
+
+	return found, nil
+}
+
+// Put will add the token to the cache, replacing any existing token for the same resource
+func (c *TokenCache) Put(cached *CachedToken) error {
+	return c.update(func(tokens []*CachedToken) []*CachedToken {
+		remaining := []*CachedToken{}
+
+		for _, token := range tokens {
+			if token.Tenant == cached.Tenant && token.ClientID == cached.ClientID && token.Resource == cached.Resource {
+				continue
+			}
+
+			remaining = append(remaining, token)
+		}
+
+		return append(remaining, cached)
+	})
+}
+
+// Delete will remove the tokens for the tenant and client from the cache. If the client id is
+// empty the tokens for all clients in the tenant are removed.
+func (c *TokenCache) Delete(tenant, clientID string) error {
+	return c.update(func(tokens []*CachedToken) []*CachedToken {
+		remaining := []*CachedToken{}
+
+		for _, token := range tokens {
+			if token.Tenant == tenant && (clientID == "" || token.ClientID == clientID) {
+				continue
+			}
+
+			remaining = append(remaining, token)
+		}
+
+		return remaining
+	})
+}
+
+func (t *CachedToken) matches(tenant, clientID, username string) bool {
+	if t.Tenant != tenant || t.ClientID != clientID {
+		return false
+	}
+
+	return username == "" || t.Username == "" || t.Username == username
+}
+
+func (c *TokenCache) update(updateFn func(tokens []*CachedToken) []*CachedToken) error {
+	err := c.file.Update(func(data []byte) ([]byte, error) {
+		tokens, err := unmarshalTokens(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(&tokenCacheFile{Tokens: updateFn(tokens)})
+	})
+	if err != nil {
+		return fmt.Errorf("updating token cache: %w", tokenCacheError(err))
+	}
+
+	return nil
+}
+
+func (c *TokenCache) read() ([]*CachedToken, error) {
+	data, err := c.file.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading token cache: %w", tokenCacheError(err))
+	}
+
+	return unmarshalTokens(data)
+}
+
+func unmarshalTokens(data []byte) ([]*CachedToken, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	cacheFile := &tokenCacheFile{}
+	if err := json.Unmarshal(data, cacheFile); err != nil {
+		return nil, fmt.Errorf("unmarshalling token cache: %w", err)
+	}
+
+	return cacheFile.Tokens, nil
+}
+
+// tokenCacheError returns ErrInvalidTokenCache if the cache can't be decrypted
+func tokenCacheError(err error) error {
+	if errors.Is(err, encryptedfile.ErrInvalidFile) {
+		return fmt.Errorf("%s: %w", err.Error(), ErrInvalidTokenCache)
+	}
+
+	return err
+}
+
+// NewIdentityStore will create a new Azure AD identity store. The tokens are cached per tenant,
+// client and resource so that they can be reused and refreshed without the user logging in again.
+func NewIdentityStore(cache *TokenCache, httpClient khttp.Client, tenant, clientID, username, idProviderName string) *IdentityStore {
+	return &IdentityStore{
+		cache:          cache,
+		httpClient:     httpClient,
+		tenant:         tenant,
+		clientID:       clientID,
+		username:       username,
+		idProviderName: idProviderName,
+	}
+}
+
+// IdentityStore is a store for Azure AD identities that uses the token cache
+type IdentityStore struct {
+	cache          *TokenCache
+	httpClient     khttp.Client
+	tenant         string
+	clientID       string
+	username       string
+	idProviderName string
+}
+
+func (s *IdentityStore) CredsExists() (bool, error) {
+	cached, err := s.cache.FindRefreshable(s.tenant, s.clientID, s.username)
+	if err != nil {
+		return false, err
+	}
+
+	return cached != nil, nil
+}
+
+// Save will cache the tokens that the identity has acquired
+func (s *IdentityStore) Save(userID identity.Identity) error {
+	adID, ok := userID.(*ActiveDirectoryIdentity)
+	if !ok {
+		return ErrNotActiveDirectoryIdentity
+	}
+
+	for _, cached := range adID.tokens {
+		if err := s.cache.Put(cached); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Load will create an identity from the cached refresh token. The identity gets its tokens
+// from the cache and refreshes them when they expire.
+func (s *IdentityStore) Load() (identity.Identity, error) {
+	cached, err := s.cache.FindRefreshable(s.tenant, s.clientID, s.username)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached == nil {
+		return nil, ErrNoCachedToken
+	}
+
+	endpoints := cached.Endpoints
+	authCfg := &AuthenticationConfig{
+		Authority: &AuthorityConfig{
+			Tenant:       cached.Tenant,
+			Host:         cached.AADHost,
+			AuthorityURI: cached.AuthorityURI,
+		},
+		ClientID:  cached.ClientID,
+		Username:  cached.Username,
+		Endpoints: &endpoints,
+	}
+
+	// The password isn't cached so if the refresh token is rejected the user logs in with a device code
+	loginMode := cached.LoginMode
+	if loginMode != LoginModeBrowser {
+		loginMode = LoginModeDeviceCode
+	}
+
+	id := NewActiveDirectoryIdentity(authCfg, &UserRealm{}, s.idProviderName, s.httpClient, true, WithLoginMode(loginMode), WithTokenCache(s.cache))
+	id.refreshToken = cached.RefreshToken
+	id.tokens[cached.Resource] = cached
+
+	return id, nil
+}
+
+func (s *IdentityStore) Expired() bool {
+	exists, err := s.CredsExists()
+
+	return err != nil || !exists
+}
+
+// Delete will remove the cached tokens for the tenant and client
+func (s *IdentityStore) Delete() error {
+	return s.cache.Delete(s.tenant, s.clientID)
+}
+
+// expiresAt returns when the token expires. Azure AD returns the expiry as seconds since the
+// epoch for the v1 endpoints and only the lifetime in seconds for the v2 endpoints.
+func expiresAt(token *OauthToken) time.Time {
+	if expiresOn, err := token.ExpiresOn.Int64(); err == nil && expiresOn > 0 {
+		return time.Unix(expiresOn, 0)
+	}
+
+	if expiresIn, err := token.ExpiresIn.Int64(); err == nil && expiresIn > 0 {
+		return time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+
+	return time.Now()
+}
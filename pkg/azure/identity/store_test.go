@@ -0,0 +1,189 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package identity
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	khttp "github.com/fidelity/kconnect/pkg/http"
+)
+
+func newTestTokenCache(t *testing.T) *TokenCache {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	return newTokenCache(path.Join(dir, tokenCacheFileName), path.Join(dir, tokenCacheKeyFileName))
+}
+
+func Test_TokenCache(t *testing.T) {
+	cache := newTestTokenCache(t)
+
+	cached := &CachedToken{
+		Tenant:       "tenant",
+		ClientID:     "client-1",
+		Resource:     "https://management.azure.com/",
+		Username:     "bob@example.com",
+		AccessToken:  "secret-access-token",
+		RefreshToken: "secret-refresh-token",
+		ExpiresAt:    time.Now().Add(time.Hour),
+		RefreshedAt:  time.Now(),
+	}
+	if err := cache.Put(cached); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(cache.file.Path())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if bytes.Contains(data, []byte("secret")) {
+		t.Fatal("expected the token cache to be encrypted")
+	}
+
+	found, err := cache.Find("tenant", "client-1", "bob@example.com", "https://management.azure.com/")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if found == nil || found.AccessToken != "secret-access-token" || !found.IsValid() {
+		t.Fatalf("expected the cached token but got %v", found)
+	}
+
+	found, err = cache.Find("tenant", "client-1", "alice@example.com", "https://management.azure.com/")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if found != nil {
+		t.Fatal("expected no token for a different user")
+	}
+
+	if err := cache.Delete("tenant", ""); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	found, err = cache.FindRefreshable("tenant", "client-1", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if found != nil {
+		t.Fatal("expected the tokens to be deleted")
+	}
+}
+
+func Test_TokenCacheWrongKey(t *testing.T) {
+	cache := newTestTokenCache(t)
+
+	if err := cache.Put(&CachedToken{Tenant: "tenant", ClientID: "client-1", Resource: "resource"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := ioutil.WriteFile(cache.file.KeyPath(), bytes.Repeat([]byte("k"), 32), 0600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err := cache.Find("tenant", "client-1", "", "resource")
+	if !errors.Is(err, ErrInvalidTokenCache) {
+		t.Fatalf("expected ErrInvalidTokenCache but got: %v", err)
+	}
+}
+
+func Test_CachedTokensReusedAndRefreshed(t *testing.T) {
+	fake := newFakeAAD(t)
+	cache := newTestTokenCache(t)
+
+	authCfg := &AuthenticationConfig{
+		Authority: fake.authority(),
+		ClientID:  "client-1",
+		Username:  "bob@example.com",
+		Password:  "password",
+	}
+
+	endpoints, err := NewOAuthEndpointsResolver(khttp.NewHTTPClient()).Resolve(authCfg.Authority)
+	if err != nil {
+		t.Fatalf("resolving endpoints: %s", err)
+	}
+
+	authCfg.Endpoints = endpoints
+
+	realm := &UserRealm{AccountType: AccountTypeManaged}
+	id := NewActiveDirectoryIdentity(authCfg, realm, "aad", khttp.NewHTTPClient(), false, WithTokenCache(cache))
+
+	if _, err := id.GetOAuthToken("https://management.azure.com/"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// A later login loads the identity from the cache without a password
+	store := NewIdentityStore(cache, khttp.NewHTTPClient(), "tenant", "client-1", "", "aad")
+	if store.Expired() {
+		t.Fatal("expected the store to have a refreshable token")
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cachedID := loaded.(*ActiveDirectoryIdentity)
+
+	token, err := cachedID.GetOAuthToken("https://management.azure.com/")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(token.AccessToken, "password") {
+		t.Fatalf("expected the cached token but got %s", token.AccessToken)
+	}
+
+	// Tokens close to expiring are refreshed silently
+	expiring, err := cache.Find("tenant", "client-1", "", "https://management.azure.com/")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expiring.ExpiresAt = time.Now().Add(time.Minute)
+	if err := cache.Put(expiring); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	token, err = cachedID.GetOAuthToken("https://management.azure.com/")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(token.AccessToken, "refresh_token") {
+		t.Fatalf("expected a refreshed token but got %s", token.AccessToken)
+	}
+
+	expectedGrants := []string{"password", "refresh_token"}
+	if strings.Join(fake.grants, ",") != strings.Join(expectedGrants, ",") {
+		t.Fatalf("expected grants %v but got %v", expectedGrants, fake.grants)
+	}
+
+	if cachedID.IsExpired() {
+		t.Fatal("expected the identity not to be expired")
+	}
+}
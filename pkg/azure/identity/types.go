@@ -19,6 +19,7 @@ package identity
 import (
 	"encoding/json"
 	"encoding/xml"
+	"slices"
 
 	"github.com/fidelity/kconnect/pkg/azure/wstrust"
 )
@@ -30,6 +31,10 @@ type Client interface {
 	GetOauth2TokenFromSamlAssertion(cfg *AuthenticationConfig, assertion string, resource string) (*OauthToken, error)
 	GetOauth2TokenFromUsernamePassword(cfg *AuthenticationConfig, resource string) (*OauthToken, error)
 	GetOauth2TokenFromAzureAccessToken(cfg *AuthenticationConfig, resource string) (*OauthToken, error)
+	GetDeviceCode(cfg *AuthenticationConfig, resource string) (*DeviceCodeResponse, error)
+	GetOauth2TokenFromDeviceCode(cfg *AuthenticationConfig, resource string, deviceCode string) (*OauthToken, error)
+	GetOauth2TokenFromAuthCode(cfg *AuthenticationConfig, resource string, code string, redirectURI string, codeVerifier string) (*OauthToken, error)
+	GetOauth2TokenFromRefreshToken(cfg *AuthenticationConfig, resource string, refreshToken string) (*OauthToken, error)
 }
 
 type AuthorityConfig struct {
@@ -83,6 +88,21 @@ var (
 	AccountTypeUnknown   = AccountType("Unknown")
 )
 
+// LoginMode is the method used to login to Azure AD
+type LoginMode string
+
+var (
+	// LoginModePassword uses the username and password of the user
+	LoginModePassword = LoginMode("password")
+	// LoginModeDeviceCode uses the OAuth device authorization flow
+	LoginModeDeviceCode = LoginMode("devicecode")
+	// LoginModeBrowser uses the OAuth authorization code flow with PKCE in a browser
+	LoginModeBrowser = LoginMode("browser")
+)
+
+// LoginModes is the list of supported login modes
+var LoginModes = []LoginMode{LoginModePassword, LoginModeDeviceCode, LoginModeBrowser}
+
 type WSTrustResponse struct {
 	XMLName xml.Name
 	Body    WSTrustResponseBody
@@ -127,6 +147,17 @@ type OauthToken struct {
 	IDToken      string      `json:"id_token"`
 }
 
+// DeviceCodeResponse is the response when starting the device authorization flow
+type DeviceCodeResponse struct {
+	UserCode        string      `json:"user_code"`
+	DeviceCode      string      `json:"device_code"`
+	VerificationURI string      `json:"verification_uri"`
+	VerificationURL string      `json:"verification_url"` // the v1 endpoints use url
+	ExpiresIn       json.Number `json:"expires_in"`
+	Interval        json.Number `json:"interval"`
+	Message         string      `json:"message"`
+}
+
 // OIDCErrorResponse represents an error message from the Azure AD OIDC service
 type OIDCErrorResponse struct {
 	ErrorType        string `json:"error"`
@@ -142,6 +173,25 @@ func (r *OIDCErrorResponse) Error() string {
 	return r.ErrorDescription
 }
 
+// interactionRequiredCodes are the AADSTS error codes that mean the user must interact
+// with Azure AD, i.e. for MFA or conditional access
+var interactionRequiredCodes = []int{50072, 50074, 50076, 50079, 50158, 65001}
+
+// IsInteractionRequired returns true if the error means that the user must login interactively
+func (r *OIDCErrorResponse) IsInteractionRequired() bool {
+	if r.ErrorType == "interaction_required" || r.ErrorType == "consent_required" {
+		return true
+	}
+
+	for _, code := range r.ErrorCodes {
+		if slices.Contains(interactionRequiredCodes, code) {
+			return true
+		}
+	}
+
+	return false
+}
+
 type EnvelopeParams struct {
 	SchemaLocation        string
 	SoapAction            string
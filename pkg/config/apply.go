@@ -19,6 +19,7 @@ package config
 import (
 	"fmt"
 	"strconv"
+	"time"
 )
 
 // ApplyToConfigSet will apply the saved app configuration to the supplied config set.
@@ -97,6 +98,15 @@ func setItemValue(item *Item, value string) error {
 
 		item.Value = boolVal
 
+		return nil
+	case ItemTypeDuration:
+		durationVal, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("parsing config as duration: %w", err)
+		}
+
+		item.Value = durationVal
+
 		return nil
 	default:
 		return ErrUnknownItemType
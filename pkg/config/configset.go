@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 var (
@@ -64,9 +65,10 @@ func (i *Item) HasValue() bool {
 type ItemType string
 
 var (
-	ItemTypeString = ItemType("string")
-	ItemTypeInt    = ItemType("int")
-	ItemTypeBool   = ItemType("bool")
+	ItemTypeString   = ItemType("string")
+	ItemTypeInt      = ItemType("int")
+	ItemTypeBool     = ItemType("bool")
+	ItemTypeDuration = ItemType("duration")
 )
 
 type ConfigurationSet interface {
@@ -89,6 +91,7 @@ type ConfigurationSet interface {
 	String(name string, defaultValue string, description string) (*Item, error)
 	Int(name string, defaultValue int, description string) (*Item, error)
 	Bool(name string, defaultValue bool, description string) (*Item, error)
+	Duration(name string, defaultValue time.Duration, description string) (*Item, error)
 }
 
 func NewConfigurationSet() ConfigurationSet {
@@ -150,6 +153,9 @@ func (s *configSet) ValueString(name string) string {
 	case ItemTypeBool:
 		boolVal := item.Value.(bool)
 		return fmt.Sprintf("%t", boolVal)
+	case ItemTypeDuration:
+		durationVal := item.Value.(time.Duration)
+		return durationVal.String()
 	default:
 		return ""
 	}
@@ -317,3 +323,18 @@ func (s *configSet) Bool(name string, defaultValue bool, description string) (*I
 
 	return item, nil
 }
+
+func (s *configSet) Duration(name string, defaultValue time.Duration, description string) (*Item, error) {
+	item := &Item{
+		Name:         name,
+		Type:         ItemTypeDuration,
+		DefaultValue: defaultValue,
+		Description:  description,
+	}
+
+	if err := s.Add(item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
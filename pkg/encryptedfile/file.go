@@ -0,0 +1,240 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptedfile
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	keySize = 32
+
+	lockSuffix        = ".lock"
+	lockRetryInterval = 20 * time.Millisecond
+	lockTimeout       = 10 * time.Second
+	// staleLockAge is when a lock file is assumed to have been left behind by a process that exited
+	staleLockAge = time.Minute
+)
+
+var (
+	ErrInvalidFile = errors.New("encrypted file can't be decrypted")
+	ErrLockTimeout = errors.New("timed out waiting for the lock on the encrypted file")
+)
+
+// File is a file that is encrypted with AES-GCM. The key is stored in a separate file and
+// is generated the first time that the file is written. Both files are only readable by the user.
+//
+// As the key is kept next to the file with the same permissions this is obfuscation rather
+// than protection: it stops the contents being read by accident, e.g. when the file is
+// viewed or shared, but anyone who can read the file can also read the key. It's no more
+// secure than a plaintext file that is only readable by the user.
+//
+// Updates are serialized using a lock file and the files are replaced atomically, so that
+// processes running at the same time, such as the AWS credential process, don't corrupt the
+// file or generate a different key.
+type File struct {
+	path    string
+	keyPath string
+}
+
+// New creates an encrypted file that uses the key in keyPath
+func New(path, keyPath string) *File {
+	return &File{
+		path:    path,
+		keyPath: keyPath,
+	}
+}
+
+// Path returns the path of the encrypted file
+func (f *File) Path() string {
+	return f.path
+}
+
+// KeyPath returns the path of the key file
+func (f *File) KeyPath() string {
+	return f.keyPath
+}
+
+// Read returns the decrypted contents of the file. Nil is returned if the file doesn't exist.
+func (f *File) Read() ([]byte, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("reading %s: %w", f.path, err)
+	}
+
+	gcm, err := f.cipher(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("reading %s: %w", f.path, ErrInvalidFile)
+	}
+
+	nonce, encrypted := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	decrypted, err := gcm.Open(nil, nonce, encrypted, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting %s: %w", f.path, ErrInvalidFile)
+	}
+
+	return decrypted, nil
+}
+
+// Update will replace the contents of the file with the result of updateFn, which is called
+// with the current decrypted contents. Other updates wait until this update has finished.
+func (f *File) Update(updateFn func(data []byte) ([]byte, error)) error {
+	unlock, err := f.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := f.Read()
+	if err != nil {
+		return err
+	}
+
+	updated, err := updateFn(data)
+	if err != nil {
+		return err
+	}
+
+	gcm, err := f.cipher(true)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("generating nonce: %w", err)
+	}
+
+	if err := writeFileAtomic(f.path, gcm.Seal(nonce, nonce, updated, nil)); err != nil {
+		return fmt.Errorf("saving %s: %w", f.path, err)
+	}
+
+	return nil
+}
+
+// cipher returns the cipher used to encrypt the file. If create is true the key is generated
+// if it doesn't exist, this must only be done when holding the lock.
+func (f *File) cipher(create bool) (cipher.AEAD, error) {
+	key, err := os.ReadFile(f.keyPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && create:
+		key = make([]byte, keySize)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, fmt.Errorf("generating key: %w", err)
+		}
+
+		if err := writeFileAtomic(f.keyPath, key); err != nil {
+			return nil, fmt.Errorf("saving key to %s: %w", f.keyPath, err)
+		}
+	case errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("reading key %s: %w", f.keyPath, ErrInvalidFile)
+	case err != nil:
+		return nil, fmt.Errorf("reading key %s: %w", f.keyPath, err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+
+	return gcm, nil
+}
+
+// lock creates the lock file, waiting for any other process that holds it. The returned
+// function removes the lock file.
+func (f *File) lock() (func(), error) {
+	lockPath := f.path + lockSuffix
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			lockFile.Close() //nolint: errcheck
+
+			return func() {
+				os.Remove(lockPath) //nolint: errcheck
+			}, nil
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("creating lock file %s: %w", lockPath, err)
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lockPath) //nolint: errcheck
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("locking %s: %w", f.path, ErrLockTimeout)
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// writeFileAtomic writes the data to a temporary file that is then renamed, so that readers
+// never see a partially written file. The temporary file is created only readable by the user.
+func writeFileAtomic(path string, data []byte) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %w", err)
+	}
+
+	tmpPath := tmpFile.Name()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()    //nolint: errcheck
+		os.Remove(tmpPath) //nolint: errcheck
+
+		return fmt.Errorf("writing temporary file: %w", err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpPath) //nolint: errcheck
+		return fmt.Errorf("closing temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath) //nolint: errcheck
+		return fmt.Errorf("replacing file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptedfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestFile(t *testing.T) *File {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	return New(filepath.Join(dir, "cache.enc"), filepath.Join(dir, "cache.key"))
+}
+
+func Test_FileUpdateAndRead(t *testing.T) {
+	file := newTestFile(t)
+
+	data, err := file.Read()
+	if err != nil || data != nil {
+		t.Fatalf("expected no data for a missing file but got %q, %v", data, err)
+	}
+
+	err = file.Update(func(data []byte) ([]byte, error) {
+		return []byte("secret"), nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	raw, err := os.ReadFile(file.Path())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if bytes.Contains(raw, []byte("secret")) {
+		t.Fatal("expected the file to be encrypted")
+	}
+
+	data, err = file.Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if string(data) != "secret" {
+		t.Fatalf("expected secret but got %q", data)
+	}
+
+	if _, err := os.Stat(file.Path() + lockSuffix); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("expected the lock file to be removed")
+	}
+}
+
+func Test_FileUpdateError(t *testing.T) {
+	file := newTestFile(t)
+	expectErr := errors.New("update failed")
+
+	if err := file.Update(func([]byte) ([]byte, error) { return nil, expectErr }); !errors.Is(err, expectErr) {
+		t.Fatalf("expected the update error but got: %v", err)
+	}
+
+	if _, err := os.Stat(file.Path()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("expected the file not to be written")
+	}
+}
+
+func Test_FileWrongKey(t *testing.T) {
+	file := newTestFile(t)
+
+	if err := file.Update(func([]byte) ([]byte, error) { return []byte("secret"), nil }); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := os.WriteFile(file.KeyPath(), bytes.Repeat([]byte("k"), keySize), 0600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := file.Read(); !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("expected ErrInvalidFile but got: %v", err)
+	}
+
+	if err := os.Remove(file.KeyPath()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := file.Read(); !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("expected ErrInvalidFile without a key but got: %v", err)
+	}
+}
+
+func Test_FileConcurrentUpdates(t *testing.T) {
+	dir := t.TempDir()
+	updates := 20
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, updates)
+
+	for i := range updates {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			// each update uses its own file, as separate processes would
+			file := New(filepath.Join(dir, "cache.enc"), filepath.Join(dir, "cache.key"))
+			errs <- file.Update(func(data []byte) ([]byte, error) {
+				return fmt.Appendf(data, "%02d,", i), nil
+			})
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	data, err := New(filepath.Join(dir, "cache.enc"), filepath.Join(dir, "cache.key")).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	entries := strings.Split(strings.TrimSuffix(string(data), ","), ",")
+	sort.Strings(entries)
+
+	if len(entries) != updates {
+		t.Fatalf("expected %d entries but got %d: %s", updates, len(entries), data)
+	}
+
+	for i, entry := range entries {
+		if entry != fmt.Sprintf("%02d", i) {
+			t.Fatalf("expected entry %02d but got %s", i, entry)
+		}
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("expected only the file and key to be left but got %d files", len(files))
+	}
+}
+
+func Test_FileStaleLock(t *testing.T) {
+	file := newTestFile(t)
+	lockPath := file.Path() + lockSuffix
+
+	if err := os.WriteFile(lockPath, nil, 0600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	stale := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(lockPath, stale, stale); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := file.Update(func([]byte) ([]byte, error) { return []byte("secret"), nil }); err != nil {
+		t.Fatalf("expected the stale lock to be ignored but got: %s", err)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/fidelity/kconnect/pkg/config"
 	"github.com/spf13/cobra"
@@ -89,6 +90,13 @@ func CreateFlagsFromConfig(cs config.ConfigurationSet) (*pflag.FlagSet, error) {
 			} else {
 				fs.Bool(configItem.Name, defVal, configItem.Description)
 			}
+		case config.ItemTypeDuration:
+			defVal := configItem.DefaultValue.(time.Duration)
+			if configItem.Shorthand != "" {
+				fs.DurationP(configItem.Name, configItem.Shorthand, defVal, configItem.Description)
+			} else {
+				fs.Duration(configItem.Name, defVal, configItem.Description)
+			}
 		default:
 			return nil, config.ErrUnknownItemType
 		}
@@ -120,6 +128,9 @@ func PopulateConfigFromFlags(flags *pflag.FlagSet, cs config.ConfigurationSet) {
 		case "int":
 			val, _ := flags.GetInt(f.Name)
 			cs.SetValue(f.Name, val) //nolint: errcheck
+		case "duration":
+			val, _ := flags.GetDuration(f.Name)
+			cs.SetValue(f.Name, val) //nolint: errcheck
 		}
 	})
 }
@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+const (
+	// DefaultTimeout is the default timeout for a request
+	DefaultTimeout = 60 * time.Second
+	// DefaultRetries is the default number of times a request will be retried
+	DefaultRetries = 3
+)
+
+var (
+	ErrNoCertsInCABundle   = errors.New("no certificates found in ca bundle")
+	ErrClientCertAndKey    = errors.New("both a client certificate and key must be supplied")
+	ErrInvalidProxyAddress = errors.New("invalid proxy address")
+)
+
+// ClientConfig is the configuration used to create a http client
+type ClientConfig struct {
+	// Timeout is the time limit for a request, including any redirects
+	Timeout time.Duration
+	// Retries is the number of times a request is retried if the server responds with
+	// a 429 or 5xx status code
+	Retries int
+	// Proxy is the address of the proxy to use. If not set the proxy is taken from
+	// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	Proxy string
+	// NoProxy is a comma separated list of hosts that shouldn't use the proxy
+	NoProxy string
+	// CABundle is the path to a PEM file with additional certificate authorities to trust
+	CABundle string
+	// ClientCert is the path to a PEM client certificate used for mutual tls
+	ClientCert string
+	// ClientKey is the path to the PEM private key for the client certificate
+	ClientKey string
+	// InsecureSkipVerify disables the verification of the servers certificate
+	InsecureSkipVerify bool
+}
+
+// DefaultClientConfig returns the default http client configuration
+func DefaultClientConfig() *ClientConfig {
+	return &ClientConfig{
+		Timeout: DefaultTimeout,
+		Retries: DefaultRetries,
+	}
+}
+
+// NewHTTPClientWithConfig creates a new http client using the supplied configuration
+func NewHTTPClientWithConfig(cfg *ClientConfig) (Client, error) {
+	client, err := NewStandardClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	clientCfg := *cfg
+
+	return &netHTTPClient{
+		client:  client,
+		retries: cfg.Retries,
+		config:  &clientCfg,
+	}, nil
+}
+
+// ConfigForClient returns a copy of the configuration used to create the http client, so that
+// a client with slightly different settings can be created. The default configuration is
+// returned if the client wasn't created from a configuration.
+func ConfigForClient(client Client) *ClientConfig {
+	netClient, ok := client.(*netHTTPClient)
+	if !ok || netClient.config == nil {
+		return DefaultClientConfig()
+	}
+
+	cfg := *netClient.config
+
+	return &cfg
+}
+
+// NewStandardClient creates a net/http client using the supplied configuration. This is
+// for use with libraries that require a net/http client. The client doesn't retry requests.
+func NewStandardClient(cfg *ClientConfig) (*http.Client, error) {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+	}, nil
+}
+
+func newTransport(cfg *ClientConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	proxyFunc, err := newProxyFunc(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	transport.Proxy = proxyFunc
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+func newProxyFunc(cfg *ClientConfig) (func(*http.Request) (*url.URL, error), error) {
+	if cfg.Proxy == "" && cfg.NoProxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	proxyCfg := httpproxy.FromEnvironment()
+
+	if cfg.Proxy != "" {
+		if _, err := url.Parse(cfg.Proxy); err != nil {
+			return nil, fmt.Errorf("parsing proxy %s: %w", cfg.Proxy, ErrInvalidProxyAddress)
+		}
+
+		proxyCfg.HTTPProxy = cfg.Proxy
+		proxyCfg.HTTPSProxy = cfg.Proxy
+	}
+
+	if cfg.NoProxy != "" {
+		proxyCfg.NoProxy = cfg.NoProxy
+	}
+
+	proxyFunc := proxyCfg.ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}, nil
+}
+
+func newTLSConfig(cfg *ClientConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint: gosec
+	}
+
+	if cfg.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		data, err := os.ReadFile(cfg.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("reading ca bundle %s: %w", cfg.CABundle, err)
+		}
+
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("loading ca bundle %s: %w", cfg.CABundle, ErrNoCertsInCABundle)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		if cfg.ClientCert == "" || cfg.ClientKey == "" {
+			return nil, ErrClientCertAndKey
+		}
+
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -30,19 +32,49 @@ const (
 	MediaTypeJSON = "application/json"
 )
 
-// NewHTTPClient creates a new http client
+var (
+	// retryWaitMin is the wait before the first retry, it doubles for each subsequent retry
+	retryWaitMin = 500 * time.Millisecond
+	// retryWaitMax is the maximum wait between retries
+	retryWaitMax = 10 * time.Second
+)
+
+// NewHTTPClient creates a new http client using the default configuration
 func NewHTTPClient() Client {
-	client := &http.Client{}
+	client, err := NewHTTPClientWithConfig(DefaultClientConfig())
+	if err != nil {
+		// The default config doesn't load any files so this shouldn't happen
+		zap.S().Warnw("failed creating default http client", "error", err.Error())
 
-	return &netHTTPClient{client}
+		return &netHTTPClient{client: &http.Client{Timeout: DefaultTimeout}, config: &ClientConfig{Timeout: DefaultTimeout}}
+	}
+
+	return client
 }
 
 // netHttpClient is a http client based on net/http
 type netHTTPClient struct {
-	client *http.Client
+	client  *http.Client
+	retries int
+	config  *ClientConfig
 }
 
+// Do will send the request. If the server responds with a 429 or 5xx status code the
+// request is retried with an exponential backoff, honouring any Retry-After header.
 func (n *netHTTPClient) Do(req *ClientRequest) (ClientResponse, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := n.do(req)
+		if attempt >= n.retries || !shouldRetry(req.Method, resp, err) {
+			return resp, err
+		}
+
+		wait := retryWait(attempt, resp)
+		zap.S().Debugw("retrying http request", "url", req.URL, "method", req.Method, "attempt", attempt+1, "wait", wait.String())
+		time.Sleep(wait)
+	}
+}
+
+func (n *netHTTPClient) do(req *ClientRequest) (ClientResponse, error) {
 	var r *http.Request
 
 	var err error
@@ -61,14 +93,51 @@ func (n *netHTTPClient) Do(req *ClientRequest) (ClientResponse, error) {
 		r.Header.Add(k, v)
 	}
 
-	zap.S().Debugw("http request", "url", req.URL, "method", req.Method, "headers", req.Headers)
+	zap.S().Debugw("http request", "url", RedactBody(req.URL), "method", req.Method, "headers", RedactHeaders(req.Headers))
+
+	tracer := getTracer()
+	started := time.Now()
 
 	resp, err := n.client.Do(r)
 	if err != nil {
+		if tracer != nil {
+			tracer.trace(newTraceEntry(r, req, started, nil, nil, err))
+		}
+
 		return nil, fmt.Errorf("do request: %w", err)
 	}
 
-	return createResponse(resp)
+	clientResp, err := createResponse(resp)
+	if tracer != nil {
+		tracer.trace(newTraceEntry(r, req, started, resp, clientResp, err))
+	}
+
+	return clientResp, err
+}
+
+func newTraceEntry(r *http.Request, req *ClientRequest, started time.Time, resp *http.Response, clientResp ClientResponse, err error) *traceEntry {
+	entry := &traceEntry{
+		started:        started,
+		duration:       time.Since(started),
+		method:         req.Method,
+		url:            req.URL,
+		requestHeaders: headerMap(r.Header),
+		requestBody:    req.Body,
+		err:            err,
+	}
+
+	if resp != nil {
+		entry.status = resp.StatusCode
+		entry.statusText = http.StatusText(resp.StatusCode)
+		entry.proto = resp.Proto
+		entry.responseHeaders = headerMap(resp.Header)
+	}
+
+	if clientResp != nil {
+		entry.responseBody = clientResp.Body()
+	}
+
+	return entry
 }
 
 func (n *netHTTPClient) Get(url string, headers map[string]string) (ClientResponse, error) {
@@ -92,6 +161,58 @@ func (n *netHTTPClient) Post(url string, body string, headers map[string]string)
 	return n.Do(req)
 }
 
+// shouldRetry returns true if the request should be retried. Requests that were rejected
+// because of rate limiting or unavailability are always retried, other server errors and
+// connection failures are only retried for idempotent methods.
+func shouldRetry(method string, resp ClientResponse, err error) bool {
+	idempotent := method != http.MethodPost && method != http.MethodPatch
+
+	if err != nil {
+		return idempotent
+	}
+
+	switch code := resp.ResponseCode(); {
+	case code == http.StatusTooManyRequests, code == http.StatusServiceUnavailable:
+		return true
+	case code >= http.StatusInternalServerError && code != http.StatusNotImplemented:
+		return idempotent
+	default:
+		return false
+	}
+}
+
+func retryWait(attempt int, resp ClientResponse) time.Duration {
+	wait := retryWaitMin << attempt
+	if wait > retryWaitMax || wait <= 0 {
+		wait = retryWaitMax
+	}
+
+	if resp == nil {
+		return wait
+	}
+
+	retryAfter := resp.Headers()["Retry-After"]
+	if retryAfter == "" {
+		return wait
+	}
+
+	if seconds, err := strconv.Atoi(retryAfter); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(retryAfter); err == nil {
+		wait = time.Until(at)
+	}
+
+	if wait < 0 {
+		return 0
+	}
+
+	if wait > retryWaitMax {
+		return retryWaitMax
+	}
+
+	return wait
+}
+
 func SetBasicAuthHeaders(headers map[string]string, username, password string) {
 	authStr := fmt.Sprintf("%s:%s", username, password)
 	encodedAuth := base64.StdEncoding.EncodeToString([]byte(authStr))
@@ -111,8 +232,8 @@ func createResponse(resp *http.Response) (ClientResponse, error) {
 		headers[k] = v[0]
 	}
 
-	zap.S().Debugw("http response", "status", resp.Status, "headers", headers)
-	zap.S().Debug(string(body))
+	zap.S().Debugw("http response", "status", resp.Status, "headers", RedactHeaders(headers))
+	zap.S().Debug(RedactBody(string(body)))
 
 	return &netHTTPResponse{
 		code:    resp.StatusCode,
@@ -0,0 +1,250 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_ClientRetries(t *testing.T) {
+	origWaitMin, origWaitMax := retryWaitMin, retryWaitMax
+	t.Cleanup(func() {
+		retryWaitMin, retryWaitMax = origWaitMin, origWaitMax
+	})
+
+	retryWaitMin = time.Millisecond
+	retryWaitMax = 5 * time.Millisecond
+
+	testCases := []struct {
+		name          string
+		method        string
+		failures      int32
+		failureCode   int
+		retries       int
+		expectCode    int
+		expectAttempt int32
+	}{
+		{
+			name:          "get retried on server error",
+			method:        http.MethodGet,
+			failures:      2,
+			failureCode:   http.StatusBadGateway,
+			retries:       3,
+			expectCode:    http.StatusOK,
+			expectAttempt: 3,
+		},
+		{
+			name:          "post retried when rate limited",
+			method:        http.MethodPost,
+			failures:      1,
+			failureCode:   http.StatusTooManyRequests,
+			retries:       3,
+			expectCode:    http.StatusOK,
+			expectAttempt: 2,
+		},
+		{
+			name:          "post not retried on server error",
+			method:        http.MethodPost,
+			failures:      1,
+			failureCode:   http.StatusInternalServerError,
+			retries:       3,
+			expectCode:    http.StatusInternalServerError,
+			expectAttempt: 1,
+		},
+		{
+			name:          "retries exhausted",
+			method:        http.MethodGet,
+			failures:      5,
+			failureCode:   http.StatusServiceUnavailable,
+			retries:       2,
+			expectCode:    http.StatusServiceUnavailable,
+			expectAttempt: 3,
+		},
+		{
+			name:          "client error not retried",
+			method:        http.MethodGet,
+			failures:      1,
+			failureCode:   http.StatusNotFound,
+			retries:       3,
+			expectCode:    http.StatusNotFound,
+			expectAttempt: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&attempts, 1) <= tc.failures {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(tc.failureCode)
+
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			cfg := DefaultClientConfig()
+			cfg.Retries = tc.retries
+
+			client, err := NewHTTPClientWithConfig(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error creating client: %s", err)
+			}
+
+			body := "{}"
+
+			resp, err := client.Do(&ClientRequest{Method: tc.method, URL: server.URL, Body: &body})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if resp.ResponseCode() != tc.expectCode {
+				t.Fatalf("expected status code %d but got %d", tc.expectCode, resp.ResponseCode())
+			}
+
+			if attempts != tc.expectAttempt {
+				t.Fatalf("expected %d attempts but got %d", tc.expectAttempt, attempts)
+			}
+		})
+	}
+}
+
+func Test_ClientCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	bundlePath := filepath.Join(t.TempDir(), "ca.pem")
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	if err := os.WriteFile(bundlePath, bundle, 0600); err != nil {
+		t.Fatalf("writing ca bundle: %s", err)
+	}
+
+	cfg := DefaultClientConfig()
+	cfg.Retries = 0
+
+	untrusted, err := NewHTTPClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error creating client: %s", err)
+	}
+
+	if _, err := untrusted.Get(server.URL, nil); err == nil {
+		t.Fatal("expected certificate error without the ca bundle")
+	}
+
+	cfg.CABundle = bundlePath
+
+	trusted, err := NewHTTPClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error creating client: %s", err)
+	}
+
+	resp, err := trusted.Get(server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error with the ca bundle: %s", err)
+	}
+
+	if resp.ResponseCode() != http.StatusOK {
+		t.Fatalf("expected status code %d but got %d", http.StatusOK, resp.ResponseCode())
+	}
+}
+
+func Test_ClientProxy(t *testing.T) {
+	var proxied int32
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&proxied, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	cfg := DefaultClientConfig()
+	cfg.Proxy = proxy.URL
+
+	client, err := NewHTTPClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error creating client: %s", err)
+	}
+
+	if _, err := client.Get("http://rancher.example.com/v3", nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if proxied != 1 {
+		t.Fatalf("expected request to use the proxy")
+	}
+
+	cfg.NoProxy = "example.com"
+	cfg.Retries = 0
+	cfg.Timeout = time.Second
+
+	client, err = NewHTTPClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error creating client: %s", err)
+	}
+
+	client.Get("http://rancher.example.com/v3", nil) //nolint: errcheck
+
+	if proxied != 1 {
+		t.Fatalf("expected request to bypass the proxy")
+	}
+}
+
+func Test_ClientCertRequiresKey(t *testing.T) {
+	cfg := DefaultClientConfig()
+	cfg.ClientCert = "client.pem"
+
+	if _, err := NewHTTPClientWithConfig(cfg); !errors.Is(err, ErrClientCertAndKey) {
+		t.Fatalf("expected ErrClientCertAndKey but got: %v", err)
+	}
+}
+
+func Test_ConfigForClient(t *testing.T) {
+	cfg := DefaultClientConfig()
+	cfg.Timeout = 5 * time.Second
+	cfg.Proxy = "http://proxy.example.com:3128"
+
+	client, err := NewHTTPClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	clientCfg := ConfigForClient(client)
+	if *clientCfg != *cfg {
+		t.Fatalf("expected config %+v but got %+v", cfg, clientCfg)
+	}
+
+	// changing the returned config mustn't change the clients config
+	clientCfg.InsecureSkipVerify = true
+	if ConfigForClient(client).InsecureSkipVerify {
+		t.Fatal("expected the clients config to be unchanged")
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RedactedValue is the value used in place of redacted values
+const RedactedValue = "[REDACTED]"
+
+// Redaction is a rule used to remove sensitive values from traced requests and responses
+type Redaction struct {
+	// Header is the name of a header whose value will be redacted
+	Header string
+	// Pattern is matched against urls and bodies
+	Pattern *regexp.Regexp
+	// Replacement is used to replace the matches of the pattern. If not set the first
+	// submatch of the pattern is kept and the remainder of the match is redacted.
+	Replacement string
+}
+
+var (
+	redactionsLock sync.RWMutex
+	redactions     = []*Redaction{
+		{Header: "Authorization"},
+		{Header: "Proxy-Authorization"},
+		{Header: "Cookie"},
+		{Header: "Set-Cookie"},
+		{Header: "X-Api-Key"},
+		{Pattern: regexp.MustCompile(`(?i)("(?:password|token|access_token|refresh_token|id_token|client_secret|secret|assertion|sessionToken|SecretAccessKey)"\s*:\s*")[^"]*`)},
+		{Pattern: regexp.MustCompile(`(?i)((?:^|[?&])(?:password|client_secret|access_token|refresh_token|id_token|assertion|SAMLResponse|code|code_verifier)=)[^&\s]*`)},
+		{Pattern: regexp.MustCompile(`(<(?:\w+:)?Password\b[^>]*>)[^<]*`)},
+		{
+			Pattern:     regexp.MustCompile(`(<(?:\w+:)?(?:EncryptedAssertion|Assertion|RequestedSecurityToken|BinarySecurityToken)\b[^>]*>)(?s:.*?)(</(?:\w+:)?(?:EncryptedAssertion|Assertion|RequestedSecurityToken|BinarySecurityToken)>)`),
+			Replacement: "${1}" + RedactedValue + "${2}",
+		},
+	}
+)
+
+// RegisterRedaction adds redaction rules that are applied when tracing. This allows plugins
+// to redact values that are specific to their provider.
+func RegisterRedaction(rules ...*Redaction) {
+	redactionsLock.Lock()
+	defer redactionsLock.Unlock()
+
+	redactions = append(redactions, rules...)
+}
+
+// RedactHeaders returns a copy of the headers with the sensitive values redacted
+func RedactHeaders(headers map[string]string) map[string]string {
+	redactionsLock.RLock()
+	defer redactionsLock.RUnlock()
+
+	redacted := make(map[string]string, len(headers))
+
+	for name, value := range headers {
+		redacted[name] = value
+
+		for _, rule := range redactions {
+			if rule.Header != "" && strings.EqualFold(rule.Header, name) {
+				redacted[name] = RedactedValue
+
+				break
+			}
+		}
+	}
+
+	return redacted
+}
+
+// RedactBody returns the body, or url, with the sensitive values redacted
+func RedactBody(body string) string {
+	redactionsLock.RLock()
+	defer redactionsLock.RUnlock()
+
+	for _, rule := range redactions {
+		if rule.Pattern == nil {
+			continue
+		}
+
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = "${1}" + RedactedValue
+		}
+
+		body = rule.Pattern.ReplaceAllString(body, replacement)
+	}
+
+	return body
+}
+
+func headerMap(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for k, v := range header {
+		headers[k] = strings.Join(v, ", ")
+	}
+
+	return headers
+}
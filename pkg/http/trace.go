@@ -0,0 +1,316 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TraceVerbosity is the logging verbosity at which http tracing is enabled
+const TraceVerbosity = 9
+
+// TraceConfig is the configuration for tracing the http requests
+type TraceConfig struct {
+	// Enabled will log the full requests and responses along with their timing
+	Enabled bool
+	// HARFile is the path of a HAR file to write the requests and responses to
+	HARFile string
+}
+
+var (
+	tracerLock sync.RWMutex
+	tracer     *httpTracer
+)
+
+// ConfigureTracing will configure the tracing for all the http clients. The values
+// are redacted before they are logged or written to the HAR file.
+func ConfigureTracing(cfg *TraceConfig) {
+	tracerLock.Lock()
+	defer tracerLock.Unlock()
+
+	if cfg == nil || (!cfg.Enabled && cfg.HARFile == "") {
+		tracer = nil
+		return
+	}
+
+	tracer = &httpTracer{
+		log:     cfg.Enabled,
+		harFile: cfg.HARFile,
+		har:     newHAR(),
+	}
+}
+
+func getTracer() *httpTracer {
+	tracerLock.RLock()
+	defer tracerLock.RUnlock()
+
+	return tracer
+}
+
+// traceEntry is a completed request and response
+type traceEntry struct {
+	started  time.Time
+	duration time.Duration
+
+	method         string
+	url            string
+	requestHeaders map[string]string
+	requestBody    *string
+
+	status          int
+	statusText      string
+	proto           string
+	responseHeaders map[string]string
+	responseBody    string
+
+	err error
+}
+
+type httpTracer struct {
+	log     bool
+	harFile string
+
+	lock sync.Mutex
+	har  *har
+}
+
+func (t *httpTracer) trace(entry *traceEntry) {
+	requestHeaders := RedactHeaders(entry.requestHeaders)
+	responseHeaders := RedactHeaders(entry.responseHeaders)
+	redactedURL := RedactBody(entry.url)
+
+	requestBody := ""
+	if entry.requestBody != nil {
+		requestBody = RedactBody(*entry.requestBody)
+	}
+
+	responseBody := RedactBody(entry.responseBody)
+
+	if t.log {
+		zap.S().Infow("http trace request", "method", entry.method, "url", redactedURL, "headers", requestHeaders, "body", requestBody)
+
+		if entry.err != nil {
+			zap.S().Infow("http trace error", "method", entry.method, "url", redactedURL, "duration", entry.duration.String(), "error", entry.err.Error())
+		} else {
+			zap.S().Infow("http trace response", "method", entry.method, "url", redactedURL, "status", entry.status, "duration", entry.duration.String(), "headers", responseHeaders, "body", responseBody)
+		}
+	}
+
+	if t.harFile == "" {
+		return
+	}
+
+	harEntry := &harEntry{
+		StartedDateTime: entry.started.Format(time.RFC3339Nano),
+		Time:            durationMillis(entry.duration),
+		Request: harRequest{
+			Method:      entry.method,
+			URL:         redactedURL,
+			HTTPVersion: "HTTP/1.1",
+			Headers:     harHeaders(requestHeaders),
+			QueryString: harQueryString(redactedURL),
+			Cookies:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(requestBody),
+		},
+		Response: harResponse{
+			Status:      entry.status,
+			StatusText:  entry.statusText,
+			HTTPVersion: entry.proto,
+			Headers:     harHeaders(responseHeaders),
+			Cookies:     []harNameValue{},
+			Content: harContent{
+				Size:     len(responseBody),
+				MimeType: entry.responseHeaders["Content-Type"],
+				Text:     responseBody,
+			},
+			HeadersSize: -1,
+			BodySize:    len(responseBody),
+		},
+		Cache: struct{}{},
+		Timings: harTimings{
+			Send:    0,
+			Wait:    durationMillis(entry.duration),
+			Receive: 0,
+		},
+	}
+
+	if entry.requestBody != nil {
+		harEntry.Request.PostData = &harPostData{
+			MimeType: entry.requestHeaders["Content-Type"],
+			Text:     requestBody,
+		}
+	}
+
+	if entry.err != nil {
+		harEntry.Error = entry.err.Error()
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.har.Log.Entries = append(t.har.Log.Entries, harEntry)
+
+	// The file is written after each request so that it's available if kconnect fails
+	if err := t.har.write(t.harFile); err != nil {
+		zap.S().Warnw("failed writing har file", "file", t.harFile, "error", err.Error())
+	}
+}
+
+// har is a HTTP Archive, see http://www.softwareishard.com/blog/har-12-spec/
+type har struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string      `json:"version"`
+	Creator harCreator  `json:"creator"`
+	Entries []*harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	Cookies     []harNameValue `json:"cookies"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Cookies     []harNameValue `json:"cookies"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+func newHAR() *har {
+	return &har{
+		Log: harLog{
+			Version: "1.2",
+			Creator: harCreator{Name: "kconnect", Version: "1.0"},
+			Entries: []*harEntry{},
+		},
+	}
+}
+
+func (h *har) write(path string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling har: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return fmt.Errorf("creating har file directory %s: %w", dir, err)
+		}
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing har file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func harHeaders(headers map[string]string) []harNameValue {
+	values := []harNameValue{}
+	for name, value := range headers {
+		values = append(values, harNameValue{Name: name, Value: value})
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+
+	return values
+}
+
+func harQueryString(rawURL string) []harNameValue {
+	values := []harNameValue{}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return values
+	}
+
+	for name, vals := range parsed.Query() {
+		for _, val := range vals {
+			values = append(values, harNameValue{Name: name, Value: val})
+		}
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+
+	return values
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func Test_RedactBody(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "json password",
+			body:     `{"username":"admin","password":"secret"}`,
+			expected: `{"username":"admin","password":"[REDACTED]"}`,
+		},
+		{
+			name:     "json token",
+			body:     `{"token": "token-abc:secret","userId":"u-1"}`,
+			expected: `{"token": "[REDACTED]","userId":"u-1"}`,
+		},
+		{
+			name:     "form values",
+			body:     "grant_type=password&username=bob&password=secret&client_id=abc",
+			expected: "grant_type=password&username=bob&password=[REDACTED]&client_id=abc",
+		},
+		{
+			name:     "url query",
+			body:     "https://login.example.com/token?code=abc123&state=xyz",
+			expected: "https://login.example.com/token?code=[REDACTED]&state=xyz",
+		},
+		{
+			name:     "ws-trust password",
+			body:     `<o:Username>bob</o:Username><o:Password Type="text">secret</o:Password>`,
+			expected: `<o:Username>bob</o:Username><o:Password Type="text">[REDACTED]</o:Password>`,
+		},
+		{
+			name:     "saml assertion",
+			body:     "<samlp:Response><saml:Assertion ID=\"1\">\n<saml:Subject>bob</saml:Subject>\n</saml:Assertion></samlp:Response>",
+			expected: `<samlp:Response><saml:Assertion ID="1">[REDACTED]</saml:Assertion></samlp:Response>`,
+		},
+		{
+			name:     "nothing sensitive",
+			body:     `{"data":[{"id":"c-1","name":"dev"}]}`,
+			expected: `{"data":[{"id":"c-1","name":"dev"}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := RedactBody(tc.body)
+			if actual != tc.expected {
+				t.Fatalf("expected %s but got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_RedactHeaders(t *testing.T) {
+	headers := map[string]string{
+		"authorization": "Bearer abc",
+		"Content-Type":  MediaTypeJSON,
+	}
+
+	redacted := RedactHeaders(headers)
+
+	if redacted["authorization"] != RedactedValue {
+		t.Fatalf("expected authorization header to be redacted but got %s", redacted["authorization"])
+	}
+
+	if redacted["Content-Type"] != MediaTypeJSON {
+		t.Fatalf("expected content type header to be unchanged but got %s", redacted["Content-Type"])
+	}
+
+	if headers["authorization"] != "Bearer abc" {
+		t.Fatal("expected original headers to be unchanged")
+	}
+}
+
+func Test_RegisterRedaction(t *testing.T) {
+	RegisterRedaction(&Redaction{Pattern: regexp.MustCompile(`(apikey-)[a-z0-9]+`)})
+
+	actual := RedactBody("key is apikey-abc123")
+	if actual != "key is apikey-[REDACTED]" {
+		t.Fatalf("expected registered redaction to be applied but got %s", actual)
+	}
+}
+
+func Test_TraceHARFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", MediaTypeJSON)
+		w.Write([]byte(`{"token":"token-abc:secret"}`)) //nolint: errcheck
+	}))
+	defer server.Close()
+
+	harFile := filepath.Join(t.TempDir(), "trace", "kconnect.har")
+
+	ConfigureTracing(&TraceConfig{HARFile: harFile})
+	defer ConfigureTracing(nil)
+
+	client := NewHTTPClient()
+	headers := map[string]string{"Authorization": "Bearer abc"}
+
+	if _, err := client.Post(server.URL+"/v3-public/localProviders/local?action=login", `{"username":"admin","password":"secret"}`, headers); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := os.ReadFile(harFile)
+	if err != nil {
+		t.Fatalf("reading har file: %s", err)
+	}
+
+	for _, secret := range []string{"Bearer abc", "token-abc:secret", `"secret"`} {
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("expected %s to be redacted from the har file", secret)
+		}
+	}
+
+	archive := &har{}
+	if err := json.Unmarshal(data, archive); err != nil {
+		t.Fatalf("unmarshalling har file: %s", err)
+	}
+
+	if len(archive.Log.Entries) != 1 {
+		t.Fatalf("expected 1 har entry but got %d", len(archive.Log.Entries))
+	}
+
+	entry := archive.Log.Entries[0]
+	if entry.Request.Method != http.MethodPost || entry.Response.Status != http.StatusOK {
+		t.Fatalf("expected POST with status 200 but got %s with %d", entry.Request.Method, entry.Response.Status)
+	}
+
+	if entry.Request.PostData == nil || !strings.Contains(entry.Request.PostData.Text, `"username":"admin"`) {
+		t.Fatal("expected the request body in the har entry")
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	kaws "github.com/fidelity/kconnect/pkg/aws"
+	"github.com/fidelity/kconnect/pkg/provider/discovery"
+)
+
+const (
+	// LabelAccess is the label set on a discovered cluster when its access entries
+	// have been checked. It is one of the Access values below.
+	LabelAccess = "access"
+	// LabelAccessPolicies is the label holding the names of the access policies
+	// associated with the callers access entry, separated by +
+	LabelAccessPolicies = "access-policies"
+
+	// AccessAccessible means the caller has an access entry for the cluster
+	AccessAccessible = "accessible"
+	// AccessNone means the cluster only uses access entries and the caller doesn't have one
+	AccessNone = "none"
+	// AccessUnknown means access couldn't be worked out from the access entries. This is
+	// the case for clusters that use the aws-auth ConfigMap.
+	AccessUnknown = "unknown"
+
+	accessPoliciesSeparator = "+"
+)
+
+// checkAccess labels the cluster with the access the caller has to it based on
+// the EKS access entries.
+func (p *eksClusterProvider) checkAccess(ctx context.Context, eksCluster *types.Cluster, cluster *discovery.Cluster) {
+	access, policies, err := p.clusterAccess(ctx, eksCluster)
+	if err != nil {
+		p.logger.Debugw("unable to check access entries, access is unknown", "cluster", cluster.Name, "error", err)
+		access = AccessUnknown
+	}
+
+	cluster.Labels[LabelAccess] = access
+	if len(policies) > 0 {
+		cluster.Labels[LabelAccessPolicies] = strings.Join(policies, accessPoliciesSeparator)
+	}
+}
+
+func (p *eksClusterProvider) clusterAccess(ctx context.Context, eksCluster *types.Cluster) (string, []string, error) {
+	mode := authenticationMode(eksCluster)
+	if mode == types.AuthenticationModeConfigMap {
+		return AccessUnknown, nil, nil
+	}
+
+	callerARN, err := p.callerARN(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	principalARN, err := p.findAccessEntry(ctx, *eksCluster.Name, callerARN)
+	if err != nil {
+		var invalidErr *types.InvalidRequestException
+		if errors.As(err, &invalidErr) {
+			// Older clusters don't support access entries and only use the aws-auth ConfigMap
+			return AccessUnknown, nil, nil
+		}
+
+		return "", nil, err
+	}
+
+	if principalARN == "" {
+		if mode == types.AuthenticationModeApi {
+			return AccessNone, nil, nil
+		}
+
+		// The caller could still be mapped in the aws-auth ConfigMap
+		return AccessUnknown, nil, nil
+	}
+
+	policies, err := p.listAccessPolicies(ctx, *eksCluster.Name, principalARN)
+	if err != nil {
+		return AccessAccessible, nil, err
+	}
+
+	return AccessAccessible, policies, nil
+}
+
+// callerARN returns the ARN of the caller. The identity only has the ARN when a role
+// was assumed, so otherwise it's got from STS and kept for the other clusters.
+func (p *eksClusterProvider) callerARN(ctx context.Context) (string, error) {
+	if p.principalARN != "" {
+		return p.principalARN, nil
+	}
+
+	if p.identity.PrincipalARN != "" {
+		p.principalARN = p.identity.PrincipalARN
+		return p.principalARN, nil
+	}
+
+	callerARN, err := kaws.GetCallerARN(ctx, *p.awsConfig)
+	if err != nil {
+		return "", err
+	}
+
+	p.principalARN = callerARN
+
+	return p.principalARN, nil
+}
+
+// findAccessEntry returns the principal ARN of the access entry for the caller, or
+// an empty string if there isn't one
+func (p *eksClusterProvider) findAccessEntry(ctx context.Context, clusterName, callerARN string) (string, error) {
+	paginator := eks.NewListAccessEntriesPaginator(p.eksClient, &eks.ListAccessEntriesInput{
+		ClusterName: aws.String(clusterName),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", fmt.Errorf("listing access entries for cluster %s: %w", clusterName, err)
+		}
+
+		for _, entryARN := range page.AccessEntries {
+			if principalMatches(entryARN, callerARN) {
+				return entryARN, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func (p *eksClusterProvider) listAccessPolicies(ctx context.Context, clusterName, principalARN string) ([]string, error) {
+	paginator := eks.NewListAssociatedAccessPoliciesPaginator(p.eksClient, &eks.ListAssociatedAccessPoliciesInput{
+		ClusterName:  aws.String(clusterName),
+		PrincipalArn: aws.String(principalARN),
+	})
+
+	policies := []string{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing associated access policies for cluster %s: %w", clusterName, err)
+		}
+
+		for _, policy := range page.AssociatedAccessPolicies {
+			policies = append(policies, policyName(aws.ToString(policy.PolicyArn)))
+		}
+	}
+
+	return policies, nil
+}
+
+func authenticationMode(eksCluster *types.Cluster) types.AuthenticationMode {
+	if eksCluster.AccessConfig == nil || eksCluster.AccessConfig.AuthenticationMode == "" {
+		return types.AuthenticationModeConfigMap
+	}
+
+	return eksCluster.AccessConfig.AuthenticationMode
+}
+
+// principalMatches checks if the principal of an access entry is the caller. Callers
+// using a role have an assumed role ARN which is matched against the role name and
+// account as the path of the role isn't part of the assumed role ARN.
+func principalMatches(entryARN, principalARN string) bool {
+	if entryARN == principalARN {
+		return true
+	}
+
+	callerARN, err := arn.Parse(principalARN)
+	if err != nil || !strings.HasPrefix(callerARN.Resource, "assumed-role/") {
+		return false
+	}
+
+	entry, err := arn.Parse(entryARN)
+	if err != nil || entry.AccountID != callerARN.AccountID || !strings.HasPrefix(entry.Resource, "role/") {
+		return false
+	}
+
+	roleName := strings.Split(strings.TrimPrefix(callerARN.Resource, "assumed-role/"), "/")[0]
+	entryParts := strings.Split(entry.Resource, "/")
+
+	return entryParts[len(entryParts)-1] == roleName
+}
+
+func policyName(policyARN string) string {
+	parts := strings.Split(policyARN, "/")
+
+	return parts[len(parts)-1]
+}
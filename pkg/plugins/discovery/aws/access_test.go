@@ -0,0 +1,254 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"go.uber.org/zap"
+
+	kaws "github.com/fidelity/kconnect/pkg/aws"
+)
+
+const (
+	testCallerARN = "arn:aws:iam::123456789012:user/bob"
+	testRoleARN   = "arn:aws:iam::123456789012:role/platform/EKSAdmin"
+)
+
+// stubAWS is a stubbed STS and EKS api used for testing the access checks
+type stubAWS struct {
+	callerARN     string
+	accessEntries []string
+	policies      []string
+
+	lock        sync.Mutex
+	callerCalls int
+}
+
+func (s *stubAWS) handler(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/":
+		r.ParseForm() //nolint: errcheck
+		if r.Form.Get("Action") != "GetCallerIdentity" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s.callerCalls++
+		w.Header().Set("Content-Type", "text/xml")
+		fmt.Fprintf(w, `<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <GetCallerIdentityResult>
+    <Arn>%s</Arn>
+    <UserId>AIDATEST</UserId>
+    <Account>123456789012</Account>
+  </GetCallerIdentityResult>
+  <ResponseMetadata><RequestId>1</RequestId></ResponseMetadata>
+</GetCallerIdentityResponse>`, s.callerARN)
+	case strings.HasSuffix(r.URL.Path, "/access-policies"):
+		policies := []map[string]string{}
+		for _, policy := range s.policies {
+			policies = append(policies, map[string]string{"policyArn": "arn:aws:eks::aws:cluster-access-policy/" + policy})
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{"associatedAccessPolicies": policies}) //nolint: errcheck
+	case strings.HasSuffix(r.URL.Path, "/access-entries"):
+		json.NewEncoder(w).Encode(map[string]any{"accessEntries": s.accessEntries}) //nolint: errcheck
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestProvider(t *testing.T, stub *stubAWS, principalARN string) *eksClusterProvider {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(stub.handler))
+	t.Cleanup(server.Close)
+
+	cfg := aws.Config{
+		Region:       "eu-west-2",
+		Credentials:  credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		BaseEndpoint: aws.String(server.URL),
+		HTTPClient:   server.Client(),
+	}
+
+	return &eksClusterProvider{
+		identity:  &kaws.Identity{PrincipalARN: principalARN},
+		awsConfig: &cfg,
+		eksClient: eks.NewFromConfig(cfg),
+		logger:    zap.NewNop().Sugar(),
+	}
+}
+
+func Test_PrincipalMatches(t *testing.T) {
+	testCases := []struct {
+		name         string
+		entryARN     string
+		principalARN string
+		expect       bool
+	}{
+		{
+			name:         "same user",
+			entryARN:     testCallerARN,
+			principalARN: testCallerARN,
+			expect:       true,
+		},
+		{
+			name:         "different user",
+			entryARN:     "arn:aws:iam::123456789012:user/alice",
+			principalARN: testCallerARN,
+			expect:       false,
+		},
+		{
+			name:         "assumed role with role path",
+			entryARN:     testRoleARN,
+			principalARN: "arn:aws:sts::123456789012:assumed-role/EKSAdmin/bob",
+			expect:       true,
+		},
+		{
+			name:         "assumed role in a different account",
+			entryARN:     testRoleARN,
+			principalARN: "arn:aws:sts::000000000000:assumed-role/EKSAdmin/bob",
+			expect:       false,
+		},
+		{
+			name:         "assumed role with a different name",
+			entryARN:     testRoleARN,
+			principalARN: "arn:aws:sts::123456789012:assumed-role/EKSReadOnly/bob",
+			expect:       false,
+		},
+		{
+			name:         "assumed role against a user entry",
+			entryARN:     "arn:aws:iam::123456789012:user/EKSAdmin",
+			principalARN: "arn:aws:sts::123456789012:assumed-role/EKSAdmin/bob",
+			expect:       false,
+		},
+		{
+			name:         "no principal",
+			entryARN:     testCallerARN,
+			principalARN: "",
+			expect:       false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := principalMatches(tc.entryARN, tc.principalARN); actual != tc.expect {
+				t.Fatalf("expected %t but got %t", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func Test_ClusterAccess(t *testing.T) {
+	testCases := []struct {
+		name           string
+		mode           types.AuthenticationMode
+		principalARN   string
+		accessEntries  []string
+		expectAccess   string
+		expectPolicies []string
+		expectSTSCalls int
+	}{
+		{
+			name:           "config map only",
+			mode:           types.AuthenticationModeConfigMap,
+			expectAccess:   AccessUnknown,
+			expectSTSCalls: 0,
+		},
+		{
+			name:           "api mode with caller from sts",
+			mode:           types.AuthenticationModeApi,
+			accessEntries:  []string{"arn:aws:iam::123456789012:user/alice", testCallerARN},
+			expectAccess:   AccessAccessible,
+			expectPolicies: []string{"AmazonEKSAdminPolicy"},
+			expectSTSCalls: 1,
+		},
+		{
+			name:           "api mode with assumed role",
+			mode:           types.AuthenticationModeApi,
+			principalARN:   "arn:aws:sts::123456789012:assumed-role/EKSAdmin/bob",
+			accessEntries:  []string{testRoleARN},
+			expectAccess:   AccessAccessible,
+			expectPolicies: []string{"AmazonEKSAdminPolicy"},
+			expectSTSCalls: 0,
+		},
+		{
+			name:           "api mode without an entry",
+			mode:           types.AuthenticationModeApi,
+			accessEntries:  []string{"arn:aws:iam::123456789012:user/alice"},
+			expectAccess:   AccessNone,
+			expectSTSCalls: 1,
+		},
+		{
+			name:           "api and config map without an entry",
+			mode:           types.AuthenticationModeApiAndConfigMap,
+			accessEntries:  []string{},
+			expectAccess:   AccessUnknown,
+			expectSTSCalls: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &stubAWS{
+				callerARN:     testCallerARN,
+				accessEntries: tc.accessEntries,
+				policies:      []string{"AmazonEKSAdminPolicy"},
+			}
+			p := newTestProvider(t, stub, tc.principalARN)
+
+			eksCluster := &types.Cluster{
+				Name:         aws.String("dev"),
+				AccessConfig: &types.AccessConfigResponse{AuthenticationMode: tc.mode},
+			}
+
+			// the access of each cluster is checked but the caller is only looked up once
+			for range 2 {
+				access, policies, err := p.clusterAccess(context.Background(), eksCluster)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if access != tc.expectAccess {
+					t.Fatalf("expected access %s but got %s", tc.expectAccess, access)
+				}
+
+				if strings.Join(policies, ",") != strings.Join(tc.expectPolicies, ",") {
+					t.Fatalf("expected policies %v but got %v", tc.expectPolicies, policies)
+				}
+			}
+
+			if stub.callerCalls != tc.expectSTSCalls {
+				t.Fatalf("expected %d calls to sts but got %d", tc.expectSTSCalls, stub.callerCalls)
+			}
+		})
+	}
+}
@@ -51,28 +51,7 @@ func (p *eksClusterProvider) GetConfig(ctx context.Context, input *discovery.Get
 		},
 	}
 
-	execConfig := &api.ExecConfig{
-		APIVersion: "client.authentication.k8s.io/v1beta1",
-		Command:    "aws-iam-authenticator",
-		Args: []string{
-			"token",
-			"-i",
-			input.Cluster.Name,
-		},
-		Env: []api.ExecEnvVar{
-			{
-				Name:  "AWS_PROFILE",
-				Value: p.identity.ProfileName,
-			},
-		},
-	}
-
-	if p.identity.AWSSharedCredentialsFile != "" {
-		execConfig.Env = append(execConfig.Env, api.ExecEnvVar{
-			Name:  "AWS_SHARED_CREDENTIALS_FILE",
-			Value: p.identity.AWSSharedCredentialsFile,
-		})
-	}
+	execConfig := p.execConfig(input.Cluster.Name)
 
 	cfg.AuthInfos = map[string]*api.AuthInfo{
 		userName: {
@@ -92,3 +71,50 @@ func (p *eksClusterProvider) GetConfig(ctx context.Context, input *discovery.Get
 		ContextName: &contextName,
 	}, nil
 }
+
+// execConfig creates the exec config that kubectl uses to get a token for the cluster
+// with the command for the login type
+func (p *eksClusterProvider) execConfig(clusterName string) *api.ExecConfig {
+	region := p.identity.Region
+	if p.config != nil && p.config.Region != nil && *p.config.Region != "" {
+		region = *p.config.Region
+	}
+
+	execConfig := &api.ExecConfig{
+		APIVersion: "client.authentication.k8s.io/v1beta1",
+		Env: []api.ExecEnvVar{
+			{
+				Name:  "AWS_PROFILE",
+				Value: p.identity.ProfileName,
+			},
+		},
+	}
+
+	switch p.loginType {
+	case LoginTypeAWSCli:
+		execConfig.Command = "aws"
+		execConfig.Args = []string{"--region", region, "eks", "get-token", "--cluster-name", clusterName, "--output", "json"}
+	case LoginTypeKconnect:
+		execConfig.Command = "kconnect"
+		execConfig.Args = []string{"aws", "token", "--cluster-name", clusterName, "--region", region, "--no-version-check"}
+	default:
+		execConfig.Command = "aws-iam-authenticator"
+		execConfig.Args = []string{"token", "-i", clusterName}
+	}
+
+	if region != "" {
+		execConfig.Env = append(execConfig.Env, api.ExecEnvVar{
+			Name:  "AWS_REGION",
+			Value: region,
+		})
+	}
+
+	if p.identity.AWSSharedCredentialsFile != "" {
+		execConfig.Env = append(execConfig.Env, api.ExecEnvVar{
+			Name:  "AWS_SHARED_CREDENTIALS_FILE",
+			Value: p.identity.AWSSharedCredentialsFile,
+		})
+	}
+
+	return execConfig
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	kaws "github.com/fidelity/kconnect/pkg/aws"
+)
+
+func Test_ResolveLoginType(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     string
+		expect    LoginType
+		expectErr error
+	}{
+		{
+			name:   "default",
+			value:  "",
+			expect: LoginTypeAWSIAMAuthenticator,
+		},
+		{
+			name:   "aws cli",
+			value:  "aws-cli",
+			expect: LoginTypeAWSCli,
+		},
+		{
+			name:   "kconnect",
+			value:  "kconnect",
+			expect: LoginTypeKconnect,
+		},
+		{
+			name:      "unknown",
+			value:     "gcloud",
+			expectErr: ErrUnknownLoginType,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := resolveLoginType(tc.value)
+			if tc.expectErr != nil {
+				if !errors.Is(err, tc.expectErr) {
+					t.Fatalf("expected error %v but got %v", tc.expectErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if actual != tc.expect {
+				t.Fatalf("expected login type %s but got %s", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func Test_ExecConfig(t *testing.T) {
+	testCases := []struct {
+		name            string
+		loginType       LoginType
+		region          string
+		credentialsFile string
+		expectCommand   string
+		expectArgs      string
+		expectEnv       string
+	}{
+		{
+			name:          "aws-iam-authenticator",
+			loginType:     LoginTypeAWSIAMAuthenticator,
+			expectCommand: "aws-iam-authenticator",
+			expectArgs:    "token -i dev",
+			expectEnv:     "AWS_PROFILE=kconnect-dev,AWS_REGION=eu-west-2",
+		},
+		{
+			name:          "aws cli",
+			loginType:     LoginTypeAWSCli,
+			expectCommand: "aws",
+			expectArgs:    "--region eu-west-2 eks get-token --cluster-name dev --output json",
+			expectEnv:     "AWS_PROFILE=kconnect-dev,AWS_REGION=eu-west-2",
+		},
+		{
+			name:            "kconnect with region and credentials file",
+			loginType:       LoginTypeKconnect,
+			region:          "us-east-1",
+			credentialsFile: "/tmp/credentials",
+			expectCommand:   "kconnect",
+			expectArgs:      "aws token --cluster-name dev --region us-east-1 --no-version-check",
+			expectEnv:       "AWS_PROFILE=kconnect-dev,AWS_REGION=us-east-1,AWS_SHARED_CREDENTIALS_FILE=/tmp/credentials",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &eksClusterProvider{
+				config: &eksClusterProviderConfig{Region: &tc.region},
+				identity: &kaws.Identity{
+					ProfileName:              "kconnect-dev",
+					Region:                   "eu-west-2",
+					AWSSharedCredentialsFile: tc.credentialsFile,
+				},
+				loginType: tc.loginType,
+			}
+
+			execConfig := p.execConfig("dev")
+
+			if execConfig.Command != tc.expectCommand {
+				t.Fatalf("expected command %s but got %s", tc.expectCommand, execConfig.Command)
+			}
+
+			if args := strings.Join(execConfig.Args, " "); args != tc.expectArgs {
+				t.Fatalf("expected args %s but got %s", tc.expectArgs, args)
+			}
+
+			if env := execEnv(execConfig); env != tc.expectEnv {
+				t.Fatalf("expected env %s but got %s", tc.expectEnv, env)
+			}
+		})
+	}
+}
+
+func execEnv(execConfig *api.ExecConfig) string {
+	env := []string{}
+	for _, envVar := range execConfig.Env {
+		env = append(env, envVar.Name+"="+envVar.Value)
+	}
+
+	return strings.Join(env, ",")
+}
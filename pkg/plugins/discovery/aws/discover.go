@@ -21,7 +21,9 @@ import (
 	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
 
 	"github.com/fidelity/kconnect/pkg/provider/discovery"
 )
@@ -49,10 +51,25 @@ func (p *eksClusterProvider) Discover(ctx context.Context, input *discovery.Disc
 		return discoverOutput, nil
 	}
 
+	checkAccess := p.config.CheckAccess || p.config.AccessibleOnly
+
 	for _, clusterName := range clusters {
-		clusterDetail, err := p.getClusterConfig(clusterName)
+		eksCluster, err := p.describeCluster(clusterName)
 		if err != nil {
-			return nil, fmt.Errorf("getting cluster config: %w", err)
+			p.logger.Debugw("failed getting cluster config", "cluster", clusterName, "error", err)
+			discoverOutput.AddWarning(discovery.WarningScopeCluster, clusterName, err)
+
+			continue
+		}
+
+		clusterDetail := clusterFromEKS(eksCluster)
+		if checkAccess {
+			p.checkAccess(ctx, eksCluster, clusterDetail)
+		}
+
+		if p.config.AccessibleOnly && clusterDetail.Labels[LabelAccess] == AccessNone {
+			p.logger.Debugw("skipping cluster as there is no access entry for the caller", "cluster", clusterDetail.Name)
+			continue
 		}
 
 		discoverOutput.Clusters[clusterDetail.ID] = clusterDetail
@@ -80,6 +97,15 @@ func (p *eksClusterProvider) listClusters() ([]string, error) {
 }
 
 func (p *eksClusterProvider) getClusterConfig(clusterName string) (*discovery.Cluster, error) {
+	eksCluster, err := p.describeCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	return clusterFromEKS(eksCluster), nil
+}
+
+func (p *eksClusterProvider) describeCluster(clusterName string) (*types.Cluster, error) {
 	input := &eks.DescribeClusterInput{
 		Name: aws.String(clusterName),
 	}
@@ -89,10 +115,34 @@ func (p *eksClusterProvider) getClusterConfig(clusterName string) (*discovery.Cl
 		return nil, fmt.Errorf("describing cluster %s: %w", clusterName, err)
 	}
 
-	return &discovery.Cluster{
-		ID:                       *output.Cluster.Arn,
-		Name:                     *output.Cluster.Name,
-		ControlPlaneEndpoint:     output.Cluster.Endpoint,
-		CertificateAuthorityData: output.Cluster.CertificateAuthority.Data,
-	}, nil
+	return output.Cluster, nil
+}
+
+// clusterFromEKS will convert the EKS cluster details into a discovered cluster. The tags
+// of the cluster are used as the labels along with the region and account.
+func clusterFromEKS(eksCluster *types.Cluster) *discovery.Cluster {
+	cluster := &discovery.Cluster{
+		ID:                       *eksCluster.Arn,
+		Name:                     *eksCluster.Name,
+		ControlPlaneEndpoint:     eksCluster.Endpoint,
+		CertificateAuthorityData: eksCluster.CertificateAuthority.Data,
+		Version:                  eksCluster.Version,
+		Labels:                   make(map[string]string),
+	}
+
+	if eksCluster.Status != "" {
+		status := string(eksCluster.Status)
+		cluster.Status = &status
+	}
+
+	for k, v := range eksCluster.Tags {
+		cluster.Labels[k] = v
+	}
+
+	if clusterARN, err := arn.Parse(*eksCluster.Arn); err == nil {
+		cluster.Labels[discovery.LabelRegion] = clusterARN.Region
+		cluster.Labels[discovery.LabelAccount] = clusterARN.AccountID
+	}
+
+	return cluster
 }
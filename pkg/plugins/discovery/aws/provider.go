@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
 	"go.uber.org/zap"
 
@@ -35,6 +36,11 @@ import (
 
 const (
 	ProviderName = "eks"
+
+	LoginTypeConfigItem      = "login-type"
+	CheckAccessConfigItem    = "check-access"
+	AccessibleOnlyConfigItem = "accessible-only"
+
 	UsageExample = `
   # Discover EKS clusters using SAML
   {{.CommandPath}} use eks --idp-protocol saml
@@ -42,6 +48,24 @@ const (
   # Discover EKS clusters using SAML with a specific role
   {{.CommandPath}} use eks --idp-protocol saml --role-arn arn:aws:iam::000000000000:role/KubernetesAdmin
 
+  # Discover EKS clusters using SAML, logging in to the IdP again instead of reusing the last SAML assertion
+  {{.CommandPath}} use eks --idp-protocol saml --fresh-login
+
+  # Discover EKS clusters using SAML with Okta push MFA and 8 hour AWS credentials
+  {{.CommandPath}} use eks --idp-protocol saml --idp-provider Okta --mfa-push-only --aws-session-duration 8h
+
+  # Discover EKS clusters using an AWS profile and a chain of roles with MFA
+  {{.CommandPath}} use eks --idp-protocol aws-iam --profile dev --assume-role-arn arn:aws:iam::000000000000:role/Jump,arn:aws:iam::111111111111:role/EKSAdmin --mfa-serial arn:aws:iam::000000000000:mfa/user
+
+  # Discover EKS clusters in CI using a web identity token
+  {{.CommandPath}} use eks --idp-protocol aws-iam --web-identity-role-arn arn:aws:iam::000000000000:role/CI --web-identity-token-file /var/run/secrets/token
+
+  # Discover EKS clusters and use the aws cli to get the token for kubectl
+  {{.CommandPath}} use eks --idp-protocol saml --login-type aws-cli
+
+  # Discover only the EKS clusters the role has an access entry for
+  {{.CommandPath}} use eks --idp-protocol saml --role-arn arn:aws:iam::000000000000:role/KubernetesAdmin --accessible-only
+
   # Discover an EKS cluster and add an alias to its connection history entry
   {{.CommandPath}} use eks --alias mycluster
   `
@@ -72,19 +96,27 @@ func New(input *provider.PluginCreationInput) (discovery.Provider, error) {
 type eksClusterProviderConfig struct {
 	common.ClusterProviderConfig
 
-	AssumeRoleARN *string `json:"assume-role-arn"`
-	Region        *string `json:"region"`
-	RegionFilter  *string `json:"region-filter"`
-	RoleArn       *string `json:"role-arn"`
-	RoleFilter    *string `json:"role-filter"`
+	AssumeRoleARN  *string `json:"assume-role-arn"`
+	Region         *string `json:"region"`
+	RegionFilter   *string `json:"region-filter"`
+	RoleArn        *string `json:"role-arn"`
+	RoleFilter     *string `json:"role-filter"`
+	CheckAccess    bool    `json:"check-access"`
+	AccessibleOnly bool    `json:"accessible-only"`
 }
 
 // EKSClusterProvider will discover EKS clusters in AWS
 type eksClusterProvider struct {
 	config    *eksClusterProviderConfig
 	identity  *aws.Identity
+	awsConfig *awssdk.Config
 	eksClient *eks.Client
 
+	// principalARN is the ARN of the caller used to find its access entries
+	principalARN string
+
+	loginType LoginType
+
 	interactive bool
 	logger      *zap.SugaredLogger
 }
@@ -99,7 +131,14 @@ func (p *eksClusterProvider) ListPreReqs() []*provider.PreReq {
 }
 
 func (p *eksClusterProvider) CheckPreReqs() error {
-	return utils.CheckAWSIAMAuthPrereq()
+	switch p.loginType {
+	case LoginTypeAWSCli:
+		return utils.CheckAWSCliPrereq()
+	case LoginTypeKconnect:
+		return utils.CheckKconnectPrereq()
+	default:
+		return utils.CheckAWSIAMAuthPrereq()
+	}
 }
 
 // ConfigurationItems returns the configuration items for this provider
@@ -107,10 +146,13 @@ func ConfigurationItems(scopeTo string) (config.ConfigurationSet, error) {
 	cs := aws.SharedConfig()
 
 	cs.String("aws-shared-credentials-file", os.Getenv("AWS_SHARED_CREDENTIALS_FILE"), "Location to store AWS credentials file")
-	cs.String("assume-role-arn", "", "ARN of the AWS role to be assumed")
-	cs.String("region-filter", "", "A regex filter to apply to the AWS regions list, e.g. '^us-|^eu-' will only show US and eu regions") //nolint: errcheck
-	cs.String("role-arn", "", "ARN of the AWS role to be logged in with")                                                                //nolint: errcheck
-	cs.String("role-filter", "", "A filter to apply to the roles list, e.g. 'EKS' will only show roles that contain EKS in the name")    //nolint: errcheck
+	cs.String("assume-role-arn", "", "ARN of the AWS role to be assumed. Use a comma separated list to assume a chain of roles")
+	cs.String("region-filter", "", "A regex filter to apply to the AWS regions list, e.g. '^us-|^eu-' will only show US and eu regions")                                                            //nolint: errcheck
+	cs.String("role-arn", "", "ARN of the AWS role to be logged in with")                                                                                                                           //nolint: errcheck
+	cs.String("role-filter", "", "A filter to apply to the roles list, e.g. 'EKS' will only show roles that contain EKS in the name")                                                               //nolint: errcheck
+	cs.String(LoginTypeConfigItem, "", "The command kubectl uses to get a token for the cluster. Possible values: aws-iam-authenticator,aws-cli,kconnect. Defaults to aws-iam-authenticator")       //nolint: errcheck
+	cs.Bool(CheckAccessConfigItem, false, "Check the EKS access entries of each cluster and label it with the access the role has and its access policies")                                         //nolint: errcheck
+	cs.Bool(AccessibleOnlyConfigItem, false, "Only discover the clusters the role has an access entry for. Clusters using the aws-auth ConfigMap are always discovered as access can't be checked") //nolint: errcheck
 
 	return cs, nil
 }
@@ -137,6 +179,7 @@ func (p *eksClusterProvider) setup(cs config.ConfigurationSet, userID identity.I
 		return fmt.Errorf("creating aws session: %w", err)
 	}
 
+	p.awsConfig = sess
 	p.eksClient = aws.NewEKSClient(*sess)
 
 	return nil
@@ -43,5 +43,28 @@ func (p *eksClusterProvider) Validate(cfg config.ConfigurationSet) error {
 // Resolve will resolve the values for the AWS specific flags that have no value. It will
 // query AWS and interactively ask the user for selections.
 func (p *eksClusterProvider) Resolve(config config.ConfigurationSet, userID identity.Identity) error {
+	loginType, err := resolveLoginType(config.ValueString(LoginTypeConfigItem))
+	if err != nil {
+		return err
+	}
+
+	p.loginType = loginType
+
 	return nil
 }
+
+// resolveLoginType returns the login type for the value of the login-type config item,
+// aws-iam-authenticator is used if there isn't a value
+func resolveLoginType(value string) (LoginType, error) {
+	if value == "" {
+		return LoginTypeAWSIAMAuthenticator, nil
+	}
+
+	for _, loginType := range LoginTypes {
+		if LoginType(value) == loginType {
+			return loginType, nil
+		}
+	}
+
+	return "", fmt.Errorf("login-type %s: %w", value, ErrUnknownLoginType)
+}
@@ -0,0 +1,36 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+// LoginType is the mechanism that kubectl uses to get a token for an EKS cluster
+type LoginType string
+
+var (
+	// LoginTypeAWSIAMAuthenticator uses aws-iam-authenticator token
+	LoginTypeAWSIAMAuthenticator = LoginType("aws-iam-authenticator")
+	// LoginTypeAWSCli uses aws eks get-token
+	LoginTypeAWSCli = LoginType("aws-cli")
+	// LoginTypeKconnect uses kconnect aws token
+	LoginTypeKconnect = LoginType("kconnect")
+
+	// LoginTypes is the list of supported login types
+	LoginTypes = []LoginType{
+		LoginTypeAWSIAMAuthenticator,
+		LoginTypeAWSCli,
+		LoginTypeKconnect,
+	}
+)
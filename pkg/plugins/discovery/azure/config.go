@@ -44,12 +44,19 @@ func (p *aksClusterProvider) GetConfig(ctx context.Context, input *discovery.Get
 	}
 
 	if !p.config.Admin {
-		if p.config.LoginType == LoginTypeToken {
-			p.config.LoginType = LoginTypeAzureCli
+		// GetConfig can be called concurrently so the config isn't changed
+		loginType := p.config.LoginType
+		if loginType == LoginTypeToken {
+			loginType = LoginTypeAzureCli
 		}
 
-		p.addKubelogin(cfg)
-		p.printLoginDetails()
+		tenantID := p.config.TenantID
+		if clusterTenantID := input.Cluster.Labels[discovery.LabelTenant]; clusterTenantID != "" {
+			tenantID = clusterTenantID
+		}
+
+		p.addKubelogin(cfg, tenantID, loginType)
+		p.printLoginDetails(loginType)
 	}
 
 	if input.Namespace != nil && *input.Namespace != "" {
@@ -63,12 +70,12 @@ func (p *aksClusterProvider) GetConfig(ctx context.Context, input *discovery.Get
 	}, nil
 }
 
-func (p *aksClusterProvider) printLoginDetails() {
-	if p.config.LoginType == LoginTypeResourceOwnerPassword {
+func (p *aksClusterProvider) printLoginDetails(loginType LoginType) {
+	if loginType == LoginTypeResourceOwnerPassword {
 		fmt.Fprintf(os.Stderr, "\033[33mSet the AAD_USER_PRINCIPAL_NAME and AAD_USER_PRINCIPAL_PASSWORD environment variables before running kubectl\033[0m\n")
 	}
 
-	if p.config.LoginType == LoginTypeServicePrincipal {
+	if loginType == LoginTypeServicePrincipal {
 		fmt.Fprintf(os.Stderr, "\033[33mSet the AAD_SERVICE_PRINCIPAL_CLIENT_ID and AAD_SERVICE_PRINCIPAL_CLIENT_SECRET environment variables before running kubectl\033[0m\n")
 	}
 
@@ -77,7 +84,7 @@ func (p *aksClusterProvider) printLoginDetails() {
 	}
 }
 
-func (p *aksClusterProvider) addKubelogin(cfg *api.Config) {
+func (p *aksClusterProvider) addKubelogin(cfg *api.Config, tenantID string, loginType LoginType) {
 	contextName := cfg.CurrentContext
 	context := cfg.Contexts[contextName]
 	userName := context.AuthInfo
@@ -94,9 +101,9 @@ func (p *aksClusterProvider) addKubelogin(cfg *api.Config) {
 			"--client-id",
 			p.config.ClientID,
 			"--tenant-id",
-			p.config.TenantID,
+			tenantID,
 			"--login",
-			string(p.config.LoginType),
+			string(loginType),
 		},
 	}
 
@@ -113,7 +120,12 @@ func (p *aksClusterProvider) getKubeconfig(ctx context.Context, cluster *discove
 		return nil, fmt.Errorf("parsing cluster id: %w", err)
 	}
 
-	client := azclient.NewContainerClient(resourceID.SubscriptionID, p.authorizer)
+	authorizer, err := p.authorizerForSubscription(ctx, resourceID.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	client := azclient.NewContainerClient(resourceID.SubscriptionID, authorizer)
 
 	var credentialList containerservice.CredentialResults
 	if p.config.Admin {
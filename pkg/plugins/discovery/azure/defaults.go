@@ -28,4 +28,13 @@ const (
 	LoginTypeConfigItem        = "login-type"
 	AzureEnvironmentConfigItem = "azure-env"
 	ServerFqdnTypeConfigItem   = "server-fqdn-type"
+
+	AllSubscriptionsConfigItem   = "all-subscriptions"
+	SubscriptionFilterConfigItem = "subscription-filter"
+	TenantIDsConfigItem          = "tenant-ids"
+
+	TagsConfigItem              = "tags"
+	LocationConfigItem          = "location"
+	ClusterNameFilterConfigItem = "cluster-name-filter"
+	SucceededOnlyConfigItem     = "succeeded-only"
 )
@@ -20,14 +20,28 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"regexp"
+	"strings"
+	"sync"
 
 	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2022-03-01/containerservice"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-11-01/subscriptions"
+	"github.com/Azure/go-autorest/autorest"
+	"k8s.io/apimachinery/pkg/labels"
 
 	azclient "github.com/fidelity/kconnect/pkg/azure/client"
 	"github.com/fidelity/kconnect/pkg/azure/id"
 	"github.com/fidelity/kconnect/pkg/provider/discovery"
 )
 
+const (
+	powerStateLabel = "power-state"
+
+	maxConcurrentSubscriptions = 10
+
+	provisioningStateSucceeded = "Succeeded"
+)
+
 func (p *aksClusterProvider) Discover(ctx context.Context, input *discovery.DiscoverInput) (*discovery.DiscoverOutput, error) {
 	if err := p.setup(input.ConfigSet, input.Identity); err != nil {
 		return nil, fmt.Errorf("setting up aks provider: %w", err)
@@ -35,74 +49,405 @@ func (p *aksClusterProvider) Discover(ctx context.Context, input *discovery.Disc
 
 	p.logger.Info("discovering AKS clusters")
 
-	clusters, err := p.listClusters(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("listing clusters: %w", err)
-	}
-
 	discoverOutput := &discovery.DiscoverOutput{
 		DiscoveryProvider: ProviderName,
 		IdentityProvider:  input.Identity.IdentityProviderName(),
 		Clusters:          make(map[string]*discovery.Cluster),
 	}
-	for _, v := range clusters {
-		discoverOutput.Clusters[v.ID] = v
+
+	if err := p.listClusters(ctx, discoverOutput); err != nil {
+		return nil, fmt.Errorf("listing clusters: %w", err)
 	}
 
 	return discoverOutput, nil
 }
 
-func (p *aksClusterProvider) listClusters(ctx context.Context) ([]*discovery.Cluster, error) {
-	p.logger.Debugw("listing clusters", "subscription", *p.config.SubscriptionID)
-	client := azclient.NewContainerClient(*p.config.SubscriptionID, p.authorizer)
+// subscriptionTarget is a subscription that clusters are discovered in
+type subscriptionTarget struct {
+	subscriptionID string
+	tenantID       string
+	authorizer     autorest.Authorizer
+}
+
+type subscriptionClusters struct {
+	clusters []*discovery.Cluster
+	warnings []*discovery.Warning
+	err      error
+}
+
+// listClusters will list the clusters in the subscriptions concurrently and add them to
+// the output. A subscription that can't be listed is added as a warning unless none
+// of the subscriptions could be listed.
+func (p *aksClusterProvider) listClusters(ctx context.Context, discoverOutput *discovery.DiscoverOutput) error {
+	filter, err := p.newClusterFilter()
+	if err != nil {
+		return err
+	}
+
+	targets, warnings, err := p.subscriptionTargets(ctx)
+	if err != nil {
+		return err
+	}
+
+	discoverOutput.Warnings = append(discoverOutput.Warnings, warnings...)
+
+	results := make([]*subscriptionClusters, len(targets))
+	limit := make(chan struct{}, maxConcurrentSubscriptions)
+
+	var wg sync.WaitGroup
+
+	for i, target := range targets {
+		wg.Add(1)
+
+		go func(i int, target *subscriptionTarget) {
+			defer wg.Done()
+
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			clusters, warnings, err := p.listSubscriptionClusters(ctx, target, filter)
+			results[i] = &subscriptionClusters{clusters: clusters, warnings: warnings, err: err}
+		}(i, target)
+	}
+
+	wg.Wait()
+
+	failed := 0
+
+	for i, result := range results {
+		if result.err != nil {
+			err := fmt.Errorf("listing clusters in subscription %s: %w", targets[i].subscriptionID, result.err)
+			if len(targets) == 1 {
+				return err
+			}
+
+			p.logger.Debugw("failed listing clusters", "subscription", targets[i].subscriptionID, "error", result.err)
+			discoverOutput.AddWarning(discovery.WarningScopeSubscription, targets[i].subscriptionID, result.err)
+			failed++
+
+			continue
+		}
+
+		for _, cluster := range result.clusters {
+			discoverOutput.Clusters[cluster.ID] = cluster
+		}
+
+		discoverOutput.Warnings = append(discoverOutput.Warnings, result.warnings...)
+	}
+
+	if failed == len(targets) {
+		return fmt.Errorf("listing clusters in %d subscriptions: %w", failed, discovery.WarningsError(discoverOutput.Warnings))
+	}
+
+	return nil
+}
+
+// listSubscriptionClusters will list the clusters in a subscription. All the pages of results are read.
+// A cluster that can't be converted is returned as a warning.
+func (p *aksClusterProvider) listSubscriptionClusters(ctx context.Context, target *subscriptionTarget, filter *clusterFilter) ([]*discovery.Cluster, []*discovery.Warning, error) {
+	p.logger.Debugw("listing clusters", "subscription", target.subscriptionID)
+	client := azclient.NewContainerClient(target.subscriptionID, target.authorizer)
 
 	clusters := []*discovery.Cluster{}
+	warnings := []*discovery.Warning{}
 
-	var list containerservice.ManagedClusterListResultPage
+	var list containerservice.ManagedClusterListResultIterator
 
 	var err error
 
 	if p.config.ResourceGroup == nil || *p.config.ResourceGroup == "" {
-		list, err = client.List(ctx)
+		list, err = client.ListComplete(ctx)
 	} else {
-		list, err = client.ListByResourceGroup(ctx, *p.config.ResourceGroup)
+		list, err = client.ListByResourceGroupComplete(ctx, *p.config.ResourceGroup)
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("querying for AKS clusters: %w", err)
+		return nil, nil, fmt.Errorf("querying for AKS clusters: %w", err)
 	}
 
-	for _, val := range list.Values() {
-		if p.config.ClusterName == "" || p.config.ClusterName == *val.Name {
-			clusterID, err := id.ToClusterID(*val.ID)
+	for list.NotDone() {
+		val := list.Value()
+		if filter.matches(&val) {
+			cluster, err := clusterFromManagedCluster(&val)
 			if err != nil {
-				return nil, fmt.Errorf("create cluster id: %w", err)
+				warnings = append(warnings, discovery.NewWarning(discovery.WarningScopeCluster, managedClusterID(&val), err))
+			} else {
+				if target.tenantID != "" {
+					cluster.Labels[discovery.LabelTenant] = target.tenantID
+				}
+
+				clusters = append(clusters, cluster)
 			}
+		}
+
+		if err := list.NextWithContext(ctx); err != nil {
+			if len(clusters) == 0 && len(warnings) == 0 {
+				return nil, nil, fmt.Errorf("querying for AKS clusters: %w", err)
+			}
+
+			// Keep the clusters from the pages that have been read
+			warnings = append(warnings, discovery.NewWarning(discovery.WarningScopeSubscription, target.subscriptionID, fmt.Errorf("querying for AKS clusters: %w", err)))
+
+			break
+		}
+	}
+
+	return clusters, warnings, nil
+}
+
+// clusterFilter is used to filter the AKS clusters as they are listed
+type clusterFilter struct {
+	name          string
+	nameRegex     *regexp.Regexp
+	tags          labels.Selector
+	location      string
+	succeededOnly bool
+}
+
+func (p *aksClusterProvider) newClusterFilter() (*clusterFilter, error) {
+	nameRegex, err := regexp.Compile(p.config.ClusterNameFilter)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ClusterNameFilterConfigItem, err)
+	}
+
+	tags, err := labels.Parse(p.config.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s %s: %w", TagsConfigItem, p.config.Tags, err)
+	}
+
+	return &clusterFilter{
+		name:          p.config.ClusterName,
+		nameRegex:     nameRegex,
+		tags:          tags,
+		location:      normalizeLocation(p.config.Location),
+		succeededOnly: p.config.SucceededOnly,
+	}, nil
+}
+
+func (f *clusterFilter) matches(val *containerservice.ManagedCluster) bool {
+	if val.Name == nil {
+		return false
+	}
+
+	if f.name != "" && f.name != *val.Name {
+		return false
+	}
+
+	if !f.nameRegex.MatchString(*val.Name) {
+		return false
+	}
+
+	if f.location != "" && (val.Location == nil || normalizeLocation(*val.Location) != f.location) {
+		return false
+	}
+
+	if f.succeededOnly && (val.ManagedClusterProperties == nil || val.ProvisioningState == nil || *val.ProvisioningState != provisioningStateSucceeded) {
+		return false
+	}
+
+	tags := labels.Set{}
+	for k, v := range val.Tags {
+		if v != nil {
+			tags[k] = *v
+		}
+	}
+
+	return f.tags.Matches(tags)
+}
+
+// managedClusterID returns the resource id of the cluster, or its name if it has no id
+func managedClusterID(val *containerservice.ManagedCluster) string {
+	if val.ID != nil {
+		return *val.ID
+	}
+
+	return *val.Name
+}
+
+// normalizeLocation will convert a location display name (e.g. West Europe) to its name (e.g. westeurope)
+func normalizeLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
+
+// subscriptionTargets returns the subscriptions to discover clusters in. This is the chosen subscription
+// unless discovering in all subscriptions or in other tenants. When discovering in more than one tenant
+// a tenant whose subscriptions can't be listed is returned as a warning.
+func (p *aksClusterProvider) subscriptionTargets(ctx context.Context) ([]*subscriptionTarget, []*discovery.Warning, error) {
+	if !p.config.AllSubscriptions && p.config.TenantIDs == "" {
+		if p.config.SubscriptionID == nil || *p.config.SubscriptionID == "" {
+			return nil, nil, ErrSubscriptionRequired
+		}
+
+		return []*subscriptionTarget{{
+			subscriptionID: *p.config.SubscriptionID,
+			tenantID:       p.config.TenantID,
+			authorizer:     p.authorizer,
+		}}, nil, nil
+	}
+
+	filter, err := regexp.Compile(p.config.SubscriptionFilter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing %s: %w", SubscriptionFilterConfigItem, err)
+	}
+
+	tenantIDs := []string{""}
+	if p.config.TenantIDs != "" {
+		tenantIDs = strings.Split(p.config.TenantIDs, ",")
+	}
+
+	targets := []*subscriptionTarget{}
+	warnings := []*discovery.Warning{}
 
-			cluster := &discovery.Cluster{
-				Name: *val.Name,
-				ID:   clusterID,
+	for _, tenantID := range tenantIDs {
+		tenantID = strings.TrimSpace(tenantID)
+
+		authorizer, subs, err := p.tenantSubscriptions(ctx, tenantID)
+		if err != nil {
+			if len(tenantIDs) == 1 {
+				return nil, nil, err
 			}
 
-			controlPlaneEndpoint := ""
+			p.logger.Debugw("failed listing subscriptions", "tenant", tenantID, "error", err)
+			warnings = append(warnings, discovery.NewWarning(discovery.WarningScopeTenant, tenantID, err))
+
+			continue
+		}
+
+		for _, sub := range subs {
+			if sub.State == subscriptions.Disabled || sub.State == subscriptions.Deleted {
+				continue
+			}
 
-			if val.Fqdn != nil {
-				url := net.JoinHostPort(*val.Fqdn, "443")
-				controlPlaneEndpoint = fmt.Sprintf("https://%s", url)
+			subTenantID := tenantID
+			if sub.TenantID != nil {
+				subTenantID = *sub.TenantID
 			}
 
-			if val.PrivateFQDN != nil {
-				url := net.JoinHostPort(*val.PrivateFQDN, "443")
-				controlPlaneEndpoint = fmt.Sprintf("https://%s", url)
+			if tenantID != "" && subTenantID != tenantID {
+				continue
 			}
 
-			if controlPlaneEndpoint != "" {
-				cluster.ControlPlaneEndpoint = &controlPlaneEndpoint
+			if !filter.MatchString(*sub.SubscriptionID) && !filter.MatchString(*sub.DisplayName) {
+				continue
 			}
 
-			clusters = append(clusters, cluster)
+			p.lock.Lock()
+			p.subscriptionTenants[*sub.SubscriptionID] = subTenantID
+			p.lock.Unlock()
+
+			targets = append(targets, &subscriptionTarget{
+				subscriptionID: *sub.SubscriptionID,
+				tenantID:       subTenantID,
+				authorizer:     authorizer,
+			})
 		}
 	}
 
-	return clusters, nil
+	if len(targets) == 0 {
+		if len(warnings) > 0 {
+			return nil, nil, fmt.Errorf("%w: %w", ErrNoSubscriptions, discovery.WarningsError(warnings))
+		}
+
+		return nil, nil, ErrNoSubscriptions
+	}
+
+	return targets, warnings, nil
+}
+
+// tenantSubscriptions returns the authorizer for the tenant and the subscriptions in it
+func (p *aksClusterProvider) tenantSubscriptions(ctx context.Context, tenantID string) (autorest.Authorizer, []subscriptions.Subscription, error) {
+	authorizer, err := p.authorizerForTenant(tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subs, err := listSubscriptions(ctx, authorizer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return authorizer, subs, nil
+}
+
+// listSubscriptions will list all the subscriptions that the user has access to
+func listSubscriptions(ctx context.Context, authorizer autorest.Authorizer) ([]subscriptions.Subscription, error) {
+	client := azclient.NewSubscriptionsClient(authorizer)
+
+	list, err := client.ListComplete(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting subscription list: %w", err)
+	}
+
+	subs := []subscriptions.Subscription{}
+
+	for list.NotDone() {
+		if sub := list.Value(); sub.SubscriptionID != nil && sub.DisplayName != nil {
+			subs = append(subs, sub)
+		}
+
+		if err := list.NextWithContext(ctx); err != nil {
+			return nil, fmt.Errorf("getting subscription list: %w", err)
+		}
+	}
+
+	return subs, nil
+}
+
+// clusterFromManagedCluster will convert the AKS managed cluster to a discovered cluster. The
+// labels are populated with the subscription, resource group, location and tags of the cluster.
+func clusterFromManagedCluster(val *containerservice.ManagedCluster) (*discovery.Cluster, error) {
+	clusterID, err := id.ToClusterID(*val.ID)
+	if err != nil {
+		return nil, fmt.Errorf("create cluster id: %w", err)
+	}
+
+	cluster := &discovery.Cluster{
+		Name:   *val.Name,
+		ID:     clusterID,
+		Labels: make(map[string]string),
+	}
+
+	for k, v := range val.Tags {
+		if v != nil {
+			cluster.Labels[k] = *v
+		}
+	}
+
+	if val.Location != nil {
+		cluster.Labels[discovery.LabelLocation] = *val.Location
+	}
+
+	if resourceID, err := id.FromClusterID(clusterID); err == nil {
+		cluster.Labels[discovery.LabelSubscription] = resourceID.SubscriptionID
+		cluster.Labels[discovery.LabelResourceGroup] = resourceID.ResourceGroupName
+	}
+
+	if val.ManagedClusterProperties == nil {
+		return cluster, nil
+	}
+
+	cluster.Version = val.KubernetesVersion
+	cluster.Status = val.ProvisioningState
+
+	controlPlaneEndpoint := ""
+
+	if val.Fqdn != nil {
+		url := net.JoinHostPort(*val.Fqdn, "443")
+		controlPlaneEndpoint = fmt.Sprintf("https://%s", url)
+	}
+
+	if val.PrivateFQDN != nil {
+		url := net.JoinHostPort(*val.PrivateFQDN, "443")
+		controlPlaneEndpoint = fmt.Sprintf("https://%s", url)
+	}
+
+	if controlPlaneEndpoint != "" {
+		cluster.ControlPlaneEndpoint = &controlPlaneEndpoint
+	}
+
+	if val.PowerState != nil && val.PowerState.Code != "" {
+		cluster.Labels[powerStateLabel] = string(val.PowerState.Code)
+	}
+
+	return cluster, nil
 }
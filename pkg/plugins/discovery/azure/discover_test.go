@@ -0,0 +1,350 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2022-03-01/containerservice"
+	"github.com/Azure/go-autorest/autorest"
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/pkg/provider/discovery"
+)
+
+// stubARM is a stubbed Azure resource manager api used for testing discovery
+type stubARM struct {
+	subscriptions []map[string]string
+}
+
+func (s *stubARM) handler(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/subscriptions":
+		json.NewEncoder(w).Encode(map[string]any{"value": s.subscriptions}) //nolint: errcheck
+	case strings.Contains(r.URL.Path, "/managedClusters/"):
+		parts := strings.Split(r.URL.Path, "/")
+		json.NewEncoder(w).Encode(map[string]any{ //nolint: errcheck
+			"id":       strings.Replace(r.URL.Path, "/resourceGroups/", "/resourcegroups/", 1),
+			"name":     parts[len(parts)-1],
+			"location": "westeurope",
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// redirectAuthorizer sends the requests to the stubbed api
+type redirectAuthorizer struct {
+	target *url.URL
+}
+
+func (a *redirectAuthorizer) WithAuthorization() autorest.PrepareDecorator {
+	return func(p autorest.Preparer) autorest.Preparer {
+		return autorest.PreparerFunc(func(r *http.Request) (*http.Request, error) {
+			r, err := p.Prepare(r)
+			if err == nil {
+				r.URL.Scheme = a.target.Scheme
+				r.URL.Host = a.target.Host
+			}
+
+			return r, err
+		})
+	}
+}
+
+func newTestProvider(t *testing.T, stub *stubARM, cfg *aksClusterProviderConfig) *aksClusterProvider {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(stub.handler))
+	t.Cleanup(server.Close)
+
+	serverURL, _ := url.Parse(server.URL)
+	authorizer := &redirectAuthorizer{target: serverURL}
+
+	return &aksClusterProvider{
+		config:     cfg,
+		authorizer: authorizer,
+		// tenant-b has already been authorized and tenant-c can't be as there's no AD identity
+		tenantAuthorizers:   map[string]autorest.Authorizer{"tenant-b": authorizer},
+		subscriptionTenants: map[string]string{},
+		logger:              zap.NewNop().Sugar(),
+	}
+}
+
+func testSubscriptions() []map[string]string {
+	return []map[string]string{
+		{"subscriptionId": "sub-dev", "displayName": "Development", "state": "Enabled", "tenantId": "tenant-a"},
+		{"subscriptionId": "sub-prod", "displayName": "Production", "state": "Enabled", "tenantId": "tenant-a"},
+		{"subscriptionId": "sub-old", "displayName": "Old", "state": "Disabled", "tenantId": "tenant-a"},
+		{"subscriptionId": "sub-partner", "displayName": "Partner", "state": "Enabled", "tenantId": "tenant-b"},
+	}
+}
+
+func Test_SubscriptionTargets(t *testing.T) {
+	testCases := []struct {
+		name           string
+		config         *aksClusterProviderConfig
+		expectTargets  []string
+		expectWarnings int
+		expectErr      error
+	}{
+		{
+			name:          "single subscription",
+			config:        &aksClusterProviderConfig{SubscriptionID: strPtr("sub-dev"), TenantID: "tenant-a"},
+			expectTargets: []string{"sub-dev=tenant-a"},
+		},
+		{
+			name:      "no subscription",
+			config:    &aksClusterProviderConfig{TenantID: "tenant-a"},
+			expectErr: ErrSubscriptionRequired,
+		},
+		{
+			name:          "all subscriptions",
+			config:        &aksClusterProviderConfig{AllSubscriptions: true, TenantID: "tenant-a"},
+			expectTargets: []string{"sub-dev=tenant-a", "sub-partner=tenant-b", "sub-prod=tenant-a"},
+		},
+		{
+			name:          "all subscriptions with filter on name",
+			config:        &aksClusterProviderConfig{AllSubscriptions: true, SubscriptionFilter: "^Dev", TenantID: "tenant-a"},
+			expectTargets: []string{"sub-dev=tenant-a"},
+		},
+		{
+			name:          "other tenants",
+			config:        &aksClusterProviderConfig{TenantIDs: "tenant-a, tenant-b", TenantID: "tenant-a"},
+			expectTargets: []string{"sub-dev=tenant-a", "sub-partner=tenant-b", "sub-prod=tenant-a"},
+		},
+		{
+			name:           "tenant that can't be authorized",
+			config:         &aksClusterProviderConfig{TenantIDs: "tenant-b,tenant-c", TenantID: "tenant-a"},
+			expectTargets:  []string{"sub-partner=tenant-b"},
+			expectWarnings: 1,
+		},
+		{
+			name:      "only a tenant that can't be authorized",
+			config:    &aksClusterProviderConfig{TenantIDs: "tenant-c", TenantID: "tenant-a"},
+			expectErr: ErrTenantsNeedAD,
+		},
+		{
+			name:      "no matching subscriptions",
+			config:    &aksClusterProviderConfig{AllSubscriptions: true, SubscriptionFilter: "^Test", TenantID: "tenant-a"},
+			expectErr: ErrNoSubscriptions,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newTestProvider(t, &stubARM{subscriptions: testSubscriptions()}, tc.config)
+
+			targets, warnings, err := p.subscriptionTargets(context.Background())
+			if tc.expectErr != nil {
+				if !errors.Is(err, tc.expectErr) {
+					t.Fatalf("expected error %v but got %v", tc.expectErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			actual := []string{}
+			for _, target := range targets {
+				actual = append(actual, target.subscriptionID+"="+target.tenantID)
+			}
+
+			sort.Strings(actual)
+
+			if strings.Join(actual, ",") != strings.Join(tc.expectTargets, ",") {
+				t.Fatalf("expected targets %v but got %v", tc.expectTargets, actual)
+			}
+
+			if len(warnings) != tc.expectWarnings {
+				t.Fatalf("expected %d warnings but got %d", tc.expectWarnings, len(warnings))
+			}
+		})
+	}
+}
+
+func Test_GetClusterTenant(t *testing.T) {
+	testCases := []struct {
+		name         string
+		config       *aksClusterProviderConfig
+		clusterID    string
+		expectTenant string
+		expectErr    error
+	}{
+		{
+			name:         "users tenant",
+			config:       &aksClusterProviderConfig{TenantID: "tenant-a"},
+			clusterID:    "sub-dev/rg-dev/dev",
+			expectTenant: "tenant-a",
+		},
+		{
+			name:         "other tenant",
+			config:       &aksClusterProviderConfig{TenantIDs: "tenant-a,tenant-b", TenantID: "tenant-a"},
+			clusterID:    "sub-partner/rg-partner/partner",
+			expectTenant: "tenant-b",
+		},
+		{
+			name:      "subscription not in the tenants",
+			config:    &aksClusterProviderConfig{TenantIDs: "tenant-b", TenantID: "tenant-a"},
+			clusterID: "sub-dev/rg-dev/dev",
+			expectErr: ErrSubscriptionNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newTestProvider(t, &stubARM{subscriptions: testSubscriptions()}, tc.config)
+
+			cluster, err := p.getCluster(context.Background(), tc.clusterID)
+			if tc.expectErr != nil {
+				if !errors.Is(err, tc.expectErr) {
+					t.Fatalf("expected error %v but got %v", tc.expectErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if cluster.ID != tc.clusterID {
+				t.Fatalf("expected cluster id %s but got %s", tc.clusterID, cluster.ID)
+			}
+
+			if tenant := cluster.Labels[discovery.LabelTenant]; tenant != tc.expectTenant {
+				t.Fatalf("expected tenant %s but got %s", tc.expectTenant, tenant)
+			}
+		})
+	}
+}
+
+func Test_ClusterFilterMatches(t *testing.T) {
+	testCases := []struct {
+		name    string
+		config  *aksClusterProviderConfig
+		cluster containerservice.ManagedCluster
+		expect  bool
+	}{
+		{
+			name:    "no filters",
+			config:  &aksClusterProviderConfig{},
+			cluster: testManagedCluster("dev", "West Europe", "Succeeded", map[string]string{"env": "dev"}),
+			expect:  true,
+		},
+		{
+			name:    "no name",
+			config:  &aksClusterProviderConfig{},
+			cluster: containerservice.ManagedCluster{},
+			expect:  false,
+		},
+		{
+			name:    "cluster name",
+			config:  &aksClusterProviderConfig{ClusterName: "prod"},
+			cluster: testManagedCluster("dev", "westeurope", "Succeeded", nil),
+			expect:  false,
+		},
+		{
+			name:    "name regex",
+			config:  &aksClusterProviderConfig{ClusterNameFilter: "^dev-"},
+			cluster: testManagedCluster("dev-payments", "westeurope", "Succeeded", nil),
+			expect:  true,
+		},
+		{
+			name:    "location display name",
+			config:  &aksClusterProviderConfig{Location: "westeurope"},
+			cluster: testManagedCluster("dev", "West Europe", "Succeeded", nil),
+			expect:  true,
+		},
+		{
+			name:    "different location",
+			config:  &aksClusterProviderConfig{Location: "West Europe"},
+			cluster: testManagedCluster("dev", "northeurope", "Succeeded", nil),
+			expect:  false,
+		},
+		{
+			name:    "succeeded only",
+			config:  &aksClusterProviderConfig{SucceededOnly: true},
+			cluster: testManagedCluster("dev", "westeurope", "Failed", nil),
+			expect:  false,
+		},
+		{
+			name:    "succeeded only without properties",
+			config:  &aksClusterProviderConfig{SucceededOnly: true},
+			cluster: containerservice.ManagedCluster{Name: strPtr("dev")},
+			expect:  false,
+		},
+		{
+			name:    "tags",
+			config:  &aksClusterProviderConfig{Tags: "env=prod,team in (payments,orders)"},
+			cluster: testManagedCluster("prod", "westeurope", "Succeeded", map[string]string{"env": "prod", "team": "payments"}),
+			expect:  true,
+		},
+		{
+			name:    "tags not matching",
+			config:  &aksClusterProviderConfig{Tags: "env=prod"},
+			cluster: testManagedCluster("dev", "westeurope", "Succeeded", map[string]string{"env": "dev"}),
+			expect:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &aksClusterProvider{config: tc.config}
+
+			filter, err := p.newClusterFilter()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if actual := filter.matches(&tc.cluster); actual != tc.expect {
+				t.Fatalf("expected %t but got %t", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func testManagedCluster(name, location, state string, tags map[string]string) containerservice.ManagedCluster {
+	clusterTags := map[string]*string{}
+	for k, v := range tags {
+		clusterTags[k] = strPtr(v)
+	}
+
+	return containerservice.ManagedCluster{
+		Name:     strPtr(name),
+		Location: strPtr(location),
+		Tags:     clusterTags,
+		ManagedClusterProperties: &containerservice.ManagedClusterProperties{
+			ProvisioningState: strPtr(state),
+		},
+	}
+}
+
+func strPtr(value string) *string {
+	return &value
+}
@@ -25,4 +25,7 @@ var (
 	ErrSubscriptionNameOrID = errors.New("subscription name and id cannot be both supplied")
 	ErrSubscriptionNotFound = errors.New("subscription not found")
 	ErrTokenNeedsAD         = errors.New("the 'token' login type requires using aad idp-protocol")
+	ErrSubscriptionRequired = errors.New("a subscription is required unless discovering in all subscriptions")
+	ErrSubscriptionAndAll   = errors.New("a subscription cannot be supplied when discovering in all subscriptions")
+	ErrTenantsNeedAD        = errors.New("discovering clusters in other tenants requires using aad idp-protocol")
 )
@@ -33,24 +33,52 @@ func (p *aksClusterProvider) GetCluster(ctx context.Context, input *discovery.Ge
 
 	p.logger.Infow("getting AKS cluster", "id", input.ClusterID)
 
-	resourceID, err := id.FromClusterID(input.ClusterID)
+	cluster, err := p.getCluster(ctx, input.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &discovery.GetClusterOutput{
+		Cluster: cluster,
+	}, nil
+}
+
+// getCluster will get the cluster from its subscription. The cluster is labelled with
+// the tenant of the subscription, the same as when the clusters are discovered, so
+// that kubelogin uses the right tenant.
+func (p *aksClusterProvider) getCluster(ctx context.Context, clusterID string) (*discovery.Cluster, error) {
+	resourceID, err := id.FromClusterID(clusterID)
 	if err != nil {
 		return nil, fmt.Errorf("getting resource id: %w", err)
 	}
 
-	client := azclient.NewContainerClient(resourceID.SubscriptionID, p.authorizer)
+	tenantID, err := p.tenantForSubscription(ctx, resourceID.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	authorizer, err := p.authorizerForTenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	client := azclient.NewContainerClient(resourceID.SubscriptionID, authorizer)
 
 	result, err := client.Get(ctx, resourceID.ResourceGroupName, resourceID.ResourceName)
 	if err != nil {
 		return nil, fmt.Errorf("getting cluster: %w", err)
 	}
 
-	cluster := &discovery.Cluster{
-		Name: *result.Name,
-		ID:   input.ClusterID,
+	cluster, err := clusterFromManagedCluster(&result)
+	if err != nil {
+		return nil, err
 	}
 
-	return &discovery.GetClusterOutput{
-		Cluster: cluster,
-	}, nil
+	cluster.ID = clusterID
+
+	if tenantID != "" {
+		cluster.Labels[discovery.LabelTenant] = tenantID
+	}
+
+	return cluster, nil
 }
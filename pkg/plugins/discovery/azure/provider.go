@@ -17,7 +17,9 @@ limitations under the License.
 package azure
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"go.uber.org/zap"
 
@@ -40,12 +42,23 @@ const (
 	UsageExample = `  # Discover AKS clusters using Azure AD
   {{.CommandPath}} use aks --idp-protocol aad
 
+  # Discover AKS clusters using Azure AD when MFA is required
+  {{.CommandPath}} use aks --idp-protocol aad --aad-login-mode devicecode
+
+  # Discover AKS clusters in all the production subscriptions
+  {{.CommandPath}} use aks --idp-protocol aad --all-subscriptions --subscription-filter "-prod$"
+
+  # Discover the running AKS clusters for a team
+  {{.CommandPath}} use aks --idp-protocol aad --tags env=prod,team=payments --location westeurope --succeeded-only
+
   # Discover AKS clusters using file based credentials
   export AZURE_TENANT_ID="123455"
   export AZURE_CLIENT_ID="76849"
   export AZURE_CLIENT_SECRET="supersecret"
   {{.CommandPath}} use aks --idp-protocol az-env
 `
+
+	managementResource = "https://management.azure.com/"
 )
 
 func init() {
@@ -88,11 +101,26 @@ type aksClusterProviderConfig struct {
 	LoginType        LoginType   `json:"login-type"`
 	AzureEnvironment Environment `json:"azure-env"`
 	ServerFqdnType   string      `json:"server-fqdn-type"`
+
+	AllSubscriptions   bool   `json:"all-subscriptions"`
+	SubscriptionFilter string `json:"subscription-filter"`
+	TenantIDs          string `json:"tenant-ids"`
+
+	Tags              string `json:"tags"`
+	Location          string `json:"location"`
+	ClusterNameFilter string `json:"cluster-name-filter"`
+	SucceededOnly     bool   `json:"succeeded-only"`
 }
 
 type aksClusterProvider struct {
 	config     *aksClusterProviderConfig
 	authorizer autorest.Authorizer
+	identity   identity.Identity
+
+	// tenantAuthorizers and subscriptionTenants are used when discovering clusters in other tenants
+	lock                sync.Mutex
+	tenantAuthorizers   map[string]autorest.Authorizer
+	subscriptionTenants map[string]string
 
 	httpClient  khttp.Client
 	interactive bool
@@ -124,6 +152,15 @@ func ConfigurationItems(scopeTo string) (config.ConfigurationSet, error) {
 	cs.String(AzureEnvironmentConfigItem, string(EnvironmentPublicCloud), "The Azure environment the clusters are in. Possible values: public,china,usgov,stack")                               //nolint: errcheck
 	cs.String(ServerFqdnTypeConfigItem, "public", "Connect to AKS cluster via Public/Private FQDN")
 
+	cs.Bool(AllSubscriptionsConfigItem, false, "Discover clusters in all the subscriptions that the user has access to")                                    //nolint: errcheck
+	cs.String(SubscriptionFilterConfigItem, "", "A regular expression that the subscription names or ids must match when discovering in all subscriptions") //nolint: errcheck
+	cs.String(TenantIDsConfigItem, "", "Comma separated list of Azure tenant ids to discover clusters in. All the subscriptions in the tenants are used")   //nolint: errcheck
+
+	cs.String(TagsConfigItem, "", "Selector applied to the tags of the AKS clusters (e.g. env=prod,team=payments)")  //nolint: errcheck
+	cs.String(LocationConfigItem, "", "Only discover the clusters in the Azure location (e.g. westeurope)")          //nolint: errcheck
+	cs.String(ClusterNameFilterConfigItem, "", "A regular expression that the names of the AKS clusters must match") //nolint: errcheck
+	cs.Bool(SucceededOnlyConfigItem, false, "Only discover the clusters whose provisioning state is Succeeded")      //nolint: errcheck
+
 	cs.SetShort(ResourceGroupConfigItem, "r") //nolint: errcheck
 
 	return cs, nil
@@ -141,6 +178,9 @@ func (p *aksClusterProvider) setup(cs config.ConfigurationSet, userID identity.I
 	}
 
 	p.config = cfg
+	p.identity = userID
+	p.tenantAuthorizers = map[string]autorest.Authorizer{}
+	p.subscriptionTenants = map[string]string{}
 
 	// TODO: should we just return a AuthorizerIdentity from the aad provider?
 	switch userID.(type) { //nolint:gocritic,gosimple
@@ -149,7 +189,7 @@ func (p *aksClusterProvider) setup(cs config.ConfigurationSet, userID identity.I
 
 		p.logger.Debugw("creating bearer authorizer")
 
-		bearerAuth, err := getBearerAuthFromIdentity(id, "https://management.azure.com/")
+		bearerAuth, err := getBearerAuthFromIdentity(id, managementResource)
 		if err != nil {
 			return fmt.Errorf("getting bearer authorizer: %w", err)
 		}
@@ -165,6 +205,77 @@ func (p *aksClusterProvider) setup(cs config.ConfigurationSet, userID identity.I
 	return nil
 }
 
+// authorizerForTenant returns the authorizer to use for the subscriptions in a tenant. The tokens
+// for other tenants are requested using the users Azure AD identity.
+func (p *aksClusterProvider) authorizerForTenant(tenantID string) (autorest.Authorizer, error) {
+	if tenantID == "" || tenantID == p.config.TenantID {
+		return p.authorizer, nil
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if authorizer, ok := p.tenantAuthorizers[tenantID]; ok {
+		return authorizer, nil
+	}
+
+	id, ok := p.identity.(*azid.ActiveDirectoryIdentity)
+	if !ok {
+		return nil, ErrTenantsNeedAD
+	}
+
+	p.logger.Debugw("creating bearer authorizer for tenant", "tenant", tenantID)
+
+	authorizer, err := getBearerAuthFromIdentity(id.Clone(azid.WithTenant(tenantID)), managementResource)
+	if err != nil {
+		return nil, fmt.Errorf("getting bearer authorizer for tenant %s: %w", tenantID, err)
+	}
+
+	p.tenantAuthorizers[tenantID] = authorizer
+
+	return authorizer, nil
+}
+
+// authorizerForSubscription returns the authorizer for the tenant that the subscription is in
+func (p *aksClusterProvider) authorizerForSubscription(ctx context.Context, subscriptionID string) (autorest.Authorizer, error) {
+	tenantID, err := p.tenantForSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.authorizerForTenant(tenantID)
+}
+
+// tenantForSubscription returns the tenant that the subscription is in. This is the users
+// tenant unless clusters are discovered in other tenants.
+func (p *aksClusterProvider) tenantForSubscription(ctx context.Context, subscriptionID string) (string, error) {
+	if p.config.TenantIDs == "" {
+		return p.config.TenantID, nil
+	}
+
+	tenantID, ok := p.subscriptionTenant(subscriptionID)
+	if !ok {
+		if _, _, err := p.subscriptionTargets(ctx); err != nil {
+			return "", err
+		}
+
+		if tenantID, ok = p.subscriptionTenant(subscriptionID); !ok {
+			return "", fmt.Errorf("finding tenant for subscription %s: %w", subscriptionID, ErrSubscriptionNotFound)
+		}
+	}
+
+	return tenantID, nil
+}
+
+func (p *aksClusterProvider) subscriptionTenant(subscriptionID string) (string, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	tenantID, ok := p.subscriptionTenants[subscriptionID]
+
+	return tenantID, ok
+}
+
 func getBearerAuthFromIdentity(id *azid.ActiveDirectoryIdentity, resource string) (autorest.Authorizer, error) {
 	token, err := id.GetOAuthToken(resource)
 	if err != nil {
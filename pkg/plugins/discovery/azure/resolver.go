@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 
-	azclient "github.com/fidelity/kconnect/pkg/azure/client"
 	"github.com/fidelity/kconnect/pkg/config"
 	kerrors "github.com/fidelity/kconnect/pkg/errors"
 	"github.com/fidelity/kconnect/pkg/prompt"
@@ -56,6 +55,15 @@ func (p *aksClusterProvider) Resolve(cfg config.ConfigurationSet, userID identit
 		return ErrSubscriptionNameOrID
 	}
 
+	// The subscriptions are found when discovering in all subscriptions or other tenants
+	if p.config.AllSubscriptions || p.config.TenantIDs != "" {
+		if cfg.ExistsWithValue(SubscriptionIDConfigItem) || cfg.ExistsWithValue(SubscriptionNameConfigItem) {
+			return ErrSubscriptionAndAll
+		}
+
+		return nil
+	}
+
 	if err := p.resolveSubscripionName(cfg); err != nil {
 		return fmt.Errorf("resolving subscription name: %w", err)
 	}
@@ -97,15 +105,13 @@ func (p *aksClusterProvider) resolveSubscripionName(cfg config.ConfigurationSet)
 }
 
 func (p *aksClusterProvider) subscriptionOptions() (map[string]string, error) {
-	client := azclient.NewSubscriptionsClient(p.authorizer)
-
-	res, err := client.List(context.TODO())
+	res, err := listSubscriptions(context.TODO(), p.authorizer)
 	if err != nil {
-		return nil, fmt.Errorf("getting subscription list: %w", err)
+		return nil, err
 	}
 
 	subs := make(map[string]string)
-	for _, sub := range res.Values() {
+	for _, sub := range res {
 		subs[*sub.DisplayName] = *sub.SubscriptionID
 	}
 
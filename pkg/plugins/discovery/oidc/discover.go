@@ -18,12 +18,17 @@ package oidc
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/fidelity/kconnect/pkg/provider/discovery"
 )
 
 func (p *oidcClusterProvider) Discover(ctx context.Context, input *discovery.DiscoverInput) (*discovery.DiscoverOutput, error) {
-	cluster, _ := p.getCluster(ctx)
+	cluster, err := p.getCluster(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting cluster: %w", err)
+	}
+
 	clusters := make(map[string]*discovery.Cluster)
 	clusters[cluster.ID] = cluster
 	discoverOutput := &discovery.DiscoverOutput{
@@ -19,6 +19,7 @@ package oidc
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/fidelity/kconnect/pkg/config"
 	"github.com/fidelity/kconnect/pkg/oidc"
@@ -175,6 +176,12 @@ func (p *oidcClusterProvider) getCluster(ctx context.Context) (*discovery.Cluste
 		Name:                     *p.config.ClusterID,
 		ControlPlaneEndpoint:     &p.config.ClusterUrl,
 		CertificateAuthorityData: &p.config.ClusterAuth,
+		Labels:                   make(map[string]string),
+	}
+
+	// There is no api to query so the labels are populated from the config
+	if serverURL, err := url.Parse(p.config.ClusterUrl); err == nil && serverURL.Hostname() != "" {
+		cluster.Labels[discovery.LabelServer] = serverURL.Hostname()
 	}
 
 	return &cluster, nil
@@ -20,19 +20,30 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
 
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/fidelity/kconnect/pkg/defaults"
 	khttp "github.com/fidelity/kconnect/pkg/http"
+	"github.com/fidelity/kconnect/pkg/prompt"
 	"github.com/fidelity/kconnect/pkg/provider/discovery"
 	"github.com/fidelity/kconnect/pkg/rancher"
 )
 
 const (
 	generateKubeconfigActionName = "generateKubeconfig"
+
+	namespaceConfigItem = "namespace"
+
+	aceContextSuffix  = "-fqdn"
+	aceMaxDialTimeout = 5 * time.Second
 )
 
 func (p *rancherClusterProvider) GetConfig(ctx context.Context, input *discovery.GetConfigInput) (*discovery.GetConfigOutput, error) {
@@ -50,6 +61,14 @@ func (p *rancherClusterProvider) GetConfig(ctx context.Context, input *discovery
 		return nil, fmt.Errorf("getting kubeconfig: %w", err)
 	}
 
+	contextName, err := p.selectContext(ctx, cfg, clusterDetail)
+	if err != nil {
+		return nil, fmt.Errorf("selecting kubeconfig context: %w", err)
+	}
+
+	p.logger.Debugw("using kubeconfig context", "context", contextName)
+	keepContext(cfg, contextName)
+
 	if input.Namespace != nil && *input.Namespace != "" {
 		p.logger.Debugw("setting kubernetes namespace", "namespace", *input.Namespace)
 		cfg.Contexts[cfg.CurrentContext].Namespace = *input.Namespace
@@ -68,9 +87,7 @@ func (p *rancherClusterProvider) getClusterDetails(clusterID string) (*clusterDe
 	}
 
 	headers := defaults.Headers(defaults.WithJSON(), defaults.WithBearerAuth(p.token))
-	httpClient := khttp.NewHTTPClient()
-
-	resp, err := httpClient.Get(resolver.Cluster(clusterID), headers)
+	resp, err := p.httpClient.Get(resolver.Cluster(clusterID), headers)
 	if err != nil {
 		return nil, fmt.Errorf("getting cluster %s using api: %w", clusterID, err)
 	}
@@ -94,9 +111,7 @@ func (p *rancherClusterProvider) getKubeconfig(clusterDetail *clusterDetails) (*
 	}
 
 	headers := defaults.Headers(defaults.WithJSON(), defaults.WithBearerAuth(p.token))
-	httpClient := khttp.NewHTTPClient()
-
-	resp, err := httpClient.Post(actionURL, "{}", headers)
+	resp, err := p.httpClient.Post(actionURL, "{}", headers)
 	if err != nil {
 		return nil, fmt.Errorf("getting cluster %s kubeconfig using api: %w", clusterDetail.ID, err)
 	}
@@ -117,3 +132,222 @@ func (p *rancherClusterProvider) getKubeconfig(clusterDetail *clusterDetails) (*
 
 	return kubeCfg, nil
 }
+
+// selectContext will select the context from the kubeconfig generated by Rancher based on the
+// endpoint type. The current context of the generated kubeconfig uses the Rancher proxy and
+// the other contexts use the authorized cluster endpoint. If none of the authorized cluster
+// endpoints can be reached then the proxy context is used.
+func (p *rancherClusterProvider) selectContext(ctx context.Context, cfg *api.Config, clusterDetail *clusterDetails) (string, error) {
+	proxyContext := cfg.CurrentContext
+
+	switch p.config.EndpointType {
+	case "", rancher.EndpointTypeProxy:
+		return proxyContext, nil
+	case rancher.EndpointTypeACE:
+	default:
+		return "", fmt.Errorf("using endpoint type %s: %w", p.config.EndpointType, ErrUnknownEndpointType)
+	}
+
+	if clusterDetail.LocalClusterAuthEndpoint == nil || !clusterDetail.LocalClusterAuthEndpoint.Enabled {
+		p.logger.Warnw("authorized cluster endpoint isn't enabled, using the Rancher proxy", "cluster", clusterDetail.Name)
+		return proxyContext, nil
+	}
+
+	timeout := p.aceDialTimeout()
+
+	for _, contextName := range aceContexts(cfg, proxyContext) {
+		kubeCluster, ok := cfg.Clusters[cfg.Contexts[contextName].Cluster]
+		if !ok {
+			continue
+		}
+
+		if endpointReachable(ctx, kubeCluster.Server, timeout) {
+			return contextName, nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return "", fmt.Errorf("checking authorized cluster endpoint: %w", err)
+		}
+
+		p.logger.Warnw("authorized cluster endpoint isn't reachable", "context", contextName, "server", kubeCluster.Server)
+	}
+
+	p.logger.Warnw("no authorized cluster endpoint is reachable, using the Rancher proxy", "cluster", clusterDetail.Name)
+
+	return proxyContext, nil
+}
+
+// aceContexts returns the names of the contexts that use the authorized cluster endpoint. The
+// context for the FQDN is first followed by the contexts for each of the control plane nodes.
+func aceContexts(cfg *api.Config, proxyContext string) []string {
+	names := []string{}
+
+	for name := range cfg.Contexts {
+		if name != proxyContext {
+			names = append(names, name)
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		iFQDN := strings.HasSuffix(names[i], aceContextSuffix)
+		jFQDN := strings.HasSuffix(names[j], aceContextSuffix)
+
+		if iFQDN != jFQDN {
+			return iFQDN
+		}
+
+		return names[i] < names[j]
+	})
+
+	return names
+}
+
+// keepContext will set the current context and remove the other contexts, along with any
+// clusters and users that are no longer used
+func keepContext(cfg *api.Config, contextName string) {
+	cfg.CurrentContext = contextName
+	kubeContext := cfg.Contexts[contextName]
+
+	for name := range cfg.Contexts {
+		if name != contextName {
+			delete(cfg.Contexts, name)
+		}
+	}
+
+	for name := range cfg.Clusters {
+		if name != kubeContext.Cluster {
+			delete(cfg.Clusters, name)
+		}
+	}
+
+	for name := range cfg.AuthInfos {
+		if name != kubeContext.AuthInfo {
+			delete(cfg.AuthInfos, name)
+		}
+	}
+}
+
+// aceDialTimeout returns the time limit for checking if an authorized cluster endpoint
+// is reachable. This is the http timeout but no more than aceMaxDialTimeout so that an
+// unreachable endpoint doesn't hold up connecting to many clusters.
+func (p *rancherClusterProvider) aceDialTimeout() time.Duration {
+	timeout := khttp.ConfigForClient(p.httpClient).Timeout
+	if timeout <= 0 || timeout > aceMaxDialTimeout {
+		return aceMaxDialTimeout
+	}
+
+	return timeout
+}
+
+// endpointReachable checks if a connection can be made to the server. The check is
+// cancelled if the context is done.
+func endpointReachable(ctx context.Context, server string, timeout time.Duration) bool {
+	serverURL, err := url.Parse(server)
+	if err != nil || serverURL.Host == "" {
+		return false
+	}
+
+	address := serverURL.Host
+	if serverURL.Port() == "" {
+		address = net.JoinHostPort(serverURL.Hostname(), "443")
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return false
+	}
+
+	conn.Close()
+
+	return true
+}
+
+// NamespaceChoiceEnabled returns true if choosing the namespace from the Rancher projects
+// has been enabled with the choose-namespace config item
+func (p *rancherClusterProvider) NamespaceChoiceEnabled() bool {
+	return p.config != nil && p.config.ChooseNamespace
+}
+
+// ChooseNamespace will ask the user to choose one of their Rancher projects in the cluster and
+// then a namespace in the project, if choosing the namespace is enabled. The chosen namespace
+// is set in the config so that it's saved in the history.
+func (p *rancherClusterProvider) ChooseNamespace(ctx context.Context, cluster *discovery.Cluster) (string, error) {
+	if !p.NamespaceChoiceEnabled() || !p.interactive {
+		return "", nil
+	}
+
+	clusterID := cluster.ID
+
+	resolver, err := rancher.NewStaticEndpointsResolver(p.config.APIEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("creating endpoint resolver: %w", err)
+	}
+
+	projects := map[string]string{}
+
+	err = p.listCollection(resolver.Projects(clusterID), ErrGettingProjects, func(body []byte) (string, error) {
+		page := &listProjectsResponse{}
+		if err := json.Unmarshal(body, page); err != nil {
+			return "", fmt.Errorf("unmarshalling api response: %w", err)
+		}
+
+		for _, project := range page.Projects {
+			projects[fmt.Sprintf("%s (%s)", project.Name, project.ID)] = project.ID
+		}
+
+		return page.Pagination.NextPage(), nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(projects) == 0 {
+		p.logger.Infow("no Rancher projects found for cluster", "cluster", clusterID)
+		return "", nil
+	}
+
+	projectID, err := prompt.Choose("project", "Select the Rancher project", true, prompt.OptionsFromMap(projects))
+	if err != nil {
+		return "", fmt.Errorf("choosing project: %w", err)
+	}
+
+	namespaces := []string{}
+
+	err = p.listCollection(resolver.Namespaces(clusterID, projectID), ErrGettingNamespaces, func(body []byte) (string, error) {
+		page := &listNamespacesResponse{}
+		if err := json.Unmarshal(body, page); err != nil {
+			return "", fmt.Errorf("unmarshalling api response: %w", err)
+		}
+
+		for _, namespace := range page.Namespaces {
+			if namespace.ProjectID == projectID {
+				namespaces = append(namespaces, namespace.Name)
+			}
+		}
+
+		return page.Pagination.NextPage(), nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(namespaces) == 0 {
+		p.logger.Infow("no namespaces found in Rancher project", "project", projectID)
+		return "", nil
+	}
+
+	namespace, err := prompt.Choose(namespaceConfigItem, "Select the namespace", true, prompt.OptionsFromStringSlice(namespaces))
+	if err != nil {
+		return "", fmt.Errorf("choosing namespace: %w", err)
+	}
+
+	if p.configSet != nil && p.configSet.Exists(namespaceConfigItem) {
+		if err := p.configSet.SetValue(namespaceConfigItem, namespace); err != nil {
+			return "", fmt.Errorf("setting %s config: %w", namespaceConfigItem, err)
+		}
+	}
+
+	return namespace, nil
+}
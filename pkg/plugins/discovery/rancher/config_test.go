@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rancher
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	khttp "github.com/fidelity/kconnect/pkg/http"
+	"github.com/fidelity/kconnect/pkg/rancher"
+)
+
+func Test_EndpointReachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %s", err)
+	}
+	defer listener.Close()
+
+	server := "https://" + listener.Addr().String()
+
+	if !endpointReachable(context.Background(), server, time.Second) {
+		t.Fatal("expected endpoint to be reachable")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if endpointReachable(ctx, server, time.Second) {
+		t.Fatal("expected endpoint check to be cancelled")
+	}
+}
+
+func Test_SelectContextCancelled(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %s", err)
+	}
+	defer listener.Close()
+
+	cfg := api.NewConfig()
+	cfg.Clusters["dev"] = &api.Cluster{Server: "https://rancher.example.com/k8s/clusters/c-abc12"}
+	cfg.Clusters["dev-fqdn"] = &api.Cluster{Server: "https://" + listener.Addr().String()}
+	cfg.Contexts["dev"] = &api.Context{Cluster: "dev"}
+	cfg.Contexts["dev-fqdn"] = &api.Context{Cluster: "dev-fqdn"}
+	cfg.CurrentContext = "dev"
+
+	p := &rancherClusterProvider{
+		config:     &rancherClusterProviderConfig{},
+		httpClient: khttp.NewHTTPClient(),
+		logger:     zap.NewNop().Sugar(),
+	}
+	p.config.EndpointType = rancher.EndpointTypeACE
+
+	details := &clusterDetails{
+		Name:                     "dev",
+		LocalClusterAuthEndpoint: &localClusterAuthEndpoint{Enabled: true},
+	}
+
+	contextName, err := p.selectContext(context.Background(), cfg, details)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if contextName != "dev-fqdn" {
+		t.Fatalf("expected the authorized cluster endpoint context but got %s", contextName)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := p.selectContext(ctx, cfg, details); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context cancelled error but got %v", err)
+	}
+}
@@ -18,17 +18,38 @@ package rancher
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/fidelity/kconnect/pkg/defaults"
-	khttp "github.com/fidelity/kconnect/pkg/http"
 	"github.com/fidelity/kconnect/pkg/provider/discovery"
 	"github.com/fidelity/kconnect/pkg/provider/identity"
 	"github.com/fidelity/kconnect/pkg/rancher"
 )
 
+const (
+	aceLabel      = "authorized-cluster-endpoint"
+	aceFQDNLabel  = "authorized-cluster-endpoint-fqdn"
+	aceCALabel    = "authorized-cluster-endpoint-ca"
+	providerLabel = "provider"
+
+	aceEnabled   = "enabled"
+	aceDisabled  = "disabled"
+	aceCASystem  = "system"
+	aceCAValid   = "valid"
+	aceCAInvalid = "invalid"
+
+	clusterStateActive = "active"
+)
+
 func (p *rancherClusterProvider) Discover(ctx context.Context, input *discovery.DiscoverInput) (*discovery.DiscoverOutput, error) {
 	if err := p.setup(input.ConfigSet, input.Identity); err != nil {
 		return nil, fmt.Errorf("setting up rancher provider: %w", err)
@@ -41,73 +62,266 @@ func (p *rancherClusterProvider) Discover(ctx context.Context, input *discovery.
 		return nil, identity.ErrNotTokenIdentity
 	}
 
-	clusters, err := p.listClusters()
+	clusters, warning, err := p.listClusters()
 	if err != nil {
 		return nil, fmt.Errorf("listing clusters: %w", err)
 	}
 
+	matching, err := p.filterClusters(clusters)
+	if err != nil {
+		return nil, fmt.Errorf("filtering clusters: %w", err)
+	}
+
+	if p.config.ClusterName != "" && len(matching) == 0 {
+		return nil, ErrNoMatchingCluster
+	}
+
 	discoverOutput := &discovery.DiscoverOutput{
 		DiscoveryProvider: ProviderName,
 		IdentityProvider:  id.IdentityProviderName(),
 		Clusters:          make(map[string]*discovery.Cluster),
 	}
 
-	if p.config.ClusterName != "" {
-		for _, v := range clusters {
-			if p.config.ClusterName == v.Name {
-				discoverOutput.Clusters[v.ID] = v
-				break
-			}
-		}
+	if warning != nil {
+		discoverOutput.Warnings = append(discoverOutput.Warnings, warning)
+	}
 
-		if len(discoverOutput.Clusters) == 0 {
-			return nil, ErrNoMatchingCluster
-		} else if len(discoverOutput.Clusters) > 1 {
-			return nil, ErrMulitpleMatchingCluster
-		}
-	} else {
-		for _, v := range clusters {
-			discoverOutput.Clusters[v.ID] = v
-		}
+	for _, details := range matching {
+		cluster := clusterFromDetails(details, p.config.EndpointType)
+		discoverOutput.Clusters[cluster.ID] = cluster
 	}
 
 	return discoverOutput, nil
 }
 
-func (p *rancherClusterProvider) listClusters() ([]*discovery.Cluster, error) {
+// listClusters will list the clusters using the Rancher api, following the pagination
+// links until all the clusters have been listed. If a page after the first one can't be
+// read the clusters already listed are returned with a warning.
+func (p *rancherClusterProvider) listClusters() ([]*clusterDetails, *discovery.Warning, error) {
 	p.logger.Debug("listing clusters using rancker api")
 
-	clusters := []*discovery.Cluster{}
-
 	resolver, err := rancher.NewStaticEndpointsResolver(p.config.APIEndpoint)
 	if err != nil {
-		return nil, fmt.Errorf("creating endpoint resolver: %w", err)
+		return nil, nil, fmt.Errorf("creating endpoint resolver: %w", err)
+	}
+
+	clusters := []*clusterDetails{}
+
+	err = p.listCollection(resolver.ClustersList(), ErrGettingClusters, func(body []byte) (string, error) {
+		page := &listClustersResponse{}
+		if err := json.Unmarshal(body, page); err != nil {
+			return "", fmt.Errorf("unmarshalling api response: %w", err)
+		}
+
+		for i := range page.Clusters {
+			clusters = append(clusters, &page.Clusters[i])
+		}
+
+		return page.Pagination.NextPage(), nil
+	})
+
+	var pageErr *pageError
+	if errors.As(err, &pageErr) && len(clusters) > 0 {
+		p.logger.Debugw("failed listing page of clusters", "url", pageErr.url, "error", pageErr.err)
+
+		return clusters, discovery.NewWarning(discovery.WarningScopePage, pageErr.url, pageErr.err), nil
+	}
+
+	if err != nil {
+		return nil, nil, err
 	}
 
+	return clusters, nil, nil
+}
+
+// pageError is returned by listCollection when a page of a collection can't be read
+type pageError struct {
+	url string
+	err error
+}
+
+func (e *pageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *pageError) Unwrap() error {
+	return e.err
+}
+
+// listCollection will get each page of a Rancher api collection. The addPage function is
+// called with the body of each page and returns the url of the next page.
+func (p *rancherClusterProvider) listCollection(collectionURL string, errListing error, addPage func(body []byte) (string, error)) error {
 	headers := defaults.Headers(defaults.WithJSON(), defaults.WithBearerAuth(p.token))
-	httpClient := khttp.NewHTTPClient()
+	visited := map[string]bool{}
+
+	for nextURL := collectionURL; nextURL != "" && !visited[nextURL]; {
+		visited[nextURL] = true
 
-	resp, err := httpClient.Get(resolver.ClustersList(), headers)
+		resp, err := p.httpClient.Get(nextURL, headers)
+		if err != nil {
+			return &pageError{url: nextURL, err: fmt.Errorf("getting %s using api: %w", nextURL, err)}
+		}
+
+		if resp.ResponseCode() != http.StatusOK {
+			return &pageError{url: nextURL, err: fmt.Errorf("%w: status code %d", errListing, resp.ResponseCode())}
+		}
+
+		nextURL, err = addPage([]byte(resp.Body()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// filterClusters will filter the clusters using the cluster name, labels, state and provider
+// filters. The cluster name is matched exactly first and then as a regular expression.
+func (p *rancherClusterProvider) filterClusters(clusters []*clusterDetails) ([]*clusterDetails, error) {
+	labelSelector, err := labels.Parse(p.config.ClusterLabels)
 	if err != nil {
-		return nil, fmt.Errorf("getting clusters using api: %w", err)
+		return nil, fmt.Errorf("parsing cluster labels %s: %w", p.config.ClusterLabels, err)
+	}
+
+	providers := []string{}
+	for _, clusterProvider := range strings.Split(p.config.ClusterProvider, ",") {
+		if clusterProvider = strings.TrimSpace(clusterProvider); clusterProvider != "" {
+			providers = append(providers, clusterProvider)
+		}
 	}
 
-	if resp.ResponseCode() != http.StatusOK {
-		return nil, ErrGettingClusters
+	matching := []*clusterDetails{}
+
+	for _, cluster := range clusters {
+		if p.config.ActiveOnly && cluster.State != clusterStateActive {
+			continue
+		}
+
+		if len(providers) > 0 && !providerMatches(cluster, providers) {
+			continue
+		}
+
+		if !labelSelector.Matches(labels.Set(cluster.Labels)) {
+			continue
+		}
+
+		matching = append(matching, cluster)
+	}
+
+	if p.config.ClusterName == "" {
+		return matching, nil
+	}
+
+	for _, cluster := range matching {
+		if cluster.Name == p.config.ClusterName {
+			return []*clusterDetails{cluster}, nil
+		}
 	}
 
-	listClustersResponse := &listClustersResponse{}
-	if err := json.Unmarshal([]byte(resp.Body()), listClustersResponse); err != nil {
-		return nil, fmt.Errorf("unmarshalling api response: %w", err)
+	nameRegex, err := regexp.Compile("^(?:" + p.config.ClusterName + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("compiling cluster name %s: %w", p.config.ClusterName, err)
+	}
+
+	named := []*clusterDetails{}
+
+	for _, cluster := range matching {
+		if nameRegex.MatchString(cluster.Name) {
+			named = append(named, cluster)
+		}
 	}
 
-	for _, val := range listClustersResponse.Clusters {
-		cluster := &discovery.Cluster{
-			Name: val.Name,
-			ID:   val.ID,
+	return named, nil
+}
+
+// providerMatches returns true if the provider or the driver of the cluster is one
+// of the providers (e.g. rke2, k3s, imported, eks)
+func providerMatches(cluster *clusterDetails, providers []string) bool {
+	for _, clusterProvider := range providers {
+		if strings.EqualFold(cluster.Provider, clusterProvider) || strings.EqualFold(cluster.Driver, clusterProvider) {
+			return true
 		}
-		clusters = append(clusters, cluster)
 	}
 
-	return clusters, nil
+	return false
+}
+
+// clusterFromDetails will convert the Rancher cluster details to a discovered cluster. The
+// labels of the Rancher cluster are used along with the driver used to provision it. The
+// authorized cluster endpoint is only used as the cluster's endpoint when the endpoint type
+// is ace, otherwise it's just added to the labels.
+func clusterFromDetails(details *clusterDetails, endpointType string) *discovery.Cluster {
+	cluster := &discovery.Cluster{
+		Name:   details.Name,
+		ID:     details.ID,
+		Labels: make(map[string]string),
+	}
+
+	for k, v := range details.Labels {
+		cluster.Labels[k] = v
+	}
+
+	if details.Driver != "" {
+		cluster.Labels[discovery.LabelDriver] = details.Driver
+	}
+
+	if details.Provider != "" {
+		cluster.Labels[providerLabel] = details.Provider
+	}
+
+	if details.State != "" {
+		state := details.State
+		cluster.Status = &state
+	}
+
+	switch {
+	case details.Version != nil && details.Version.GitVersion != "":
+		version := details.Version.GitVersion
+		cluster.Version = &version
+	case details.EngineConfig != nil && details.EngineConfig.Version != "":
+		version := details.EngineConfig.Version
+		cluster.Version = &version
+	}
+
+	ace := details.LocalClusterAuthEndpoint
+	if ace == nil || !ace.Enabled {
+		cluster.Labels[aceLabel] = aceDisabled
+		return cluster
+	}
+
+	// the authorized cluster endpoint details are always included so that they can be
+	// seen and selected on, only the endpoint of the cluster depends on the endpoint type
+	cluster.Labels[aceLabel] = aceEnabled
+	cluster.Labels[aceCALabel] = aceCAStatus(ace.CACerts)
+
+	if ace.FQDN != "" {
+		cluster.Labels[aceFQDNLabel] = ace.FQDN
+	}
+
+	if ace.CACerts != "" {
+		ca := base64.StdEncoding.EncodeToString([]byte(ace.CACerts))
+		cluster.CertificateAuthorityData = &ca
+	}
+
+	if endpointType == rancher.EndpointTypeACE && ace.FQDN != "" {
+		endpoint := "https://" + ace.FQDN
+		cluster.ControlPlaneEndpoint = &endpoint
+	}
+
+	return cluster
+}
+
+// aceCAStatus returns the status of the CA certificates of the authorized cluster endpoint.
+// No CA certificates means that the endpoint uses a certificate trusted by the system.
+func aceCAStatus(caCerts string) string {
+	if caCerts == "" {
+		return aceCASystem
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(caCerts)) {
+		return aceCAInvalid
+	}
+
+	return aceCAValid
 }
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rancher
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/fidelity/kconnect/pkg/rancher"
+)
+
+func Test_ClusterFromDetails(t *testing.T) {
+	caCert := testCACert(t)
+	encodedCA := base64.StdEncoding.EncodeToString([]byte(caCert))
+
+	testCases := []struct {
+		name         string
+		ace          *localClusterAuthEndpoint
+		endpointType string
+		expectLabels map[string]string
+		expectServer string
+		expectCA     string
+	}{
+		{
+			name:         "no authorized cluster endpoint",
+			endpointType: rancher.EndpointTypeACE,
+			expectLabels: map[string]string{aceLabel: aceDisabled},
+		},
+		{
+			name:         "authorized cluster endpoint disabled",
+			ace:          &localClusterAuthEndpoint{FQDN: "dev.example.com"},
+			endpointType: rancher.EndpointTypeACE,
+			expectLabels: map[string]string{aceLabel: aceDisabled},
+		},
+		{
+			name:         "proxy endpoint type",
+			ace:          &localClusterAuthEndpoint{Enabled: true, FQDN: "dev.example.com", CACerts: caCert},
+			endpointType: rancher.EndpointTypeProxy,
+			expectLabels: map[string]string{aceLabel: aceEnabled, aceFQDNLabel: "dev.example.com", aceCALabel: aceCAValid},
+			expectCA:     encodedCA,
+		},
+		{
+			name:         "default endpoint type",
+			ace:          &localClusterAuthEndpoint{Enabled: true, FQDN: "dev.example.com", CACerts: caCert},
+			expectLabels: map[string]string{aceLabel: aceEnabled, aceFQDNLabel: "dev.example.com", aceCALabel: aceCAValid},
+			expectCA:     encodedCA,
+		},
+		{
+			name:         "ace endpoint type",
+			ace:          &localClusterAuthEndpoint{Enabled: true, FQDN: "dev.example.com", CACerts: caCert},
+			endpointType: rancher.EndpointTypeACE,
+			expectLabels: map[string]string{aceLabel: aceEnabled, aceFQDNLabel: "dev.example.com", aceCALabel: aceCAValid},
+			expectServer: "https://dev.example.com",
+			expectCA:     encodedCA,
+		},
+		{
+			name:         "ace endpoint type with an invalid ca",
+			ace:          &localClusterAuthEndpoint{Enabled: true, FQDN: "dev.example.com", CACerts: "cert"},
+			endpointType: rancher.EndpointTypeACE,
+			expectLabels: map[string]string{aceLabel: aceEnabled, aceFQDNLabel: "dev.example.com", aceCALabel: aceCAInvalid},
+			expectServer: "https://dev.example.com",
+			expectCA:     base64.StdEncoding.EncodeToString([]byte("cert")),
+		},
+		{
+			name:         "ace endpoint type without fqdn or ca",
+			ace:          &localClusterAuthEndpoint{Enabled: true},
+			endpointType: rancher.EndpointTypeACE,
+			expectLabels: map[string]string{aceLabel: aceEnabled, aceCALabel: aceCASystem},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := clusterFromDetails(&clusterDetails{
+				ID:                       "c-abc12",
+				Name:                     "dev",
+				LocalClusterAuthEndpoint: tc.ace,
+			}, tc.endpointType)
+
+			if len(cluster.Labels) != len(tc.expectLabels) {
+				t.Fatalf("expected labels %v but got %v", tc.expectLabels, cluster.Labels)
+			}
+
+			for k, v := range tc.expectLabels {
+				if cluster.Labels[k] != v {
+					t.Fatalf("expected label %s=%s but got %v", k, v, cluster.Labels)
+				}
+			}
+
+			if server := stringValue(cluster.ControlPlaneEndpoint); server != tc.expectServer {
+				t.Fatalf("expected endpoint %q but got %q", tc.expectServer, server)
+			}
+
+			if ca := stringValue(cluster.CertificateAuthorityData); ca != tc.expectCA {
+				t.Fatalf("expected ca %q but got %q", tc.expectCA, ca)
+			}
+		})
+	}
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
+
+func testCACert(t *testing.T) string {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "dev-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("creating certificate: %s", err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
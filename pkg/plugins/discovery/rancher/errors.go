@@ -25,4 +25,7 @@ var (
 	ErrGettingKubeconfig       = errors.New("error getting kubeconfig from api")
 	ErrNoMatchingCluster       = errors.New("no clusters found that match name")
 	ErrMulitpleMatchingCluster = errors.New("multiple clusters found that match name")
+	ErrGettingProjects         = errors.New("error querying projects")
+	ErrGettingNamespaces       = errors.New("error querying namespaces")
+	ErrUnknownEndpointType     = errors.New("unknown endpoint type, must be proxy or ace")
 )
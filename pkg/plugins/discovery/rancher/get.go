@@ -38,10 +38,8 @@ func (p *rancherClusterProvider) GetCluster(ctx context.Context, input *discover
 		return nil, fmt.Errorf("getting cluster detail: %w", err)
 	}
 
-	cluster := &discovery.Cluster{
-		Name: clusterDetail.Name,
-		ID:   input.ClusterID,
-	}
+	cluster := clusterFromDetails(clusterDetail, p.config.EndpointType)
+	cluster.ID = input.ClusterID
 
 	return &discovery.GetClusterOutput{
 		Cluster: cluster,
@@ -38,6 +38,24 @@ const (
 
 	# Discover clusters via Rancher using a API key
 	{{.CommandPath}} use rancher --idp-protocol static-token --token ABCDEF
+
+	# Discover clusters via Rancher using a local Rancher user
+	{{.CommandPath}} use rancher --idp-protocol rancher-local --username admin
+
+	# Discover clusters via Rancher using a SAML or OIDC provider (e.g. Keycloak, Okta) in a browser
+	{{.CommandPath}} use rancher --idp-protocol rancher-sso
+
+	# Discover clusters via Rancher choosing from the auth providers enabled in Rancher
+	{{.CommandPath}} use rancher --idp-protocol rancher-auth
+
+	# Discover the active RKE2 and imported clusters via Rancher with names starting with dev
+	{{.CommandPath}} use rancher --idp-protocol rancher-ad --active-only --cluster-provider rke2,imported --cluster-name "dev-.*"
+
+	# Discover clusters via Rancher and choose the namespace from your Rancher projects
+	{{.CommandPath}} use rancher --idp-protocol rancher-ad --choose-namespace
+
+	# Connect directly to the cluster using the authorized cluster endpoint (ACE) if it's reachable
+	{{.CommandPath}} use rancher --idp-protocol rancher-ad --endpoint-type ace
   `
 )
 
@@ -49,7 +67,7 @@ func init() {
 			ConfigurationItemsFunc: ConfigurationItems,
 		},
 		CreateFunc:                 New,
-		SupportedIdentityProviders: []string{"static-token", "rancher-ad"},
+		SupportedIdentityProviders: []string{"static-token", "rancher-ad", "rancher-local", "rancher-openldap", "rancher-freeipa", "rancher-github", "rancher-sso", "rancher-auth"},
 	}); err != nil {
 		zap.S().Fatalw("Failed to register Rancher discovery plugin", "error", err)
 	}
@@ -75,8 +93,9 @@ type rancherClusterProviderConfig struct {
 }
 
 type rancherClusterProvider struct {
-	config *rancherClusterProviderConfig
-	token  string
+	config    *rancherClusterProviderConfig
+	configSet config.ConfigurationSet
+	token     string
 
 	httpClient  khttp.Client
 	interactive bool
@@ -110,6 +129,7 @@ func (p *rancherClusterProvider) setup(cs config.ConfigurationSet, userID identi
 	}
 
 	p.config = cfg
+	p.configSet = cs
 
 	id, ok := userID.(*identity.TokenIdentity)
 	if !ok {
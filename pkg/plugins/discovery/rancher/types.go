@@ -16,8 +16,44 @@ limitations under the License.
 
 package rancher
 
+// pagination holds the links to the other pages of a Rancher api collection
+type pagination struct {
+	Next string `json:"next,omitempty"`
+}
+
+// NextPage returns the url of the next page or an empty string if it's the last page
+func (p *pagination) NextPage() string {
+	if p == nil {
+		return ""
+	}
+
+	return p.Next
+}
+
 type listClustersResponse struct {
-	Clusters []clusterDetails `json:"data"`
+	Clusters   []clusterDetails `json:"data"`
+	Pagination *pagination      `json:"pagination,omitempty"`
+}
+
+type listProjectsResponse struct {
+	Projects   []projectDetails `json:"data"`
+	Pagination *pagination      `json:"pagination,omitempty"`
+}
+
+type listNamespacesResponse struct {
+	Namespaces []namespaceDetails `json:"data"`
+	Pagination *pagination        `json:"pagination,omitempty"`
+}
+
+type projectDetails struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type namespaceDetails struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ProjectID string `json:"projectId"`
 }
 
 type clusterDetails struct {
@@ -26,6 +62,25 @@ type clusterDetails struct {
 	Description  string                  `json:"description"`
 	EngineConfig *kubernetesEngineConfig `json:"rancherKuernetesEngineConfig,omitempty"`
 	Actions      map[string]string       `json:"actions"`
+	State        string                  `json:"state"`
+	Driver       string                  `json:"driver"`
+	Provider     string                  `json:"provider"`
+	Labels       map[string]string       `json:"labels,omitempty"`
+	Version      *versionInfo            `json:"version,omitempty"`
+
+	LocalClusterAuthEndpoint *localClusterAuthEndpoint `json:"localClusterAuthEndpoint,omitempty"`
+}
+
+// localClusterAuthEndpoint is the authorized cluster endpoint (ACE) of a cluster. When
+// enabled the cluster can be accessed directly instead of via the Rancher server.
+type localClusterAuthEndpoint struct {
+	Enabled bool   `json:"enabled"`
+	FQDN    string `json:"fqdn"`
+	CACerts string `json:"caCerts"`
+}
+
+type versionInfo struct {
+	GitVersion string `json:"gitVersion"`
 }
 
 type kubernetesEngineConfig struct {
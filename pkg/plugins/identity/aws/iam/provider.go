@@ -17,11 +17,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/versent/saml2aws/v2/pkg/awsconfig"
 	"go.uber.org/zap"
 
 	kaws "github.com/fidelity/kconnect/pkg/aws"
 	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/prompt"
 	"github.com/fidelity/kconnect/pkg/provider"
 	"github.com/fidelity/kconnect/pkg/provider/identity"
 	"github.com/fidelity/kconnect/pkg/provider/registry"
@@ -35,6 +40,7 @@ var (
 	ErrProfileWithAccessKey    = errors.New("cannot use profile with access-key")
 	ErrProfileWithSecretKey    = errors.New("cannot use profile with secret-key")
 	ErrAccessAndSecretRequired = errors.New("access-key and secret-key are both required")
+	ErrMFATokenRequired        = errors.New("mfa-token is required when using mfa-serial non-interactively")
 )
 
 func init() {
@@ -71,6 +77,18 @@ type providerConfig struct {
 	Region                   string `json:"region"`
 	Partition                string `json:"partition"`
 	AWSSharedCredentialsFile string `json:"aws-shared-credentials-file"`
+
+	AssumeRoleARN        string `json:"assume-role-arn"`
+	AssumeRoleExternalID string `json:"assume-role-external-id"`
+	RoleSessionName      string `json:"role-session-name"`
+	RoleSessionDuration  string `json:"role-session-duration"`
+	MFASerial            string `json:"mfa-serial"`
+	MFAToken             string `json:"mfa-token"`
+
+	CredentialSource     string `json:"credential-source"`
+	WebIdentityRoleARN   string `json:"web-identity-role-arn"`
+	WebIdentityTokenFile string `json:"web-identity-token-file"`
+	CredentialProcess    string `json:"credential-process"`
 }
 
 func (p *iamIdentityProvider) Name() string {
@@ -90,17 +108,57 @@ func (p *iamIdentityProvider) Authenticate(ctx context.Context, input *identity.
 		return nil, err
 	}
 
-	sess, err := kaws.NewSession(cfg.Region, cfg.Profile, cfg.AccessKey, cfg.SecretKey, cfg.SessionToken, cfg.AWSSharedCredentialsFile)
+	sess, source, err := kaws.NewSessionFromCredentials(ctx, &kaws.CredentialsConfig{
+		Source:                   kaws.CredentialSource(cfg.CredentialSource),
+		Region:                   cfg.Region,
+		Profile:                  cfg.Profile,
+		AccessKey:                cfg.AccessKey,
+		SecretKey:                cfg.SecretKey,
+		SessionToken:             cfg.SessionToken,
+		AWSSharedCredentialsFile: cfg.AWSSharedCredentialsFile,
+		WebIdentityRoleARN:       cfg.WebIdentityRoleARN,
+		WebIdentityTokenFile:     cfg.WebIdentityTokenFile,
+		CredentialProcess:        cfg.CredentialProcess,
+		RoleSessionName:          cfg.RoleSessionName,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("creating aws session: %w", err)
 	}
 
-	creds, err := sess.Credentials.Retrieve(context.TODO())
+	// The resolved source is recorded in the history so the same credentials are used again
+	if err := input.ConfigSet.SetValue(kaws.CredentialSourceConfigItem, string(source)); err != nil {
+		return nil, fmt.Errorf("setting %s: %w", kaws.CredentialSourceConfigItem, err)
+	}
+
+	creds, err := sess.Credentials.Retrieve(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("getting credentials: %w", err)
 	}
 
-	p.logger.Debugw("found aws iam credentials", "provider", creds.Source)
+	p.logger.Debugw("found aws iam credentials", "source", source, "provider", creds.Source)
+
+	if cfg.AssumeRoleARN != "" {
+		return p.assumeRoles(ctx, sess, cfg, input.ConfigSet)
+	}
+
+	// Temporary credentials that only kconnect knows how to get are saved to a profile
+	// so that they can be used by aws-iam-authenticator
+	if creds.CanExpire && source != kaws.CredentialSourceProfile && source != kaws.CredentialSourceDefault {
+		principalARN, err := kaws.GetCallerARN(ctx, *sess)
+		if err != nil {
+			return nil, err
+		}
+
+		return p.saveProfile(cfg, input.ConfigSet, &awsconfig.AWSCredentials{
+			AWSAccessKey:     creds.AccessKeyID,
+			AWSSecretKey:     creds.SecretAccessKey,
+			AWSSessionToken:  creds.SessionToken,
+			AWSSecurityToken: creds.SessionToken,
+			PrincipalARN:     principalARN,
+			Expires:          creds.Expires.Local(),
+			Region:           cfg.Region,
+		})
+	}
 
 	id := &kaws.Identity{
 		ProfileName:     cfg.Profile,
@@ -108,6 +166,86 @@ func (p *iamIdentityProvider) Authenticate(ctx context.Context, input *identity.
 		AWSSecretKey:    creds.SecretAccessKey,
 		AWSSessionToken: creds.SessionToken,
 		Region:          cfg.Region,
+		IDProviderName:  ProviderName,
+	}
+
+	if creds.CanExpire {
+		id.Expires = creds.Expires
+	}
+
+	return &identity.AuthenticateOutput{
+		Identity: id,
+	}, nil
+}
+
+// assumeRoles will assume the chain of roles and save the temporary credentials to a kconnect profile
+func (p *iamIdentityProvider) assumeRoles(ctx context.Context, sess *aws.Config, cfg *providerConfig, cs config.ConfigurationSet) (*identity.AuthenticateOutput, error) {
+	var duration time.Duration
+
+	if cfg.RoleSessionDuration != "" {
+		var err error
+		if duration, err = time.ParseDuration(cfg.RoleSessionDuration); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", kaws.RoleSessionDurationConfigItem, err)
+		}
+	}
+
+	if cfg.MFASerial != "" && cfg.MFAToken == "" {
+		if !p.interactive {
+			return nil, ErrMFATokenRequired
+		}
+
+		if err := prompt.InputAndSet(cs, kaws.MFATokenConfigItem, "MFA token code:", true); err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", kaws.MFATokenConfigItem, err)
+		}
+
+		cfg.MFAToken = cs.ValueString(kaws.MFATokenConfigItem)
+	}
+
+	p.logger.Infow("assuming aws roles", "roles", cfg.AssumeRoleARN)
+
+	awsCreds, err := kaws.AssumeRoleChain(ctx, *sess, &kaws.AssumeRoleInput{
+		RoleARNs:     kaws.SplitList(cfg.AssumeRoleARN),
+		ExternalIDs:  kaws.SplitList(cfg.AssumeRoleExternalID),
+		SessionName:  cfg.RoleSessionName,
+		Duration:     duration,
+		MFASerial:    cfg.MFASerial,
+		MFATokenCode: cfg.MFAToken,
+		Region:       cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("assuming roles: %w", err)
+	}
+
+	return p.saveProfile(cfg, cs, awsCreds)
+}
+
+// saveProfile will save the temporary credentials to a kconnect profile in the AWS credentials
+// file, in the same way as the saml identity provider.
+func (p *iamIdentityProvider) saveProfile(cfg *providerConfig, cs config.ConfigurationSet, awsCreds *awsconfig.AWSCredentials) (*identity.AuthenticateOutput, error) {
+	identifier, err := kaws.CreateIDFromCreds(awsCreds)
+	if err != nil {
+		return nil, fmt.Errorf("creating identifier from AWS creds: %w", err)
+	}
+
+	profileName := kaws.ProfileName(identifier)
+
+	item, err := cs.String("aws-profile", profileName, "AWS profile name to use")
+	if err != nil {
+		return nil, fmt.Errorf("setting aws-profile: %w", err)
+	}
+
+	item.Value = profileName
+
+	id := kaws.MapCredsToIdentity(awsCreds, profileName, cfg.AWSSharedCredentialsFile)
+	id.IDProviderName = ProviderName
+
+	store, err := kaws.NewIdentityStore(profileName, ProviderName, cfg.AWSSharedCredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("creating identity store: %w", err)
+	}
+
+	if err := store.Save(id); err != nil {
+		return nil, fmt.Errorf("saving identity: %w", err)
 	}
 
 	return &identity.AuthenticateOutput{
@@ -132,6 +270,10 @@ func (p *iamIdentityProvider) validateConfig(cfg *providerConfig) error {
 		return ErrAccessAndSecretRequired
 	}
 
+	if cfg.CredentialSource != "" && !slices.Contains(kaws.CredentialSources, kaws.CredentialSource(cfg.CredentialSource)) {
+		return fmt.Errorf("validating %s %s: %w", kaws.CredentialSourceConfigItem, cfg.CredentialSource, kaws.ErrUnknownCredentialSource)
+	}
+
 	return nil
 }
 
@@ -143,6 +285,8 @@ func ConfigurationItems(scopeTo string) (config.ConfigurationSet, error) {
 	kaws.AddRegionConfig(cs)
 	kaws.AddPartitionConfig(cs)
 	kaws.AddIAMConfigs(cs)
+	kaws.AddAssumeRoleConfigs(cs)
+	kaws.AddCredentialSourceConfigs(cs)
 
 	return cs, nil
 }
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
 
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
@@ -38,7 +39,8 @@ import (
 )
 
 const (
-	ProviderName = "aad"
+	ProviderName        = "aad"
+	LoginModeConfigItem = "aad-login-mode"
 )
 
 var (
@@ -81,9 +83,10 @@ type aadIdentityProvider struct {
 type aadConfig struct {
 	common.IdentityProviderConfig
 
-	TenantID string           `json:"tenant-id" validate:"required"`
-	ClientID string           `json:"client-id" validate:"required"`
-	AADHost  identity.AADHost `json:"aad-host"  validate:"required"`
+	TenantID  string             `json:"tenant-id" validate:"required"`
+	ClientID  string             `json:"client-id" validate:"required"`
+	AADHost   identity.AADHost   `json:"aad-host"  validate:"required"`
+	LoginMode identity.LoginMode `json:"aad-login-mode"`
 }
 
 func (p *aadIdentityProvider) Name() string {
@@ -103,6 +106,14 @@ func (p *aadIdentityProvider) Authenticate(ctx context.Context, input *provid.Au
 		return nil, fmt.Errorf("unmarshalling config into use aadconfig: %w", err)
 	}
 
+	if id := p.cachedIdentity(cfg.TenantID, cfg.ClientID, cfg.Username, cfg.Password); id != nil {
+		p.logger.Info("using cached azure ad tokens")
+
+		return &provid.AuthenticateOutput{
+			Identity: id,
+		}, nil
+	}
+
 	if err := p.validateConfig(cfg); err != nil {
 		return nil, err
 	}
@@ -118,6 +129,10 @@ func (p *aadIdentityProvider) Authenticate(ctx context.Context, input *provid.Au
 		Password: cfg.Password,
 	}
 
+	if cfg.LoginMode == identity.LoginModeDeviceCode || cfg.LoginMode == identity.LoginModeBrowser {
+		return p.authenticateInteractive(authCfg, cfg.LoginMode)
+	}
+
 	endpointResolver := identity.NewOAuthEndpointsResolver(p.httpClient)
 
 	endpoints, err := endpointResolver.Resolve(authCfg.Authority)
@@ -154,16 +169,75 @@ func (p *aadIdentityProvider) Authenticate(ctx context.Context, input *provid.Au
 		}
 	}
 
-	id := identity.NewActiveDirectoryIdentity(authCfg, userRealm, ProviderName, p.httpClient, interactiveLoginRequired)
+	id := identity.NewActiveDirectoryIdentity(authCfg, userRealm, ProviderName, p.httpClient, interactiveLoginRequired, identity.WithTokenCache(identity.NewTokenCache()))
+
+	return &provid.AuthenticateOutput{
+		Identity: id,
+	}, nil
+}
+
+// authenticateInteractive will create an identity that logs in using the device code or browser
+// flows. The user logs in when the first token is requested and the refresh token from that login
+// is used to get the tokens for other resources.
+func (p *aadIdentityProvider) authenticateInteractive(authCfg *identity.AuthenticationConfig, mode identity.LoginMode) (*provid.AuthenticateOutput, error) {
+	endpointResolver := identity.NewOIDCEndpointsResolver(p.httpClient)
+
+	endpoints, err := endpointResolver.Resolve(authCfg.Authority)
+	if err != nil {
+		return nil, fmt.Errorf("getting endpoints: %w", err)
+	}
+
+	authCfg.Endpoints = endpoints
+
+	id := identity.NewActiveDirectoryIdentity(authCfg, &identity.UserRealm{}, ProviderName, p.httpClient, true, identity.WithLoginMode(mode), identity.WithTokenCache(identity.NewTokenCache()))
 
 	return &provid.AuthenticateOutput{
 		Identity: id,
 	}, nil
 }
 
+// cachedIdentity returns an identity that uses the cached tokens for the tenant and client so that
+// the user doesn't need to login again. Nil is returned if a password has been supplied or there
+// isn't a cached refresh token that can be used.
+func (p *aadIdentityProvider) cachedIdentity(tenantID, clientID, username, password string) provid.Identity {
+	if tenantID == "" || clientID == "" || password != "" {
+		return nil
+	}
+
+	store := identity.NewIdentityStore(identity.NewTokenCache(), p.httpClient, tenantID, clientID, username, ProviderName)
+	if store.Expired() {
+		return nil
+	}
+
+	id, err := store.Load()
+	if err != nil {
+		p.logger.Debugw("failed loading cached azure ad tokens", "error", err.Error())
+		return nil
+	}
+
+	return id
+}
+
 func (p *aadIdentityProvider) validateConfig(cfg *aadConfig) error {
+	if cfg.LoginMode == "" {
+		cfg.LoginMode = identity.LoginModePassword
+	}
+
+	if !slices.Contains(identity.LoginModes, cfg.LoginMode) {
+		return fmt.Errorf("validating aad config %s: %w", cfg.LoginMode, identity.ErrUnknownLoginMode)
+	}
+
 	validate := validator.New()
-	if err := validate.Struct(cfg); err != nil {
+
+	// The username and password aren't needed when logging in interactively
+	var err error
+	if cfg.LoginMode == identity.LoginModePassword {
+		err = validate.Struct(cfg)
+	} else {
+		err = validate.StructExcept(cfg, "IdentityProviderConfig.Username", "IdentityProviderConfig.Password")
+	}
+
+	if err != nil {
 		return fmt.Errorf("validating aad config: %w", err)
 	}
 
@@ -183,6 +257,8 @@ func ConfigurationItems(scopeTo string) (config.ConfigurationSet, error) {
 	cs.String(azure.ClientIDConfigItem, "04b07795-8ddb-461a-bbee-02f9e1bf7b46", "The azure ad client id") //nolint: errcheck
 	cs.String(azure.AADHostConfigItem, string(identity.AADHostWorldwide), "The AAD host to use")          //nolint: errcheck
 
+	cs.String(LoginModeConfigItem, string(identity.LoginModePassword), "How to login to Azure AD. Possible values: password,devicecode,browser. Use devicecode or browser when MFA or conditional access is required") //nolint: errcheck
+
 	cs.SetShort(azure.TenantIDConfigItem, "t") //nolint: errcheck
 	cs.SetRequired(azure.TenantIDConfigItem)   //nolint: errcheck
 
@@ -32,14 +32,6 @@ func (p *aadIdentityProvider) resolveConfig(cfg config.ConfigurationSet) error {
 		return nil
 	}
 
-	if err := prompt.InputAndSet(cfg, defaults.UsernameConfigItem, "Username:", true); err != nil {
-		return fmt.Errorf("resolving %s: %w", defaults.UsernameConfigItem, err)
-	}
-
-	if err := prompt.InputSensitiveAndSet(cfg, defaults.PasswordConfigItem, "Password:", true); err != nil {
-		return fmt.Errorf("resolving %s: %w", defaults.PasswordConfigItem, err)
-	}
-
 	if err := prompt.InputAndSet(cfg, azure.TenantIDConfigItem, "Enter the Azure tenant ID", true); err != nil {
 		return fmt.Errorf("resolving %s: %w", azure.TenantIDConfigItem, err)
 	}
@@ -52,9 +44,35 @@ func (p *aadIdentityProvider) resolveConfig(cfg config.ConfigurationSet) error {
 		return fmt.Errorf("resolving %s: %w", azure.ClientIDConfigItem, err)
 	}
 
+	// The user enters their credentials in the browser when logging in interactively and
+	// doesn't need to enter them if there are cached tokens
+	loginMode := cfg.ValueString(LoginModeConfigItem)
+	if loginMode == "" || identity.LoginMode(loginMode) == identity.LoginModePassword {
+		if p.hasCachedIdentity(cfg) {
+			return nil
+		}
+
+		if err := prompt.InputAndSet(cfg, defaults.UsernameConfigItem, "Username:", true); err != nil {
+			return fmt.Errorf("resolving %s: %w", defaults.UsernameConfigItem, err)
+		}
+
+		if err := prompt.InputSensitiveAndSet(cfg, defaults.PasswordConfigItem, "Password:", true); err != nil {
+			return fmt.Errorf("resolving %s: %w", defaults.PasswordConfigItem, err)
+		}
+	}
+
 	return nil
 }
 
+func (p *aadIdentityProvider) hasCachedIdentity(cfg config.ConfigurationSet) bool {
+	return p.cachedIdentity(
+		cfg.ValueString(azure.TenantIDConfigItem),
+		cfg.ValueString(azure.ClientIDConfigItem),
+		cfg.ValueString(defaults.UsernameConfigItem),
+		cfg.ValueString(defaults.PasswordConfigItem),
+	) != nil
+}
+
 func aadHostOptions() (map[string]string, error) {
 	return map[string]string{
 		"Worldwide (recommended)": string(identity.AADHostWorldwide),
@@ -15,13 +15,8 @@ package oidc
 
 import (
 	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"encoding/json"
 	"fmt"
-	"log"
-	"net/http"
-	"os"
 	"os/exec"
 	"strings"
 
@@ -58,15 +53,21 @@ func init() {
 
 // New will create a new OIDC identity provider
 func New(input *provider.PluginCreationInput) (identity.Provider, error) {
+	if input.HTTPClient == nil {
+		return nil, provider.ErrHTTPClientRequired
+	}
+
 	return &oidcIdentityProvider{
 		logger:      input.Logger,
 		interactive: input.IsInteractive,
+		httpClient:  input.HTTPClient,
 	}, nil
 }
 
 type oidcIdentityProvider struct {
 	logger      *zap.SugaredLogger
 	interactive bool
+	httpClient  khttp.Client
 }
 
 type providerConfig struct {
@@ -186,21 +187,13 @@ func (p *oidcIdentityProvider) getConfigFromUrl(configSet config.ConfigurationSe
 }
 
 func readConfigs(p *oidcIdentityProvider, configSet config.ConfigurationSet, configValue string) {
-	if getValue(configSet, "skip-ssl") == True {
-		SetTransport("")
-	} else {
-		caCert := getValue(configSet, "ca-cert")
-		if caCert != "" {
-			SetTransport(caCert)
-		} else {
-			p.logger.Warnf("CA cert is required to call the config url.")
-			p.logger.Info(FailCallConfigUrl)
-
-			return
-		}
-	}
+	kclient, err := p.configURLClient(configSet)
+	if err != nil {
+		p.logger.Errorf("Error creating http client for the config URL, error is: %s", err)
+		p.logger.Info(FailCallConfigUrl)
 
-	kclient := khttp.NewHTTPClient()
+		return
+	}
 
 	res, err := kclient.Get(configValue, nil)
 	if err == nil {
@@ -211,6 +204,27 @@ func readConfigs(p *oidcIdentityProvider, configSet config.ConfigurationSet, con
 	}
 }
 
+// configURLClient returns the http client used to call the config url. If skip-ssl or ca-cert
+// are set a dedicated client is created from the settings of the configured http client,
+// otherwise the configured http client is used.
+func (p *oidcIdentityProvider) configURLClient(configSet config.ConfigurationSet) (khttp.Client, error) {
+	skipSSL := getValue(configSet, "skip-ssl") == True
+	caCert := getValue(configSet, "ca-cert")
+
+	if !skipSSL && caCert == "" {
+		return p.httpClient, nil
+	}
+
+	cfg := khttp.ConfigForClient(p.httpClient)
+	cfg.InsecureSkipVerify = skipSSL
+
+	if !skipSSL {
+		cfg.CABundle = caCert
+	}
+
+	return khttp.NewHTTPClientWithConfig(cfg)
+}
+
 func addItems(p *oidcIdentityProvider, configSet config.ConfigurationSet, body string) {
 	appConfiguration := &kconnectv1alpha.Configuration{}
 	if err := json.Unmarshal([]byte(body), appConfiguration); err == nil {
@@ -234,32 +248,6 @@ func addItem(configSet config.ConfigurationSet, key string, value string) {
 	}
 }
 
-func SetTransport(file string) {
-	var config *tls.Config
-
-	skipReadCert := false
-	if file == "" {
-		skipReadCert = true
-	}
-
-	if file != "" {
-		caCert, err := os.ReadFile(file)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(caCert)
-		config = &tls.Config{
-			RootCAs: caCertPool,
-		}
-	} else {
-		config = &tls.Config{InsecureSkipVerify: skipReadCert}
-	}
-
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = config
-}
-
 func getValue(configSet config.ConfigurationSet, key string) (value string) {
 	if configSet.Get(key) != nil {
 		val := configSet.Get(key).Value
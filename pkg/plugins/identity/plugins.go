@@ -23,6 +23,9 @@ import (
 	_ "github.com/fidelity/kconnect/pkg/plugins/identity/azure/env"
 	_ "github.com/fidelity/kconnect/pkg/plugins/identity/oidc"
 	_ "github.com/fidelity/kconnect/pkg/plugins/identity/rancher/activedirectory"
+	_ "github.com/fidelity/kconnect/pkg/plugins/identity/rancher/auth"
+	_ "github.com/fidelity/kconnect/pkg/plugins/identity/rancher/browser"
+	_ "github.com/fidelity/kconnect/pkg/plugins/identity/rancher/password"
 	_ "github.com/fidelity/kconnect/pkg/plugins/identity/saml"
 	_ "github.com/fidelity/kconnect/pkg/plugins/identity/static/token"
 )
@@ -0,0 +1,182 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/pkg/config"
+	khttp "github.com/fidelity/kconnect/pkg/http"
+	"github.com/fidelity/kconnect/pkg/plugins/identity/rancher/activedirectory"
+	"github.com/fidelity/kconnect/pkg/plugins/identity/rancher/browser"
+	"github.com/fidelity/kconnect/pkg/plugins/identity/rancher/password"
+	"github.com/fidelity/kconnect/pkg/prompt"
+	"github.com/fidelity/kconnect/pkg/provider"
+	"github.com/fidelity/kconnect/pkg/provider/identity"
+	"github.com/fidelity/kconnect/pkg/provider/registry"
+	"github.com/fidelity/kconnect/pkg/rancher"
+)
+
+const (
+	ProviderName = "rancher-auth"
+)
+
+var (
+	ErrAddingCommonCfg       = errors.New("adding common identity config")
+	ErrMultipleAuthProviders = errors.New("multiple auth providers are enabled in Rancher, use --idp-protocol to choose one")
+)
+
+func init() {
+	if err := registry.RegisterIdentityPlugin(&registry.IdentityPluginRegistration{
+		PluginRegistration: registry.PluginRegistration{
+			Name:                   ProviderName,
+			UsageExample:           "",
+			ConfigurationItemsFunc: password.ConfigurationItems,
+		},
+		CreateFunc: New,
+	}); err != nil {
+		zap.S().Fatalw("Failed to register Rancher auth identity plugin", "error", err)
+	}
+}
+
+// New will create a new Rancher identity provider that queries Rancher for the enabled
+// auth providers and lets the user choose which one to login with
+func New(input *provider.PluginCreationInput) (identity.Provider, error) {
+	if input.HTTPClient == nil {
+		return nil, provider.ErrHTTPClientRequired
+	}
+
+	return &authIdentityProvider{
+		creationInput: input,
+		logger:        input.Logger,
+		interactive:   input.IsInteractive,
+		httpClient:    input.HTTPClient,
+	}, nil
+}
+
+type authIdentityProvider struct {
+	creationInput *provider.PluginCreationInput
+	interactive   bool
+	logger        *zap.SugaredLogger
+	httpClient    khttp.Client
+}
+
+func (p *authIdentityProvider) Name() string {
+	return ProviderName
+}
+
+// Authenticate will choose the identity provider to use based on the auth providers
+// enabled in Rancher and then authenticate the user with it.
+func (p *authIdentityProvider) Authenticate(ctx context.Context, input *identity.AuthenticateInput) (*identity.AuthenticateOutput, error) {
+	if p.interactive {
+		if err := rancher.ResolveCommon(input.ConfigSet); err != nil {
+			return nil, fmt.Errorf("resolving common Rancher config: %w", err)
+		}
+	}
+
+	cfg := &rancher.CommonConfig{}
+	if err := config.Unmarshall(input.ConfigSet, cfg); err != nil {
+		return nil, fmt.Errorf("unmarshalling config into use rancher config: %w", err)
+	}
+
+	resolver, err := rancher.NewStaticEndpointsResolver(cfg.APIEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("creating endpoint resolver: %w", err)
+	}
+
+	enabled, err := rancher.ListAuthProviders(p.httpClient, resolver)
+	if err != nil {
+		return nil, err
+	}
+
+	providerName, err := p.chooseProvider(enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logger.Infow("using Rancher identity provider", "name", providerName)
+
+	registration, err := registry.GetIdentityProviderRegistration(providerName)
+	if err != nil {
+		return nil, fmt.Errorf("getting identity provider %s: %w", providerName, err)
+	}
+
+	idProvider, err := registration.CreateFunc(p.creationInput)
+	if err != nil {
+		return nil, fmt.Errorf("creating identity provider %s: %w", providerName, err)
+	}
+
+	return idProvider.Authenticate(ctx, input)
+}
+
+// chooseProvider will ask the user to choose from the identity providers that can be used
+// with the enabled auth providers. If only 1 can be used it's automatically chosen.
+func (p *authIdentityProvider) chooseProvider(enabled []rancher.AuthProvider) (string, error) {
+	options := map[string]string{}
+
+	for _, authProvider := range enabled {
+		if name := IdentityProviderFor(authProvider.ID); name != "" {
+			options[fmt.Sprintf("%s (%s)", authProvider.ID, name)] = name
+		}
+	}
+
+	if len(options) == 0 {
+		return "", rancher.ErrNoEnabledAuthProviders
+	}
+
+	if len(options) > 1 && !p.interactive {
+		names := []string{}
+		for _, name := range options {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+
+		slices.Sort(names)
+
+		return "", fmt.Errorf("valid idp protocols are [%s]: %w", strings.Join(names, ","), ErrMultipleAuthProviders)
+	}
+
+	return prompt.Choose("auth-provider", "Select the Rancher auth provider", true, prompt.OptionsFromMap(options))
+}
+
+// IdentityProviderFor returns the name of the identity provider to use with the Rancher
+// auth provider. An empty string is returned if the auth provider isn't supported.
+func IdentityProviderFor(authProvider string) string {
+	switch {
+	case authProvider == rancher.AuthProviderActiveDirectory:
+		return activedirectory.ProviderName
+	case authProvider == rancher.AuthProviderLocal:
+		return password.LocalProviderName
+	case authProvider == rancher.AuthProviderOpenLDAP:
+		return password.OpenLDAPProviderName
+	case authProvider == rancher.AuthProviderFreeIPA:
+		return password.FreeIPAProviderName
+	case authProvider == rancher.AuthProviderGitHub:
+		return browser.GitHubProviderName
+	case slices.Contains(rancher.BrowserAuthProviders, authProvider):
+		return browser.SSOProviderName
+	default:
+		return ""
+	}
+}
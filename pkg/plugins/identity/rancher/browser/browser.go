@@ -0,0 +1,207 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/pkg/config"
+	khttp "github.com/fidelity/kconnect/pkg/http"
+	"github.com/fidelity/kconnect/pkg/provider"
+	"github.com/fidelity/kconnect/pkg/provider/identity"
+	"github.com/fidelity/kconnect/pkg/provider/registry"
+	"github.com/fidelity/kconnect/pkg/rancher"
+	"github.com/fidelity/kconnect/pkg/utils"
+)
+
+const (
+	GitHubProviderName = "rancher-github"
+	SSOProviderName    = "rancher-sso"
+
+	loginPollInterval = 5 * time.Second
+	loginTimeout      = 5 * time.Minute
+)
+
+var (
+	ErrAddingCommonCfg = errors.New("adding common identity config")
+)
+
+// providers maps the name of the identity plugins to the Rancher auth providers they can use
+var providers = map[string][]string{
+	GitHubProviderName: {rancher.AuthProviderGitHub},
+	SSOProviderName:    rancher.BrowserAuthProviders,
+}
+
+func init() {
+	for name, authProviders := range providers {
+		if err := registry.RegisterIdentityPlugin(&registry.IdentityPluginRegistration{
+			PluginRegistration: registry.PluginRegistration{
+				Name:                   name,
+				UsageExample:           "",
+				ConfigurationItemsFunc: ConfigurationItems,
+			},
+			CreateFunc: NewCreateFunc(name, authProviders),
+		}); err != nil {
+			zap.S().Fatalw("Failed to register Rancher identity plugin", "name", name, "error", err)
+		}
+	}
+}
+
+// NewCreateFunc returns a function that creates a Rancher identity provider that logs in via
+// the Rancher dashboard in a browser using one of the Rancher auth providers
+func NewCreateFunc(name string, authProviders []string) identity.ProviderCreatorFun {
+	return func(input *provider.PluginCreationInput) (identity.Provider, error) {
+		if input.HTTPClient == nil {
+			return nil, provider.ErrHTTPClientRequired
+		}
+
+		return &browserIdentityProvider{
+			name:          name,
+			authProviders: authProviders,
+			logger:        input.Logger,
+			interactive:   input.IsInteractive,
+			httpClient:    input.HTTPClient,
+			openURL:       openURL,
+		}, nil
+	}
+}
+
+type browserIdentityProvider struct {
+	name          string
+	authProviders []string
+	interactive   bool
+	logger        *zap.SugaredLogger
+	httpClient    khttp.Client
+	openURL       func(url string) error
+}
+
+type browserConfig struct {
+	rancher.CommonConfig
+}
+
+func (p *browserIdentityProvider) Name() string {
+	return p.name
+}
+
+// Authenticate will authenticate a user via the Rancher dashboard and return
+// details of their identity. A previously created token is reused if it's still valid.
+func (p *browserIdentityProvider) Authenticate(ctx context.Context, input *identity.AuthenticateInput) (*identity.AuthenticateOutput, error) {
+	p.logger.Info("authenticating user via browser")
+
+	if p.interactive {
+		if err := rancher.ResolveCommon(input.ConfigSet); err != nil {
+			return nil, fmt.Errorf("resolving common Rancher config: %w", err)
+		}
+	}
+
+	cfg := &browserConfig{}
+	if err := config.Unmarshall(input.ConfigSet, cfg); err != nil {
+		return nil, fmt.Errorf("unmarshalling config into use browserConfig: %w", err)
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(cfg); err != nil {
+		return nil, fmt.Errorf("validating %s config: %w", p.name, err)
+	}
+
+	resolver, err := rancher.NewStaticEndpointsResolver(cfg.APIEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("creating endpoint resolver: %w", err)
+	}
+
+	// The username isn't known before logging in via the browser so the token is stored
+	// against the api endpoint only
+	store, err := rancher.NewIdentityStore(cfg.APIEndpoint, "", p.name)
+	if err != nil {
+		return nil, fmt.Errorf("creating identity store: %w", err)
+	}
+
+	if id := rancher.LoadValidIdentity(p.httpClient, resolver, store); id != nil {
+		p.logger.Info("reusing stored Rancher token")
+
+		return &identity.AuthenticateOutput{
+			Identity: id,
+		}, nil
+	}
+
+	if err := rancher.CheckAuthProviderEnabled(p.httpClient, resolver, p.authProviders...); err != nil {
+		return nil, err
+	}
+
+	token, err := rancher.BrowserLogin(ctx, &rancher.BrowserLoginInput{
+		HTTPClient:   p.httpClient,
+		Resolver:     resolver,
+		OpenURL:      p.openURL,
+		PollInterval: loginPollInterval,
+		Timeout:      loginTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("logging in via browser: %w", err)
+	}
+
+	userID, err := rancher.GetCurrentUserID(p.httpClient, resolver, token)
+	if err != nil {
+		return nil, err
+	}
+
+	id := identity.NewTokenIdentity(userID, token, p.name)
+
+	// The token is created by the dashboard so its expiry comes from the Rancher settings
+	if details, err := rancher.GetToken(p.httpClient, resolver, token, rancher.TokenName(token)); err == nil {
+		id.WithExpiry(details.Expiry())
+	}
+
+	if err := store.Save(id); err != nil {
+		p.logger.Warnw("failed saving Rancher token", "error", err.Error())
+	}
+
+	return &identity.AuthenticateOutput{
+		Identity: id,
+	}, nil
+}
+
+// openURL will display the login url and try to open it in the browser. Failing to open the
+// browser isn't an error as the user can open the url themselves.
+func openURL(url string) error {
+	fmt.Fprintf(os.Stderr, "Login to Rancher using the following url:\n\n%s\n\n", url)
+
+	if err := utils.OpenBrowser(url); err != nil {
+		zap.S().Debugw("failed opening browser", "error", err.Error())
+	}
+
+	return nil
+}
+
+// ConfigurationItems will return the configuration items for the intentity plugin based
+// of the cluster provider that its being used in conjunction with
+func ConfigurationItems(scopeTo string) (config.ConfigurationSet, error) {
+	cs := config.NewConfigurationSet()
+	cs.String("idp-protocol", "", "The idp protocol to use (e.g. saml). Each protocol has its own flags.") //nolint:errcheck
+
+	if err := rancher.AddCommonConfig(cs); err != nil {
+		return nil, ErrAddingCommonCfg
+	}
+
+	return cs, nil
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package password
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+
+	"github.com/fidelity/kconnect/pkg/config"
+	khttp "github.com/fidelity/kconnect/pkg/http"
+	"github.com/fidelity/kconnect/pkg/provider"
+	"github.com/fidelity/kconnect/pkg/provider/common"
+	"github.com/fidelity/kconnect/pkg/provider/identity"
+	"github.com/fidelity/kconnect/pkg/provider/registry"
+	"github.com/fidelity/kconnect/pkg/rancher"
+)
+
+const (
+	LocalProviderName    = "rancher-local"
+	OpenLDAPProviderName = "rancher-openldap"
+	FreeIPAProviderName  = "rancher-freeipa"
+)
+
+var (
+	ErrAddingCommonCfg = errors.New("adding common identity config")
+)
+
+// providers maps the name of the identity plugins to the Rancher auth provider they use
+var providers = map[string]string{
+	LocalProviderName:    rancher.AuthProviderLocal,
+	OpenLDAPProviderName: rancher.AuthProviderOpenLDAP,
+	FreeIPAProviderName:  rancher.AuthProviderFreeIPA,
+}
+
+func init() {
+	for name, authProvider := range providers {
+		if err := registry.RegisterIdentityPlugin(&registry.IdentityPluginRegistration{
+			PluginRegistration: registry.PluginRegistration{
+				Name:                   name,
+				UsageExample:           "",
+				ConfigurationItemsFunc: ConfigurationItems,
+			},
+			CreateFunc: NewCreateFunc(name, authProvider),
+		}); err != nil {
+			zap.S().Fatalw("Failed to register Rancher identity plugin", "name", name, "error", err)
+		}
+	}
+}
+
+// NewCreateFunc returns a function that creates a Rancher identity provider that logs in
+// with a username and password using the Rancher auth provider
+func NewCreateFunc(name, authProvider string) identity.ProviderCreatorFun {
+	return func(input *provider.PluginCreationInput) (identity.Provider, error) {
+		if input.HTTPClient == nil {
+			return nil, provider.ErrHTTPClientRequired
+		}
+
+		return &passwordIdentityProvider{
+			name:         name,
+			authProvider: authProvider,
+			logger:       input.Logger,
+			interactive:  input.IsInteractive,
+			httpClient:   input.HTTPClient,
+		}, nil
+	}
+}
+
+type passwordIdentityProvider struct {
+	name         string
+	authProvider string
+	interactive  bool
+	logger       *zap.SugaredLogger
+	httpClient   khttp.Client
+}
+
+type passwordConfig struct {
+	common.IdentityProviderConfig
+	rancher.CommonConfig
+	rancher.TokenConfig
+}
+
+func (p *passwordIdentityProvider) Name() string {
+	return p.name
+}
+
+// Authenticate will authenticate a user and return details of
+// their identity. A previously created token is reused if it's still valid.
+func (p *passwordIdentityProvider) Authenticate(ctx context.Context, input *identity.AuthenticateInput) (*identity.AuthenticateOutput, error) {
+	p.logger.Infow("authenticating user", "auth-provider", p.authProvider)
+
+	if err := p.resolveEndpoint(input.ConfigSet); err != nil {
+		return nil, fmt.Errorf("resolving config: %w", err)
+	}
+
+	cfg := &passwordConfig{}
+	if err := config.Unmarshall(input.ConfigSet, cfg); err != nil {
+		return nil, fmt.Errorf("unmarshalling config into use passwordConfig: %w", err)
+	}
+
+	resolver, err := rancher.NewStaticEndpointsResolver(cfg.APIEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("creating endpoint resolver: %w", err)
+	}
+
+	// The auth provider is checked before asking for the credentials
+	if err := rancher.CheckAuthProviderEnabled(p.httpClient, resolver, p.authProvider); err != nil {
+		return nil, err
+	}
+
+	if err := p.resolveUsername(input.ConfigSet); err != nil {
+		return nil, fmt.Errorf("resolving config: %w", err)
+	}
+
+	if err := config.Unmarshall(input.ConfigSet, cfg); err != nil {
+		return nil, fmt.Errorf("unmarshalling config into use passwordConfig: %w", err)
+	}
+
+	if cfg.Username != "" {
+		if id := p.storedIdentity(cfg); id != nil {
+			p.logger.Info("reusing stored Rancher token")
+
+			return &identity.AuthenticateOutput{
+				Identity: id,
+			}, nil
+		}
+	}
+
+	if err := p.resolvePassword(input.ConfigSet); err != nil {
+		return nil, fmt.Errorf("resolving password: %w", err)
+	}
+
+	if err := config.Unmarshall(input.ConfigSet, cfg); err != nil {
+		return nil, fmt.Errorf("unmarshalling config into use passwordConfig: %w", err)
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(cfg); err != nil {
+		return nil, fmt.Errorf("validating %s config: %w", p.name, err)
+	}
+
+	ttl, err := time.ParseDuration(cfg.TokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", rancher.TokenTTLConfigName, err)
+	}
+
+	loginResponse, err := rancher.Login(p.httpClient, resolver, p.authProvider, cfg.Username, cfg.Password, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	id := identity.NewTokenIdentity(loginResponse.UserID, loginResponse.Token, p.name).WithExpiry(loginResponse.Expiry())
+	p.saveIdentity(cfg, id)
+
+	return &identity.AuthenticateOutput{
+		Identity: id,
+	}, nil
+}
+
+func (p *passwordIdentityProvider) storedIdentity(cfg *passwordConfig) *identity.TokenIdentity {
+	resolver, err := rancher.NewStaticEndpointsResolver(cfg.APIEndpoint)
+	if err != nil {
+		return nil
+	}
+
+	store, err := rancher.NewIdentityStore(cfg.APIEndpoint, cfg.Username, p.name)
+	if err != nil {
+		return nil
+	}
+
+	return rancher.LoadValidIdentity(p.httpClient, resolver, store)
+}
+
+// saveIdentity will save the token so that it can be reused. Failing to save the token
+// isn't an error as the user can still connect.
+func (p *passwordIdentityProvider) saveIdentity(cfg *passwordConfig, id *identity.TokenIdentity) {
+	store, err := rancher.NewIdentityStore(cfg.APIEndpoint, cfg.Username, p.name)
+	if err == nil {
+		err = store.Save(id)
+	}
+
+	if err != nil {
+		p.logger.Warnw("failed saving Rancher token", "error", err.Error())
+	}
+}
+
+// ConfigurationItems will return the configuration items for the intentity plugin based
+// of the cluster provider that its being used in conjunction with
+func ConfigurationItems(scopeTo string) (config.ConfigurationSet, error) {
+	cs := config.NewConfigurationSet()
+
+	if err := common.AddCommonIdentityConfig(cs); err != nil {
+		return nil, ErrAddingCommonCfg
+	}
+
+	if err := rancher.AddCommonConfig(cs); err != nil {
+		return nil, ErrAddingCommonCfg
+	}
+
+	if err := rancher.AddTokenConfig(cs); err != nil {
+		return nil, ErrAddingCommonCfg
+	}
+
+	return cs, nil
+}
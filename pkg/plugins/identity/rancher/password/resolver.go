@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package activedirectory
+package password
 
 import (
 	"fmt"
@@ -25,22 +25,38 @@ import (
 	rshared "github.com/fidelity/kconnect/pkg/rancher"
 )
 
-func (p *radIdentityProvider) resolveConfig(cfg config.ConfigurationSet) error {
+func (p *passwordIdentityProvider) resolveEndpoint(cfg config.ConfigurationSet) error {
 	if !p.interactive {
 		p.logger.Debug("skipping configuration resolution as runnning non-interactive")
 		return nil
 	}
 
+	if err := rshared.ResolveCommon(cfg); err != nil {
+		return fmt.Errorf("resolving common Rancher config: %w", err)
+	}
+
+	return nil
+}
+
+func (p *passwordIdentityProvider) resolveUsername(cfg config.ConfigurationSet) error {
+	if !p.interactive {
+		return nil
+	}
+
 	if err := prompt.InputAndSet(cfg, defaults.UsernameConfigItem, "Username:", true); err != nil {
 		return fmt.Errorf("resolving %s: %w", defaults.UsernameConfigItem, err)
 	}
 
-	if err := prompt.InputSensitiveAndSet(cfg, defaults.PasswordConfigItem, "Password:", true); err != nil {
-		return fmt.Errorf("resolving %s: %w", defaults.PasswordConfigItem, err)
+	return nil
+}
+
+func (p *passwordIdentityProvider) resolvePassword(cfg config.ConfigurationSet) error {
+	if !p.interactive {
+		return nil
 	}
 
-	if err := rshared.ResolveCommon(cfg); err != nil {
-		return fmt.Errorf("resolving common Rancher config: %w", err)
+	if err := prompt.InputSensitiveAndSet(cfg, defaults.PasswordConfigItem, "Password:", true); err != nil {
+		return fmt.Errorf("resolving %s: %w", defaults.PasswordConfigItem, err)
 	}
 
 	return nil
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package saml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/versent/saml2aws/v2"
+
+	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/plugins/identity/saml/sp"
+)
+
+const (
+	mfaConfigItem             = "mfa"
+	mfaTokenConfigItem        = "mfa-token"
+	mfaPushOnlyConfigItem     = "mfa-push-only"
+	sessionDurationConfigItem = "aws-session-duration"
+
+	defaultMFA         = "Auto"
+	duoPushOption      = "Duo Push"
+	minSessionDuration = 15 * time.Minute
+	maxSessionDuration = 12 * time.Hour
+)
+
+// pushMFAs is the push notification MFA type of each idp provider that supports one
+var pushMFAs = map[string]string{
+	"AzureAD":       "PhoneAppNotification",
+	"JumpCloud":     "PUSH",
+	"Okta":          "PUSH",
+	"ShibbolethECP": "push",
+}
+
+func addMFAConfig(cs config.ConfigurationSet) {
+	cs.String(mfaConfigItem, "", "The MFA type to use. The supported types depend on the idp-provider. Defaults to Auto")                                                            //nolint: errcheck
+	cs.String(mfaTokenConfigItem, "", "The MFA token or TOTP code to use instead of being prompted for it")                                                                          //nolint: errcheck
+	cs.Bool(mfaPushOnlyConfigItem, false, "Only use push notification MFA (Okta, JumpCloud, AzureAD and ShibbolethECP)")                                                             //nolint: errcheck
+	cs.String(sessionDurationConfigItem, "", "How long the AWS credentials are valid for, between 15m and 12h (e.g. 8h). Defaults to 1h. Limited to 1h when assume-role-arn is set") //nolint: errcheck
+
+	cs.SetSensitive(mfaTokenConfigItem)     //nolint: errcheck
+	cs.SetHistoryIgnore(mfaTokenConfigItem) //nolint: errcheck
+}
+
+// resolveMFA returns the saml2aws MFA type to use for the idp provider. The type is
+// matched ignoring case and checked against the types that the idp provider supports.
+func resolveMFA(cfg *sp.ProviderConfig) (string, error) {
+	supported := saml2aws.MFAsByProvider.Mfas(cfg.IdpProvider)
+	if len(supported) == 0 {
+		return "", fmt.Errorf("idp-provider %s: %w", cfg.IdpProvider, ErrUnknownIdpProvider)
+	}
+
+	requested := cfg.MFA
+
+	if cfg.MFAPushOnly {
+		push, ok := pushMFAs[cfg.IdpProvider]
+		if !ok {
+			return "", fmt.Errorf("idp-provider %s: %w", cfg.IdpProvider, ErrPushNotSupported)
+		}
+
+		if requested != "" && !strings.EqualFold(requested, push) {
+			return "", fmt.Errorf("mfa %s: %w", requested, ErrMFAAndPushOnly)
+		}
+
+		if cfg.MFAToken != "" {
+			return "", ErrMFATokenAndPushOnly
+		}
+
+		requested = push
+	}
+
+	if requested == "" {
+		requested = defaultMFA
+	}
+
+	for _, mfa := range supported {
+		if strings.EqualFold(mfa, requested) {
+			return mfa, nil
+		}
+	}
+
+	return "", fmt.Errorf("mfa %s isn't supported by %s, use one of %s: %w", requested, cfg.IdpProvider, strings.Join(supported, ","), ErrUnsupportedMFA)
+}
+
+// sessionDuration returns the duration in seconds of the AWS session. The value can be a
+// duration (e.g. 8h) or a number of seconds.
+func sessionDuration(value string) (int, error) {
+	if value == "" {
+		return defaultSession, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			return 0, fmt.Errorf("parsing %s %s: %w", sessionDurationConfigItem, value, err)
+		}
+
+		duration = time.Duration(seconds) * time.Second
+	}
+
+	if duration < minSessionDuration || duration > maxSessionDuration {
+		return 0, fmt.Errorf("%s %s: %w", sessionDurationConfigItem, value, ErrInvalidSessionDuration)
+	}
+
+	return int(duration.Seconds()), nil
+}
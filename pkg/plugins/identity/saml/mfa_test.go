@@ -0,0 +1,174 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package saml
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fidelity/kconnect/pkg/plugins/identity/saml/sp"
+)
+
+func Test_ResolveMFA(t *testing.T) {
+	testCases := []struct {
+		name      string
+		config    *sp.ProviderConfig
+		expect    string
+		expectErr error
+	}{
+		{
+			name:   "default",
+			config: &sp.ProviderConfig{IdpProvider: "Okta"},
+			expect: "Auto",
+		},
+		{
+			name:   "matched ignoring case",
+			config: &sp.ProviderConfig{IdpProvider: "Okta", MFA: "totp"},
+			expect: "TOTP",
+		},
+		{
+			name:      "unknown idp provider",
+			config:    &sp.ProviderConfig{IdpProvider: "Unknown"},
+			expectErr: ErrUnknownIdpProvider,
+		},
+		{
+			name:      "mfa not supported by idp provider",
+			config:    &sp.ProviderConfig{IdpProvider: "KeyCloak", MFA: "TOTP"},
+			expectErr: ErrUnsupportedMFA,
+		},
+		{
+			name:   "push only",
+			config: &sp.ProviderConfig{IdpProvider: "AzureAD", MFAPushOnly: true},
+			expect: "PhoneAppNotification",
+		},
+		{
+			name:   "push only with the push mfa",
+			config: &sp.ProviderConfig{IdpProvider: "Okta", MFA: "push", MFAPushOnly: true},
+			expect: "PUSH",
+		},
+		{
+			name:      "push only with another mfa",
+			config:    &sp.ProviderConfig{IdpProvider: "Okta", MFA: "TOTP", MFAPushOnly: true},
+			expectErr: ErrMFAAndPushOnly,
+		},
+		{
+			name:      "push only with a token",
+			config:    &sp.ProviderConfig{IdpProvider: "Okta", MFAToken: "123456", MFAPushOnly: true},
+			expectErr: ErrMFATokenAndPushOnly,
+		},
+		{
+			name:      "push only not supported",
+			config:    &sp.ProviderConfig{IdpProvider: "KeyCloak", MFAPushOnly: true},
+			expectErr: ErrPushNotSupported,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := resolveMFA(tc.config)
+			if tc.expectErr != nil {
+				if !errors.Is(err, tc.expectErr) {
+					t.Fatalf("expected error %v but got %v", tc.expectErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if actual != tc.expect {
+				t.Fatalf("expected mfa %s but got %s", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func Test_SessionDuration(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     string
+		expect    int
+		expectErr error
+	}{
+		{
+			name:   "default",
+			value:  "",
+			expect: defaultSession,
+		},
+		{
+			name:   "duration",
+			value:  "8h",
+			expect: 28800,
+		},
+		{
+			name:   "seconds",
+			value:  "7200",
+			expect: 7200,
+		},
+		{
+			name:   "minimum",
+			value:  "15m",
+			expect: 900,
+		},
+		{
+			name:   "maximum",
+			value:  "12h",
+			expect: 43200,
+		},
+		{
+			name:      "too short",
+			value:     "10m",
+			expectErr: ErrInvalidSessionDuration,
+		},
+		{
+			name:      "too long",
+			value:     "43201",
+			expectErr: ErrInvalidSessionDuration,
+		},
+		{
+			name:  "invalid",
+			value: "eight hours",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := sessionDuration(tc.value)
+			if tc.expect == 0 {
+				if err == nil {
+					t.Fatalf("expected an error but got duration %d", actual)
+				}
+
+				if tc.expectErr != nil && !errors.Is(err, tc.expectErr) {
+					t.Fatalf("expected error %v but got %v", tc.expectErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if actual != tc.expect {
+				t.Fatalf("expected duration %d but got %d", tc.expect, actual)
+			}
+		})
+	}
+}
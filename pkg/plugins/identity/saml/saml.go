@@ -29,11 +29,14 @@ import (
 
 	kaws "github.com/fidelity/kconnect/pkg/aws"
 	"github.com/fidelity/kconnect/pkg/config"
+	"github.com/fidelity/kconnect/pkg/defaults"
 	"github.com/fidelity/kconnect/pkg/plugins/identity/saml/sp"
 	"github.com/fidelity/kconnect/pkg/plugins/identity/saml/sp/aws"
+	"github.com/fidelity/kconnect/pkg/prompt"
 	"github.com/fidelity/kconnect/pkg/provider"
 	"github.com/fidelity/kconnect/pkg/provider/identity"
 	"github.com/fidelity/kconnect/pkg/provider/registry"
+	ksaml "github.com/fidelity/kconnect/pkg/saml"
 )
 
 var (
@@ -41,11 +44,20 @@ var (
 	ErrUnsuportedProvider = errors.New("cluster provider not supported")
 	ErrNoSAMLAssertions   = errors.New("no SAML assertions")
 	ErrCreatingAccount    = errors.New("creating account")
+
+	ErrUnknownIdpProvider     = errors.New("unknown idp provider")
+	ErrUnsupportedMFA         = errors.New("unsupported mfa type")
+	ErrPushNotSupported       = errors.New("idp provider doesn't support push only mfa")
+	ErrMFAAndPushOnly         = errors.New("mfa can't be used with mfa-push-only unless it's the push type")
+	ErrMFATokenAndPushOnly    = errors.New("mfa-token can't be used with mfa-push-only")
+	ErrInvalidSessionDuration = errors.New("session duration must be between 15m and 12h")
 )
 
 const (
 	ProviderName   = "saml"
 	defaultSession = 3600
+
+	freshLoginConfigItem = "fresh-login"
 )
 
 var (
@@ -109,7 +121,14 @@ func (p *samlIdentityProvider) Authenticate(ctx context.Context, input *identity
 		return nil, err
 	}
 
-	if err := p.bindAndValidateConfig(input.ConfigSet); err != nil {
+	cached := p.cachedAssertion(input.ConfigSet)
+	if cached == nil {
+		if err := p.resolvePassword(input.ConfigSet); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.bindAndValidateConfig(input.ConfigSet, cached != nil); err != nil {
 		return nil, fmt.Errorf("binding and validation config: %w", err)
 	}
 
@@ -119,7 +138,7 @@ func (p *samlIdentityProvider) Authenticate(ctx context.Context, input *identity
 
 	account, err := p.createAccount(input.ConfigSet)
 	if err != nil {
-		return nil, ErrCreatingAccount
+		return nil, fmt.Errorf("%w: %w", ErrCreatingAccount, err)
 	}
 
 	err = account.Validate()
@@ -127,47 +146,114 @@ func (p *samlIdentityProvider) Authenticate(ctx context.Context, input *identity
 		return nil, fmt.Errorf("validating saml: %w", err)
 	}
 
+	var samlAssertion string
+
+	if cached != nil {
+		p.logger.Infow("reusing cached saml assertion", "expires", cached.NotOnOrAfter)
+		samlAssertion = cached.Assertion
+	} else {
+		samlAssertion, err = p.login(account)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	userID, err := p.serviceProvider.ProcessAssertions(account, samlAssertion, input.ConfigSet)
+	if err != nil {
+		if cached != nil {
+			// don't reuse an assertion that has been rejected
+			if err := ksaml.NewAssertionCache().Delete(p.config.IdpEndpoint, p.config.Username); err != nil {
+				p.logger.Warnw("failed removing cached saml assertion", "error", err.Error())
+			}
+		}
+
+		return nil, fmt.Errorf("processing assertions for: %s: %w", p.scopedToDiscovery, err)
+	}
+
+	store, err := p.createIdentityStore(input.ConfigSet)
+	if err != nil {
+		return nil, fmt.Errorf("creating identity store for %s: %w", p.scopedToDiscovery, err)
+	}
+
+	err = store.Save(userID)
+	if err != nil {
+		return nil, fmt.Errorf("saving identity: %w", err)
+	}
+
+	return &identity.AuthenticateOutput{
+		Identity: userID,
+	}, nil
+}
+
+// login authenticates with the identity provider and returns the SAML assertion. The assertion
+// is cached so that it can be reused until it expires.
+func (p *samlIdentityProvider) login(account *cfg.IDPAccount) (string, error) {
 	client, err := saml2aws.NewSAMLClient(account)
 	if err != nil {
-		return nil, fmt.Errorf("creating saml client: %w", err)
+		return "", fmt.Errorf("creating saml client: %w", err)
 	}
 
 	loginDetails := &creds.LoginDetails{
 		Username: p.config.Username,
 		Password: p.config.Password,
+		MFAToken: p.config.MFAToken,
 		URL:      p.config.IdpEndpoint,
 	}
 
+	if p.config.MFAPushOnly {
+		// when Okta uses Duo it has its own choice of factor
+		loginDetails.DuoMFAOption = duoPushOption
+	}
+
 	samlAssertion, err := client.Authenticate(loginDetails)
 	if err != nil {
-		return nil, fmt.Errorf("authenticating: %w", err)
+		return "", fmt.Errorf("authenticating: %w", err)
 	}
 
 	if samlAssertion == "" {
-		return nil, ErrNoSAMLAssertions
+		return "", ErrNoSAMLAssertions
 	}
 
-	userID, err := p.serviceProvider.ProcessAssertions(account, samlAssertion, input.ConfigSet)
-	if err != nil {
-		return nil, fmt.Errorf("processing assertions for: %s: %w", p.scopedToDiscovery, err)
+	cached, err := ksaml.NewAssertionCache().Put(p.config.IdpEndpoint, p.config.Username, samlAssertion)
+	switch {
+	case errors.Is(err, ksaml.ErrNoAssertionExpiry):
+		p.logger.Debug("not caching saml assertion as it has no expiry")
+	case err != nil:
+		p.logger.Warnw("failed caching saml assertion", "error", err.Error())
+	default:
+		p.logger.Debugw("cached saml assertion", "expires", cached.NotOnOrAfter)
 	}
 
-	store, err := p.createIdentityStore(input.ConfigSet)
-	if err != nil {
-		return nil, fmt.Errorf("creating identity store for %s: %w", p.scopedToDiscovery, err)
+	return samlAssertion, nil
+}
+
+// cachedAssertion returns the cached assertion for the idp endpoint and username if there is
+// one that is still valid. Nil is returned if there isn't one or a fresh login is requested.
+func (p *samlIdentityProvider) cachedAssertion(cs config.ConfigurationSet) *ksaml.CachedAssertion {
+	if item := cs.Get(freshLoginConfigItem); item != nil {
+		if freshLogin, ok := item.Value.(bool); ok && freshLogin {
+			p.logger.Debug("fresh login requested, not using cached saml assertion")
+			return nil
+		}
 	}
 
-	err = store.Save(userID)
+	idpEndpoint := cs.ValueString("idp-endpoint")
+	username := cs.ValueString(defaults.UsernameConfigItem)
+
+	if idpEndpoint == "" || username == "" {
+		return nil
+	}
+
+	cached, err := ksaml.NewAssertionCache().Find(idpEndpoint, username)
 	if err != nil {
-		return nil, fmt.Errorf("saving identity: %w", err)
+		p.logger.Warnw("failed reading cached saml assertions", "error", err.Error())
+		return nil
 	}
 
-	return &identity.AuthenticateOutput{
-		Identity: userID,
-	}, nil
+	return cached
 }
 
-func (p *samlIdentityProvider) bindAndValidateConfig(cs config.ConfigurationSet) error {
+func (p *samlIdentityProvider) bindAndValidateConfig(cs config.ConfigurationSet, cachedAssertion bool) error {
 	spConfig := &sp.ProviderConfig{}
 
 	if err := config.Unmarshall(cs, spConfig); err != nil {
@@ -175,22 +261,47 @@ func (p *samlIdentityProvider) bindAndValidateConfig(cs config.ConfigurationSet)
 	}
 
 	validate := validator.New()
-	if err := validate.Struct(spConfig); err != nil {
+
+	var err error
+	if cachedAssertion {
+		// the password isn't needed when using a cached assertion
+		err = validate.StructExcept(spConfig, "IdentityProviderConfig.Password")
+	} else {
+		err = validate.Struct(spConfig)
+	}
+
+	if err != nil {
 		return fmt.Errorf("validating config struct: %w", err)
 	}
 
+	mfa, err := resolveMFA(spConfig)
+	if err != nil {
+		return fmt.Errorf("validating mfa config: %w", err)
+	}
+
+	spConfig.MFA = mfa
+
+	if _, err := sessionDuration(spConfig.AWSSessionDuration); err != nil {
+		return fmt.Errorf("validating session duration: %w", err)
+	}
+
 	p.config = spConfig
 
 	return nil
 }
 
 func (p *samlIdentityProvider) createAccount(cs config.ConfigurationSet) (*cfg.IDPAccount, error) {
+	duration, err := sessionDuration(p.config.AWSSessionDuration)
+	if err != nil {
+		return nil, err
+	}
+
 	account := &cfg.IDPAccount{
 		Username:        p.config.Username,
 		URL:             p.config.IdpEndpoint,
 		Provider:        p.config.IdpProvider,
-		MFA:             "Auto",
-		SessionDuration: defaultSession,
+		MFA:             p.config.MFA,
+		SessionDuration: duration,
 	}
 	if err := p.serviceProvider.PopulateAccount(account, cs); err != nil {
 		return nil, fmt.Errorf("populating account: %w", err)
@@ -215,6 +326,18 @@ func (p *samlIdentityProvider) resolveConfig(cfg config.ConfigurationSet) error
 	return nil
 }
 
+func (p *samlIdentityProvider) resolvePassword(cfg config.ConfigurationSet) error {
+	if !p.interactive {
+		return nil
+	}
+
+	if err := prompt.InputSensitiveAndSet(cfg, defaults.PasswordConfigItem, "Password:", true); err != nil {
+		return fmt.Errorf("resolving %s: %w", defaults.PasswordConfigItem, err)
+	}
+
+	return nil
+}
+
 func (p *samlIdentityProvider) createIdentityStore(cfg config.ConfigurationSet) (identity.Store, error) {
 	var store identity.Store
 
@@ -259,6 +382,11 @@ func ConfigurationItems(scopedToDiscovery string) (config.ConfigurationSet, erro
 	cs.SetRequired("idp-endpoint")                                                       //nolint: errcheck
 	cs.SetRequired("idp-provider")                                                       //nolint: errcheck
 
+	addMFAConfig(cs)
+
+	cs.Bool(freshLoginConfigItem, false, "Login to the identity provider even if there is a cached SAML assertion") //nolint: errcheck
+	cs.SetHistoryIgnore(freshLoginConfigItem)                                                                       //nolint: errcheck
+
 	// get the service provider flags
 	sp, err := createServiceProvider(scopedToDiscovery, nil)
 	if err != nil {
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/beevik/etree"
 	"github.com/go-playground/validator/v10"
@@ -162,7 +163,7 @@ func (p *ServiceProvider) ProcessAssertions(account *cfg.IDPAccount, samlAsserti
 		return nil, fmt.Errorf("creating identifier from AWS creds: %w", err)
 	}
 
-	profileName := fmt.Sprintf("kconnect-%s", identifier)
+	profileName := kaws.ProfileName(identifier)
 	if err := p.setProfileName(profileName, cfg); err != nil {
 		return nil, fmt.Errorf("setting profile name: %w", err)
 	}
@@ -363,26 +364,14 @@ func (p *ServiceProvider) assumeRoleARN(account *cfg.IDPAccount, awsCreds *awsco
 		return nil, fmt.Errorf("creating aws session: %w", err)
 	}
 
-	assumeRoleInput := &sts.AssumeRoleInput{
-		RoleArn:         &assumeRoleARN,
-		RoleSessionName: &account.Username,
-		DurationSeconds: aws.Int32(int32(account.SessionDuration)),
-	}
-
-	out, err := sts.NewFromConfig(cfg).AssumeRole(context.TODO(), assumeRoleInput)
-	if err != nil {
-		return nil, fmt.Errorf("failed to assume role: %w", err)
-	}
-
-	return &awsconfig.AWSCredentials{
-		AWSAccessKey:     aws.ToString(out.Credentials.AccessKeyId),
-		AWSSecretKey:     aws.ToString(out.Credentials.SecretAccessKey),
-		AWSSessionToken:  aws.ToString(out.Credentials.SessionToken),
-		AWSSecurityToken: aws.ToString(out.Credentials.SessionToken),
-		PrincipalARN:     aws.ToString(out.AssumedRoleUser.Arn),
-		Expires:          out.Credentials.Expiration.Local(),
-		Region:           account.Region,
-	}, nil
+	// the roles are assumed using the role credentials from saml so the duration is limited
+	return kaws.AssumeRoleChain(context.TODO(), cfg, &kaws.AssumeRoleInput{
+		RoleARNs:            kaws.SplitList(assumeRoleARN),
+		SessionName:         account.Username,
+		Duration:            time.Duration(account.SessionDuration) * time.Second,
+		Region:              account.Region,
+		FromRoleCredentials: true,
+	})
 }
 
 // TODO: use the version from saml2aws when modules are fixed
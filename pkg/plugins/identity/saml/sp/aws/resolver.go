@@ -48,14 +48,12 @@ func (p *ServiceProvider) ResolveConfiguration(cfg config.ConfigurationSet) erro
 		return fmt.Errorf("resolving region: %w", err)
 	}
 
+	// NOTE: the password is resolved by the saml provider as it isn't needed when
+	// there is a cached assertion
 	if err := prompt.InputAndSet(cfg, defaults.UsernameConfigItem, "Username:", true); err != nil {
 		return fmt.Errorf("resolving %s: %w", defaults.UsernameConfigItem, err)
 	}
 
-	if err := prompt.InputSensitiveAndSet(cfg, defaults.PasswordConfigItem, "Password:", true); err != nil {
-		return fmt.Errorf("resolving %s: %w", defaults.PasswordConfigItem, err)
-	}
-
 	return nil
 }
 
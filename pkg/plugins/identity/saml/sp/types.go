@@ -29,6 +29,11 @@ type ProviderConfig struct {
 
 	IdpEndpoint string `json:"idp-endpoint" validate:"required"`
 	IdpProvider string `json:"idp-provider" validate:"required"`
+
+	MFA                string `json:"mfa"`
+	MFAToken           string `json:"mfa-token"`
+	MFAPushOnly        bool   `json:"mfa-push-only"`
+	AWSSessionDuration string `json:"aws-session-duration"`
 }
 
 type ServiceProvider interface {
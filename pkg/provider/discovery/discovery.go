@@ -32,6 +32,17 @@ type Provider interface {
 	GetConfig(ctx context.Context, input *GetConfigInput) (*GetConfigOutput, error)
 }
 
+// NamespaceChooser is implemented by providers that can let the user choose the
+// namespace for a cluster. It's called after the cluster has been selected and
+// before GetConfig, and only when connecting to a single cluster as it may prompt
+// the user. An empty namespace is returned if no namespace is chosen.
+type NamespaceChooser interface {
+	// NamespaceChoiceEnabled returns true if the provider has been configured to let
+	// the user choose the namespace
+	NamespaceChoiceEnabled() bool
+	ChooseNamespace(ctx context.Context, cluster *Cluster) (string, error)
+}
+
 type ProviderCreatorFun func(input *provider.PluginCreationInput) (Provider, error)
 
 // DiscoverInput is the input to Discover
@@ -42,10 +53,14 @@ type DiscoverInput struct {
 
 // DiscoverOutput holds details of the output of the Discover
 type DiscoverOutput struct {
-	DiscoveryProvider string `yaml:"discoveryProvider"`
-	IdentityProvider  string `yaml:"identityProvider"`
+	DiscoveryProvider string `yaml:"discoveryProvider" json:"discoveryProvider"`
+	IdentityProvider  string `yaml:"identityProvider" json:"identityProvider"`
+
+	Clusters map[string]*Cluster `yaml:"clusters" json:"clusters"`
 
-	Clusters map[string]*Cluster `yaml:"clusters"`
+	// Warnings holds the errors for the clusters or scopes that couldn't be
+	// discovered. The clusters that were discovered are still returned.
+	Warnings []*Warning `yaml:"warnings,omitempty" json:"warnings,omitempty"`
 }
 
 // GetClusterInput is the input to GetCluster
@@ -73,8 +88,16 @@ type GetConfigOutput struct {
 
 // Cluster represents the information about a discovered k8s cluster
 type Cluster struct {
-	ID                       string  `yaml:"id"`
-	Name                     string  `yaml:"name"`
-	ControlPlaneEndpoint     *string `yaml:"endpoint"`
-	CertificateAuthorityData *string `yaml:"ca"`
+	ID                       string  `yaml:"id" json:"id"`
+	Name                     string  `yaml:"name" json:"name"`
+	ControlPlaneEndpoint     *string `yaml:"endpoint" json:"endpoint,omitempty"`
+	CertificateAuthorityData *string `yaml:"ca" json:"ca,omitempty"`
+
+	// Version is the Kubernetes version of the cluster
+	Version *string `yaml:"version,omitempty" json:"version,omitempty"`
+	// Status is the provider specific status of the cluster
+	Status *string `yaml:"status,omitempty" json:"status,omitempty"`
+	// Labels holds additional attributes of the cluster such as region,
+	// account and any tags. These can be used with a cluster selector.
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
 }
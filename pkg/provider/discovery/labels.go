@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Well known cluster label names that are populated by the discovery providers.
+const (
+	LabelRegion        = "region"
+	LabelAccount       = "account"
+	LabelSubscription  = "subscription"
+	LabelTenant        = "tenant"
+	LabelResourceGroup = "resource-group"
+	LabelLocation      = "location"
+	LabelDriver        = "driver"
+	LabelServer        = "server"
+
+	// VersionSelectorKey and StatusSelectorKey can be used in a cluster selector to
+	// match against the cluster version and status
+	VersionSelectorKey = "version"
+	StatusSelectorKey  = "status"
+)
+
+// SelectorSet returns the set of labels used when matching the cluster against
+// a label selector. This includes the version and status of the cluster.
+func (c *Cluster) SelectorSet() labels.Set {
+	set := labels.Set{}
+	for k, v := range c.Labels {
+		set[k] = v
+	}
+
+	if c.Version != nil {
+		set[VersionSelectorKey] = *c.Version
+	}
+
+	if c.Status != nil {
+		set[StatusSelectorKey] = *c.Status
+	}
+
+	return set
+}
+
+// DisplayName returns the name of the cluster with its version, status
+// and labels. This is used when asking the user to select a cluster.
+func (c *Cluster) DisplayName() string {
+	details := []string{}
+
+	if c.Version != nil && *c.Version != "" {
+		details = append(details, *c.Version)
+	}
+
+	if c.Status != nil && *c.Status != "" {
+		details = append(details, *c.Status)
+	}
+
+	if len(c.Labels) > 0 {
+		details = append(details, labelsToString(c.Labels))
+	}
+
+	if len(details) == 0 {
+		return c.Name
+	}
+
+	return fmt.Sprintf("%s (%s)", c.Name, strings.Join(details, ", "))
+}
+
+// ToTable will convert the discovered clusters to a table
+func (o *DiscoverOutput) ToTable() *metav1.Table {
+	return ToTable([]*DiscoverOutput{o})
+}
+
+// ToTable will convert the clusters discovered by 1 or more providers to a table
+func ToTable(outputs []*DiscoverOutput) *metav1.Table {
+	table := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: metav1.SchemeGroupVersion.String(),
+			Kind:       "Table",
+		},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Provider", Type: "string"},
+			{Name: "Name", Type: "string"},
+			{Name: "Id", Type: "string"},
+			{Name: "Version", Type: "string"},
+			{Name: "Status", Type: "string"},
+			{Name: "Labels", Type: "string"},
+		},
+	}
+
+	for _, output := range outputs {
+		if output == nil {
+			continue
+		}
+
+		clusters := make([]*Cluster, 0, len(output.Clusters))
+		for _, cluster := range output.Clusters {
+			clusters = append(clusters, cluster)
+		}
+
+		sort.Slice(clusters, func(i, j int) bool {
+			return clusters[i].Name < clusters[j].Name
+		})
+
+		for _, cluster := range clusters {
+			row := metav1.TableRow{
+				Cells: []any{
+					output.DiscoveryProvider,
+					cluster.Name,
+					cluster.ID,
+					stringValue(cluster.Version),
+					stringValue(cluster.Status),
+					labelsToString(cluster.Labels),
+				},
+			}
+			table.Rows = append(table.Rows, row)
+		}
+	}
+
+	return table
+}
+
+func labelsToString(clusterLabels map[string]string) string {
+	return labels.Set(clusterLabels).String()
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
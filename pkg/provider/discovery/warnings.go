@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// WarningScopeCluster is the scope of a warning for a single cluster
+	WarningScopeCluster = "cluster"
+	// WarningScopeSubscription is the scope of a warning for an Azure subscription
+	WarningScopeSubscription = "subscription"
+	// WarningScopeTenant is the scope of a warning for an Azure tenant
+	WarningScopeTenant = "tenant"
+	// WarningScopePage is the scope of a warning for a page of results from a provider api
+	WarningScopePage = "page"
+)
+
+var (
+	ErrPartialDiscovery = errors.New("some clusters couldn't be discovered")
+)
+
+// Warning is an error discovering a cluster, or a scope containing clusters such as
+// a subscription, that didn't stop the other clusters from being discovered
+type Warning struct {
+	// Scope is what couldn't be discovered, e.g. cluster or subscription
+	Scope string `yaml:"scope" json:"scope"`
+	// ID is the provider specific id of the cluster or scope
+	ID      string `yaml:"id" json:"id"`
+	Message string `yaml:"message" json:"message"`
+}
+
+func (w *Warning) Error() string {
+	return fmt.Sprintf("%s %s: %s", w.Scope, w.ID, w.Message)
+}
+
+// NewWarning creates a warning for a cluster or scope that couldn't be discovered
+func NewWarning(scope, id string, err error) *Warning {
+	return &Warning{
+		Scope:   scope,
+		ID:      id,
+		Message: err.Error(),
+	}
+}
+
+// AddWarning will add a warning to the output for a cluster or scope that couldn't
+// be discovered
+func (o *DiscoverOutput) AddWarning(scope, id string, err error) {
+	o.Warnings = append(o.Warnings, NewWarning(scope, id, err))
+}
+
+// WarningsError returns an error containing all the warnings, or nil if there
+// aren't any warnings
+func (o *DiscoverOutput) WarningsError() error {
+	return WarningsError(o.Warnings)
+}
+
+// WarningsError returns an error containing all the warnings, or nil if there
+// aren't any warnings
+func WarningsError(warnings []*Warning) error {
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0, len(warnings))
+	for _, warning := range warnings {
+		errs = append(errs, warning)
+	}
+
+	return fmt.Errorf("%w: %w", ErrPartialDiscovery, errors.Join(errs...))
+}
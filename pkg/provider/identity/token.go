@@ -1,6 +1,9 @@
 package identity
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrNotTokenIdentity = errors.New("not a token identity")
@@ -10,6 +13,7 @@ type TokenIdentity struct {
 	token          string
 	name           string
 	idProviderName string
+	expiresAt      time.Time
 }
 
 func NewTokenIdentity(name, token, idProviderName string) *TokenIdentity {
@@ -28,9 +32,25 @@ func (t *TokenIdentity) Name() string {
 	return t.name
 }
 
+// WithExpiry sets the time that the token expires. Tokens without an expiry
+// are never treated as expired.
+func (t *TokenIdentity) WithExpiry(expiresAt time.Time) *TokenIdentity {
+	t.expiresAt = expiresAt
+
+	return t
+}
+
+// ExpiresAt returns the time the token expires or the zero time if it doesn't expire
+func (t *TokenIdentity) ExpiresAt() time.Time {
+	return t.expiresAt
+}
+
 func (t *TokenIdentity) IsExpired() bool {
-	// TODO: handle properly
-	return false
+	if t.expiresAt.IsZero() {
+		return false
+	}
+
+	return time.Now().After(t.expiresAt)
 }
 
 func (t *TokenIdentity) IdentityProviderName() string {
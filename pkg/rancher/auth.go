@@ -0,0 +1,323 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rancher
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fidelity/kconnect/pkg/defaults"
+	khttp "github.com/fidelity/kconnect/pkg/http"
+)
+
+// Ids of the Rancher auth providers
+const (
+	AuthProviderActiveDirectory = "activedirectory"
+	AuthProviderLocal           = "local"
+	AuthProviderOpenLDAP        = "openldap"
+	AuthProviderFreeIPA         = "freeipa"
+	AuthProviderGitHub          = "github"
+)
+
+const (
+	browserLoginKeySize      = 2048
+	browserLoginRequestIDLen = 16
+	requestIDChars           = "abcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+// BrowserAuthProviders are the SAML and OIDC based auth providers that require the
+// user to login via the Rancher dashboard in a browser
+var BrowserAuthProviders = []string{
+	"azuread",
+	"keycloak",
+	"keycloakoidc",
+	"okta",
+	"ping",
+	"adfs",
+	"shibboleth",
+	"genericoidc",
+	"googleoauth",
+}
+
+// AuthProvider represents an auth provider that is enabled in Rancher
+type AuthProvider struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type authProvidersResponse struct {
+	Data []AuthProvider `json:"data"`
+}
+
+type loginRequest struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	TTL         int64  `json:"ttl,omitempty"`
+}
+
+// LoginResponse is the response from a Rancher login
+type LoginResponse struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Token     string      `json:"token"`
+	UserID    string      `json:"userId"`
+	TTL       json.Number `json:"ttl"`
+	ExpiresAt string      `json:"expiresAt"`
+}
+
+// Expiry returns the time the token expires or the zero time if the token doesn't expire
+func (r *LoginResponse) Expiry() time.Time {
+	return parseExpiry(r.ExpiresAt)
+}
+
+type authTokenResponse struct {
+	Token   string `json:"token"`
+	Expired bool   `json:"expired"`
+}
+
+type usersResponse struct {
+	Data []struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"data"`
+}
+
+// ListAuthProviders will return the auth providers that are enabled in Rancher
+func ListAuthProviders(httpClient khttp.Client, resolver EndpointsResolver) ([]AuthProvider, error) {
+	headers := defaults.Headers(defaults.WithNoCache(), defaults.WithJSON())
+
+	resp, err := httpClient.Get(resolver.AuthProviders(), headers)
+	if err != nil {
+		return nil, fmt.Errorf("getting auth providers using api: %w", err)
+	}
+
+	if resp.ResponseCode() != http.StatusOK {
+		return nil, ErrListingAuthProviders
+	}
+
+	providersResponse := &authProvidersResponse{}
+	if err := json.Unmarshal([]byte(resp.Body()), providersResponse); err != nil {
+		return nil, fmt.Errorf("unmarshalling auth providers response: %w", err)
+	}
+
+	return providersResponse.Data, nil
+}
+
+// CheckAuthProviderEnabled will check that one of the auth providers is enabled in Rancher. If
+// none of them are enabled the error contains the providers that are enabled.
+func CheckAuthProviderEnabled(httpClient khttp.Client, resolver EndpointsResolver, authProviders ...string) error {
+	enabled, err := ListAuthProviders(httpClient, resolver)
+	if err != nil {
+		return err
+	}
+
+	enabledIDs := make([]string, 0, len(enabled))
+	for _, provider := range enabled {
+		if slices.Contains(authProviders, provider.ID) {
+			return nil
+		}
+
+		enabledIDs = append(enabledIDs, provider.ID)
+	}
+
+	return fmt.Errorf("checking %s, enabled providers are [%s]: %w", strings.Join(authProviders, ","), strings.Join(enabledIDs, ","), ErrAuthProviderNotEnabled)
+}
+
+// Login will login to Rancher with a username and password using an auth provider. The token
+// is created with a description that identifies it as created by kconnect and is valid for
+// the ttl, a ttl of 0 uses the default from the Rancher settings.
+func Login(httpClient khttp.Client, resolver EndpointsResolver, authProvider, username, password string, ttl time.Duration) (*LoginResponse, error) {
+	loginURL, err := resolver.Login(authProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &loginRequest{
+		Type:        "token",
+		Description: TokenDescription(),
+		Username:    username,
+		Password:    password,
+		TTL:         ttl.Milliseconds(),
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling login request: %w", err)
+	}
+
+	headers := defaults.Headers(defaults.WithNoCache(), defaults.WithContentTypeJSON())
+
+	resp, err := httpClient.Post(loginURL, string(data), headers)
+	if err != nil {
+		return nil, fmt.Errorf("performing %s login: %w", authProvider, err)
+	}
+
+	if resp.ResponseCode() != http.StatusCreated {
+		return nil, fmt.Errorf("logging in using %s: %w", authProvider, ErrAuthenticationFailed)
+	}
+
+	loginResponse := &LoginResponse{}
+	if err := json.Unmarshal([]byte(resp.Body()), loginResponse); err != nil {
+		return nil, fmt.Errorf("unmarshalling login response: %w", err)
+	}
+
+	if loginResponse.ExpiresAt == "" && ttl > 0 {
+		loginResponse.ExpiresAt = time.Now().Add(ttl).UTC().Format(time.RFC3339)
+	}
+
+	return loginResponse, nil
+}
+
+// BrowserLoginInput is the input to BrowserLogin
+type BrowserLoginInput struct {
+	HTTPClient khttp.Client
+	Resolver   EndpointsResolver
+	// OpenURL is called with the url of the Rancher dashboard that the user must login with
+	OpenURL      func(url string) error
+	PollInterval time.Duration
+	Timeout      time.Duration
+}
+
+// BrowserLogin will login to Rancher via the dashboard in a browser. This is used for the auth
+// providers that redirect to another identity provider (i.e. SAML, OIDC and GitHub). The token
+// is generated by Rancher once the user has logged in, encrypted with a public key and then
+// polled for using the request id.
+func BrowserLogin(ctx context.Context, input *BrowserLoginInput) (string, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, browserLoginKeySize)
+	if err != nil {
+		return "", fmt.Errorf("generating key: %w", err)
+	}
+
+	publicKey, err := json.Marshal(privateKey.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("marshalling public key: %w", err)
+	}
+
+	requestID, err := generateRequestID()
+	if err != nil {
+		return "", err
+	}
+
+	loginURL := input.Resolver.DashboardLogin(requestID, base64.StdEncoding.EncodeToString(publicKey))
+	if err := input.OpenURL(loginURL); err != nil {
+		return "", fmt.Errorf("opening login url: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, input.Timeout)
+	defer cancel()
+
+	tokenURL := input.Resolver.AuthToken(requestID)
+	headers := defaults.Headers(defaults.WithNoCache(), defaults.WithJSON())
+
+	for {
+		resp, err := input.HTTPClient.Get(tokenURL, headers)
+		if err != nil {
+			return "", fmt.Errorf("getting auth token: %w", err)
+		}
+
+		if resp.ResponseCode() == http.StatusOK {
+			return decryptAuthToken(input.HTTPClient, tokenURL, resp.Body(), privateKey)
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ErrBrowserLoginTimeout
+		case <-time.After(input.PollInterval):
+		}
+	}
+}
+
+// GetCurrentUserID will get the id of the user that the token belongs to
+func GetCurrentUserID(httpClient khttp.Client, resolver EndpointsResolver, token string) (string, error) {
+	headers := defaults.Headers(defaults.WithJSON(), defaults.WithBearerAuth(token))
+
+	resp, err := httpClient.Get(resolver.CurrentUser(), headers)
+	if err != nil {
+		return "", fmt.Errorf("getting current user using api: %w", err)
+	}
+
+	if resp.ResponseCode() != http.StatusOK {
+		return "", ErrGettingCurrentUser
+	}
+
+	users := &usersResponse{}
+	if err := json.Unmarshal([]byte(resp.Body()), users); err != nil {
+		return "", fmt.Errorf("unmarshalling users response: %w", err)
+	}
+
+	if len(users.Data) == 0 {
+		return "", ErrGettingCurrentUser
+	}
+
+	return users.Data[0].ID, nil
+}
+
+func decryptAuthToken(httpClient khttp.Client, tokenURL, body string, privateKey *rsa.PrivateKey) (string, error) {
+	tokenResponse := &authTokenResponse{}
+	if err := json.Unmarshal([]byte(body), tokenResponse); err != nil {
+		return "", fmt.Errorf("unmarshalling auth token response: %w", err)
+	}
+
+	if tokenResponse.Expired {
+		return "", ErrBrowserLoginTokenExpired
+	}
+
+	encrypted, err := base64.StdEncoding.DecodeString(tokenResponse.Token)
+	if err != nil {
+		return "", fmt.Errorf("decoding auth token: %w", err)
+	}
+
+	token, err := privateKey.Decrypt(nil, encrypted, &rsa.OAEPOptions{Hash: crypto.SHA256})
+	if err != nil {
+		return "", fmt.Errorf("decrypting auth token: %w", err)
+	}
+
+	// The auth token request is no longer needed once the token has been retrieved
+	if _, err := httpClient.Do(&khttp.ClientRequest{Method: http.MethodDelete, URL: tokenURL}); err != nil {
+		return "", fmt.Errorf("deleting auth token request: %w", err)
+	}
+
+	return string(token), nil
+}
+
+func generateRequestID() (string, error) {
+	id := make([]byte, browserLoginRequestIDLen)
+	charCount := big.NewInt(int64(len(requestIDChars)))
+
+	for i := range id {
+		n, err := rand.Int(rand.Reader, charCount)
+		if err != nil {
+			return "", fmt.Errorf("generating request id: %w", err)
+		}
+
+		id[i] = requestIDChars[n.Int64()]
+	}
+
+	return string(id), nil
+}
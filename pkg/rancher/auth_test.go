@@ -0,0 +1,302 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rancher
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	khttp "github.com/fidelity/kconnect/pkg/http"
+)
+
+// stubRancher is a stubbed Rancher api used for testing the authentication
+type stubRancher struct {
+	authProviders []string
+	users         map[string]string
+
+	lock      sync.Mutex
+	publicKey *rsa.PublicKey
+	requestID string
+	deleted   bool
+	loginReq  *loginRequest
+}
+
+func (s *stubRancher) handler(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch {
+	case r.URL.Path == "/v3-public/authProviders":
+		providers := []AuthProvider{}
+		for _, id := range s.authProviders {
+			providers = append(providers, AuthProvider{ID: id, Type: id + "Provider"})
+		}
+
+		json.NewEncoder(w).Encode(&authProvidersResponse{Data: providers}) //nolint: errcheck
+	case strings.HasSuffix(r.URL.Path, "Providers/local") && r.URL.Query().Get("action") == "login":
+		req := &loginRequest{}
+		json.NewDecoder(r.Body).Decode(req) //nolint: errcheck
+		s.loginReq = req
+
+		if s.users[req.Username] != req.Password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(&LoginResponse{Token: "token-abc:secret", UserID: "u-" + req.Username}) //nolint: errcheck
+	case strings.HasPrefix(r.URL.Path, "/v3-public/authTokens/"):
+		if s.publicKey == nil || strings.TrimPrefix(r.URL.Path, "/v3-public/authTokens/") != s.requestID {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			s.deleted = true
+			return
+		}
+
+		encrypted, _ := rsa.EncryptOAEP(sha256.New(), rand.Reader, s.publicKey, []byte("token-xyz:secret"), nil)
+		json.NewEncoder(w).Encode(&authTokenResponse{Token: base64.StdEncoding.EncodeToString(encrypted)}) //nolint: errcheck
+	case r.URL.Path == "/v3/users" && r.URL.Query().Get("me") == "true":
+		if r.Header.Get("Authorization") != "Bearer token-xyz:secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		fmt.Fprint(w, `{"data":[{"id":"u-sso","username":"bob"}]}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// login simulates the user logging in via the dashboard in a browser
+func (s *stubRancher) login(loginURL string) error {
+	parsed, err := url.Parse(loginURL)
+	if err != nil {
+		return err
+	}
+
+	keyData, err := base64.StdEncoding.DecodeString(parsed.Query().Get("publicKey"))
+	if err != nil {
+		return err
+	}
+
+	publicKey := &rsa.PublicKey{}
+	if err := json.Unmarshal(keyData, publicKey); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.publicKey = publicKey
+	s.requestID = parsed.Query().Get("requestId")
+
+	return nil
+}
+
+func newStubRancher(t *testing.T, authProviders ...string) (*stubRancher, EndpointsResolver) {
+	t.Helper()
+
+	stub := &stubRancher{
+		authProviders: authProviders,
+		users:         map[string]string{"admin": "password"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(stub.handler))
+	t.Cleanup(server.Close)
+
+	resolver, err := NewStaticEndpointsResolver(server.URL + "/v3")
+	if err != nil {
+		t.Fatalf("creating resolver: %s", err)
+	}
+
+	return stub, resolver
+}
+
+func Test_CheckAuthProviderEnabled(t *testing.T) {
+	testCases := []struct {
+		name          string
+		enabled       []string
+		check         []string
+		expectEnabled bool
+	}{
+		{
+			name:          "enabled",
+			enabled:       []string{AuthProviderLocal, AuthProviderOpenLDAP},
+			check:         []string{AuthProviderOpenLDAP},
+			expectEnabled: true,
+		},
+		{
+			name:          "not enabled",
+			enabled:       []string{AuthProviderLocal},
+			check:         []string{AuthProviderFreeIPA},
+			expectEnabled: false,
+		},
+		{
+			name:          "one of many enabled",
+			enabled:       []string{AuthProviderLocal, "okta"},
+			check:         BrowserAuthProviders,
+			expectEnabled: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, resolver := newStubRancher(t, tc.enabled...)
+
+			err := CheckAuthProviderEnabled(khttp.NewHTTPClient(), resolver, tc.check...)
+			if tc.expectEnabled && err != nil {
+				t.Fatalf("expected provider enabled but got error: %s", err)
+			}
+
+			if !tc.expectEnabled && !errors.Is(err, ErrAuthProviderNotEnabled) {
+				t.Fatalf("expected ErrAuthProviderNotEnabled but got: %v", err)
+			}
+		})
+	}
+}
+
+func Test_Login(t *testing.T) {
+	testCases := []struct {
+		name         string
+		authProvider string
+		username     string
+		password     string
+		expectUserID string
+		expectErr    error
+	}{
+		{
+			name:         "valid credentials",
+			authProvider: AuthProviderLocal,
+			username:     "admin",
+			password:     "password",
+			expectUserID: "u-admin",
+		},
+		{
+			name:         "invalid credentials",
+			authProvider: AuthProviderLocal,
+			username:     "admin",
+			password:     "wrong",
+			expectErr:    ErrAuthenticationFailed,
+		},
+		{
+			name:         "unsupported auth provider",
+			authProvider: AuthProviderGitHub,
+			username:     "admin",
+			password:     "password",
+			expectErr:    ErrUnsupportedAuthProvider,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub, resolver := newStubRancher(t, tc.authProvider)
+
+			resp, err := Login(khttp.NewHTTPClient(), resolver, tc.authProvider, tc.username, tc.password, time.Hour)
+			if tc.expectErr != nil {
+				if !errors.Is(err, tc.expectErr) {
+					t.Fatalf("expected error %s but got: %v", tc.expectErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if resp.UserID != tc.expectUserID {
+				t.Fatalf("expected user id %s but got %s", tc.expectUserID, resp.UserID)
+			}
+
+			if stub.loginReq.TTL != time.Hour.Milliseconds() {
+				t.Fatalf("expected ttl of %d but got %d", time.Hour.Milliseconds(), stub.loginReq.TTL)
+			}
+
+			if !strings.HasPrefix(stub.loginReq.Description, TokenDescriptionPrefix) {
+				t.Fatalf("expected token description to start with %s but got %s", TokenDescriptionPrefix, stub.loginReq.Description)
+			}
+
+			if resp.Expiry().IsZero() {
+				t.Fatal("expected token expiry to be set")
+			}
+		})
+	}
+}
+
+func Test_BrowserLogin(t *testing.T) {
+	stub, resolver := newStubRancher(t, "keycloak")
+	httpClient := khttp.NewHTTPClient()
+
+	token, err := BrowserLogin(context.Background(), &BrowserLoginInput{
+		HTTPClient:   httpClient,
+		Resolver:     resolver,
+		OpenURL:      stub.login,
+		PollInterval: 10 * time.Millisecond,
+		Timeout:      5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if token != "token-xyz:secret" {
+		t.Fatalf("expected decrypted token but got %s", token)
+	}
+
+	if !stub.deleted {
+		t.Fatal("expected auth token request to be deleted")
+	}
+
+	userID, err := GetCurrentUserID(httpClient, resolver, token)
+	if err != nil {
+		t.Fatalf("unexpected error getting user: %s", err)
+	}
+
+	if userID != "u-sso" {
+		t.Fatalf("expected user id u-sso but got %s", userID)
+	}
+}
+
+func Test_BrowserLoginTimeout(t *testing.T) {
+	_, resolver := newStubRancher(t, "keycloak")
+
+	_, err := BrowserLogin(context.Background(), &BrowserLoginInput{
+		HTTPClient:   khttp.NewHTTPClient(),
+		Resolver:     resolver,
+		OpenURL:      func(string) error { return nil },
+		PollInterval: 10 * time.Millisecond,
+		Timeout:      50 * time.Millisecond,
+	})
+	if !errors.Is(err, ErrBrowserLoginTimeout) {
+		t.Fatalf("expected ErrBrowserLoginTimeout but got: %v", err)
+	}
+}
@@ -27,6 +27,28 @@ const (
 	APIEndpointConfigName = "api-endpoint"
 	// ClusterName is the user friendly name of the Rancher cluster to connect to
 	ClusterName = "cluster-name"
+	// EndpointTypeConfigName is the name of the config item for the type of endpoint to connect to the cluster with
+	EndpointTypeConfigName = "endpoint-type"
+	// ClusterLabelsConfigName is the name of the config item for the cluster label selector
+	ClusterLabelsConfigName = "cluster-labels"
+	// ClusterProviderConfigName is the name of the config item for the providers of the clusters
+	ClusterProviderConfigName = "cluster-provider"
+	// ActiveOnlyConfigName is the name of the config item to only discover active clusters
+	ActiveOnlyConfigName = "active-only"
+	// ChooseNamespaceConfigName is the name of the config item to choose the namespace from the projects
+	ChooseNamespaceConfigName = "choose-namespace"
+	// TokenTTLConfigName is the name of the config item for the time to live of the Rancher tokens
+	TokenTTLConfigName = "token-ttl"
+
+	defaultTokenTTL = "12h"
+)
+
+// The types of endpoint that can be used to connect to a Rancher cluster
+const (
+	// EndpointTypeProxy connects to the cluster via the Rancher server
+	EndpointTypeProxy = "proxy"
+	// EndpointTypeACE connects directly to the cluster using the authorized cluster endpoint
+	EndpointTypeACE = "ace"
 )
 
 // CommonConfig represents the common configuration for Rancher
@@ -35,10 +57,26 @@ type CommonConfig struct {
 	APIEndpoint string `json:"api-endpoint" validate:"required"`
 }
 
+// TokenConfig represents the configuration for the tokens created when logging in to Rancher
+type TokenConfig struct {
+	// TokenTTL is how long the token is valid for. A value of 0 uses the Rancher default.
+	TokenTTL string `json:"token-ttl"`
+}
+
 // UseConfig represents the use configuration for Rancher
 type UseConfig struct {
 	// ClusterName is the user friendly name of the Rancher cluster to connect to
 	ClusterName string `json:"cluster-name"`
+	// EndpointType is the type of endpoint to connect to the cluster with
+	EndpointType string `json:"endpoint-type"`
+	// ClusterLabels is a label selector applied to the labels of the Rancher clusters
+	ClusterLabels string `json:"cluster-labels"`
+	// ClusterProvider is a comma separated list of the providers of the clusters to discover
+	ClusterProvider string `json:"cluster-provider"`
+	// ActiveOnly will only discover the clusters that are active
+	ActiveOnly bool `json:"active-only"`
+	// ChooseNamespace will ask the user to choose the namespace from their Rancher projects
+	ChooseNamespace bool `json:"choose-namespace"`
 }
 
 // AddCommonConfig adds the Rancher common configuration to a configuration set
@@ -54,11 +92,40 @@ func AddCommonConfig(cs config.ConfigurationSet) error {
 	return nil
 }
 
+// AddTokenConfig adds the configuration for the Rancher tokens to a configuration set
+func AddTokenConfig(cs config.ConfigurationSet) error {
+	if _, err := cs.String(TokenTTLConfigName, defaultTokenTTL, "How long the Rancher token is valid for (e.g. 8h, 30m). 0 uses the Rancher default"); err != nil {
+		return fmt.Errorf("setting config item %s: %w", TokenTTLConfigName, err)
+	}
+
+	return nil
+}
+
 // AddUseConfig adds the Rancher use configuration to a configuration set
 func AddUseConfig(cs config.ConfigurationSet) error {
 	if _, err := cs.String(ClusterName, "", "The Rancher user friendly cluster name"); err != nil {
 		return fmt.Errorf("setting config item %s: %w", ClusterName, err)
 	}
 
+	if _, err := cs.String(EndpointTypeConfigName, EndpointTypeProxy, "The endpoint to connect to the cluster with, either proxy (via the Rancher server) or ace (the authorized cluster endpoint)"); err != nil {
+		return fmt.Errorf("setting config item %s: %w", EndpointTypeConfigName, err)
+	}
+
+	if _, err := cs.String(ClusterLabelsConfigName, "", "Label selector applied to the labels of the Rancher clusters (e.g. env=prod)"); err != nil {
+		return fmt.Errorf("setting config item %s: %w", ClusterLabelsConfigName, err)
+	}
+
+	if _, err := cs.String(ClusterProviderConfigName, "", "Comma separated list of the providers of the clusters to discover (e.g. rke2,k3s,imported,eks)"); err != nil {
+		return fmt.Errorf("setting config item %s: %w", ClusterProviderConfigName, err)
+	}
+
+	if _, err := cs.Bool(ActiveOnlyConfigName, false, "Only discover the clusters that are active"); err != nil {
+		return fmt.Errorf("setting config item %s: %w", ActiveOnlyConfigName, err)
+	}
+
+	if _, err := cs.Bool(ChooseNamespaceConfigName, false, "Choose the namespace for the context from your Rancher projects"); err != nil {
+		return fmt.Errorf("setting config item %s: %w", ChooseNamespaceConfigName, err)
+	}
+
 	return nil
 }
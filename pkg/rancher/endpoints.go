@@ -18,19 +18,49 @@ package rancher
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 )
 
 const (
-	adAuthTemplate   = "%s-public/activeDirectoryProviders/activedirectory?action=login"
-	clustersTemplate = "%s/clusters"
-	clusterTemplate  = "%s/clusters/%s"
+	adAuthTemplate         = "%s-public/activeDirectoryProviders/activedirectory?action=login"
+	loginTemplate          = "%s-public/%s/%s?action=login"
+	authProvidersTemplate  = "%s-public/authProviders"
+	authTokenTemplate      = "%s-public/authTokens/%s"
+	dashboardLoginTemplate = "%s/dashboard/auth/login?requestId=%s&publicKey=%s&responseType=kubeconfig"
+	currentUserTemplate    = "%s/users?me=true"
+	clustersTemplate       = "%s/clusters"
+	clusterTemplate        = "%s/clusters/%s"
+	tokensTemplate         = "%s/tokens"
+	projectsTemplate       = "%s/projects?clusterId=%s"
+	namespacesTemplate     = "%s/cluster/%s/namespaces?projectId=%s"
+	tokenTemplate          = "%s/tokens/%s"
+
+	apiVersionSuffix = "/v3"
 )
 
+// authProviderCollections maps the id of the auth providers that support logging in
+// with a username and password to the name of their collection in the Rancher api
+var authProviderCollections = map[string]string{
+	AuthProviderActiveDirectory: "activeDirectoryProviders",
+	AuthProviderLocal:           "localProviders",
+	AuthProviderOpenLDAP:        "openLdapProviders",
+	AuthProviderFreeIPA:         "freeIpaProviders",
+}
+
 type EndpointsResolver interface {
 	ActiveDirectoryAuth() string
+	Login(authProvider string) (string, error)
+	AuthProviders() string
+	AuthToken(requestID string) string
+	DashboardLogin(requestID, publicKey string) string
+	CurrentUser() string
 	ClustersList() string
 	Cluster(clusterName string) string
+	Tokens() string
+	Token(tokenName string) string
+	Projects(clusterID string) string
+	Namespaces(clusterID, projectID string) string
 }
 
 func NewStaticEndpointsResolver(apiEndpoint string) (EndpointsResolver, error) {
@@ -55,6 +85,36 @@ func (r *StaticEndpointsResolver) ActiveDirectoryAuth() string {
 	return fmt.Sprintf(adAuthTemplate, r.apiEndpoint)
 }
 
+// Login returns the login endpoint for an auth provider that supports a username and password
+func (r *StaticEndpointsResolver) Login(authProvider string) (string, error) {
+	collection, ok := authProviderCollections[authProvider]
+	if !ok {
+		return "", fmt.Errorf("getting login endpoint for %s: %w", authProvider, ErrUnsupportedAuthProvider)
+	}
+
+	return fmt.Sprintf(loginTemplate, r.apiEndpoint, collection, authProvider), nil
+}
+
+func (r *StaticEndpointsResolver) AuthProviders() string {
+	return fmt.Sprintf(authProvidersTemplate, r.apiEndpoint)
+}
+
+func (r *StaticEndpointsResolver) AuthToken(requestID string) string {
+	return fmt.Sprintf(authTokenTemplate, r.apiEndpoint, requestID)
+}
+
+// DashboardLogin returns the url of the Rancher dashboard that the user opens in a browser to
+// login. The generated token is encrypted with the public key.
+func (r *StaticEndpointsResolver) DashboardLogin(requestID, publicKey string) string {
+	serverURL := strings.TrimSuffix(strings.TrimSuffix(r.apiEndpoint, "/"), apiVersionSuffix)
+
+	return fmt.Sprintf(dashboardLoginTemplate, serverURL, url.QueryEscape(requestID), url.QueryEscape(publicKey))
+}
+
+func (r *StaticEndpointsResolver) CurrentUser() string {
+	return fmt.Sprintf(currentUserTemplate, r.apiEndpoint)
+}
+
 func (r *StaticEndpointsResolver) ClustersList() string {
 	return fmt.Sprintf(clustersTemplate, r.apiEndpoint)
 }
@@ -62,3 +122,19 @@ func (r *StaticEndpointsResolver) ClustersList() string {
 func (r *StaticEndpointsResolver) Cluster(clusterName string) string {
 	return fmt.Sprintf(clusterTemplate, r.apiEndpoint, clusterName)
 }
+
+func (r *StaticEndpointsResolver) Tokens() string {
+	return fmt.Sprintf(tokensTemplate, r.apiEndpoint)
+}
+
+func (r *StaticEndpointsResolver) Token(tokenName string) string {
+	return fmt.Sprintf(tokenTemplate, r.apiEndpoint, tokenName)
+}
+
+func (r *StaticEndpointsResolver) Projects(clusterID string) string {
+	return fmt.Sprintf(projectsTemplate, r.apiEndpoint, url.QueryEscape(clusterID))
+}
+
+func (r *StaticEndpointsResolver) Namespaces(clusterID, projectID string) string {
+	return fmt.Sprintf(namespacesTemplate, r.apiEndpoint, url.PathEscape(clusterID), url.QueryEscape(projectID))
+}
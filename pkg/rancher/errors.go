@@ -19,5 +19,18 @@ package rancher
 import "errors"
 
 var (
-	ErrNoAPIEndpoint = errors.New("no rancher api endpoint")
+	ErrNoAPIEndpoint            = errors.New("no rancher api endpoint")
+	ErrUnsupportedAuthProvider  = errors.New("auth provider doesn't support username and password login")
+	ErrAuthProviderNotEnabled   = errors.New("auth provider isn't enabled in Rancher")
+	ErrListingAuthProviders     = errors.New("failed listing the Rancher auth providers")
+	ErrAuthenticationFailed     = errors.New("failed to authenticate with Rancher")
+	ErrBrowserLoginTimeout      = errors.New("timed out waiting for the browser login to complete")
+	ErrBrowserLoginTokenExpired = errors.New("the browser login token has expired")
+	ErrGettingCurrentUser       = errors.New("failed getting the current Rancher user")
+	ErrNoEnabledAuthProviders   = errors.New("no supported auth providers are enabled in Rancher")
+	ErrListingTokens            = errors.New("failed listing the Rancher tokens")
+	ErrGettingToken             = errors.New("failed getting the Rancher token")
+	ErrTokenNotFound            = errors.New("the Rancher token doesn't exist or has been revoked")
+	ErrDeletingToken            = errors.New("failed deleting the Rancher token")
+	ErrNoStoredToken            = errors.New("no stored Rancher token")
 )
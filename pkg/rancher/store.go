@@ -0,0 +1,274 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rancher
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
+
+	"github.com/fidelity/kconnect/pkg/defaults"
+	khttp "github.com/fidelity/kconnect/pkg/http"
+	"github.com/fidelity/kconnect/pkg/provider/identity"
+)
+
+const tokenStoreFileName = "rancher-tokens.yaml"
+
+// StoredToken is a Rancher token that has been saved in the token store
+type StoredToken struct {
+	APIEndpoint      string     `json:"apiEndpoint"`
+	Username         string     `json:"username,omitempty"`
+	IdentityProvider string     `json:"identityProvider"`
+	UserID           string     `json:"userId"`
+	Token            string     `json:"token"`
+	ExpiresAt        *time.Time `json:"expiresAt,omitempty"`
+}
+
+// IsExpired returns true if the token has expired
+func (t *StoredToken) IsExpired() bool {
+	return t.ExpiresAt != nil && time.Now().After(*t.ExpiresAt)
+}
+
+type tokenStoreFile struct {
+	Tokens []*StoredToken `json:"tokens"`
+}
+
+// TokenStorePath returns the path of the file that the Rancher tokens are stored in
+func TokenStorePath() string {
+	return path.Join(defaults.AppDirectory(), tokenStoreFileName)
+}
+
+// NewIdentityStore will create a new Rancher identity store. The tokens are stored per
+// Rancher api endpoint, username and identity provider so that they can be reused
+// until they expire.
+func NewIdentityStore(apiEndpoint, username, idProviderName string) (*IdentityStore, error) {
+	return newIdentityStore(TokenStorePath(), apiEndpoint, username, idProviderName)
+}
+
+func newIdentityStore(storePath, apiEndpoint, username, idProviderName string) (*IdentityStore, error) {
+	if apiEndpoint == "" {
+		return nil, ErrNoAPIEndpoint
+	}
+
+	return &IdentityStore{
+		path:           storePath,
+		apiEndpoint:    normalizeAPIEndpoint(apiEndpoint),
+		username:       username,
+		idProviderName: idProviderName,
+	}, nil
+}
+
+// IdentityStore is a store for the Rancher tokens
+type IdentityStore struct {
+	path           string
+	apiEndpoint    string
+	username       string
+	idProviderName string
+}
+
+func (s *IdentityStore) CredsExists() (bool, error) {
+	stored, err := s.find()
+	if err != nil {
+		return false, err
+	}
+
+	return stored != nil, nil
+}
+
+func (s *IdentityStore) Save(userID identity.Identity) error {
+	tokenID, ok := userID.(*identity.TokenIdentity)
+	if !ok {
+		return identity.ErrNotTokenIdentity
+	}
+
+	stored := &StoredToken{
+		APIEndpoint:      s.apiEndpoint,
+		Username:         s.username,
+		IdentityProvider: s.idProviderName,
+		UserID:           tokenID.Name(),
+		Token:            tokenID.Token(),
+	}
+
+	if expiresAt := tokenID.ExpiresAt(); !expiresAt.IsZero() {
+		stored.ExpiresAt = &expiresAt
+	}
+
+	return s.update(func(tokens []*StoredToken) []*StoredToken {
+		return append(s.without(tokens), stored)
+	})
+}
+
+func (s *IdentityStore) Load() (identity.Identity, error) {
+	stored, err := s.find()
+	if err != nil {
+		return nil, err
+	}
+
+	if stored == nil {
+		return nil, ErrNoStoredToken
+	}
+
+	tokenID := identity.NewTokenIdentity(stored.UserID, stored.Token, stored.IdentityProvider)
+	if stored.ExpiresAt != nil {
+		tokenID.WithExpiry(*stored.ExpiresAt)
+	}
+
+	return tokenID, nil
+}
+
+func (s *IdentityStore) Expired() bool {
+	stored, err := s.find()
+	if err != nil || stored == nil {
+		return true
+	}
+
+	return stored.IsExpired()
+}
+
+// Delete will remove the token from the store
+func (s *IdentityStore) Delete() error {
+	return s.update(s.without)
+}
+
+func (s *IdentityStore) matches(stored *StoredToken) bool {
+	return stored.APIEndpoint == s.apiEndpoint && stored.Username == s.username && stored.IdentityProvider == s.idProviderName
+}
+
+func (s *IdentityStore) without(tokens []*StoredToken) []*StoredToken {
+	remaining := []*StoredToken{}
+
+	for _, stored := range tokens {
+		if !s.matches(stored) {
+			remaining = append(remaining, stored)
+		}
+	}
+
+	return remaining
+}
+
+func (s *IdentityStore) find() (*StoredToken, error) {
+	tokens, err := readTokenStore(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stored := range tokens {
+		if s.matches(stored) {
+			return stored, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *IdentityStore) update(updateFn func(tokens []*StoredToken) []*StoredToken) error {
+	tokens, err := readTokenStore(s.path)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&tokenStoreFile{Tokens: updateFn(tokens)})
+	if err != nil {
+		return fmt.Errorf("marshalling token store: %w", err)
+	}
+
+	// The file contains credentials so it's only readable by the user
+	if err := ioutil.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("saving token store to %s: %w", s.path, err)
+	}
+
+	return nil
+}
+
+// LoadValidIdentity will load the identity from the store if the token hasn't expired and is
+// still accepted by Rancher. Nil is returned if there isn't a token that can be reused.
+func LoadValidIdentity(httpClient khttp.Client, resolver EndpointsResolver, store identity.Store) *identity.TokenIdentity {
+	if store.Expired() {
+		return nil
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		zap.S().Debugw("failed loading stored Rancher token", "error", err.Error())
+		return nil
+	}
+
+	tokenID, ok := loaded.(*identity.TokenIdentity)
+	if !ok {
+		return nil
+	}
+
+	details, err := GetToken(httpClient, resolver, tokenID.Token(), TokenName(tokenID.Token()))
+	if err != nil {
+		zap.S().Debugw("stored Rancher token can't be reused", "error", err.Error())
+		return nil
+	}
+
+	if details.Expired {
+		return nil
+	}
+
+	return tokenID
+}
+
+// ListStoredTokens will return the tokens in the token store for a Rancher api endpoint
+func ListStoredTokens(apiEndpoint string) ([]*StoredToken, error) {
+	tokens, err := readTokenStore(TokenStorePath())
+	if err != nil {
+		return nil, err
+	}
+
+	apiEndpoint = normalizeAPIEndpoint(apiEndpoint)
+	stored := []*StoredToken{}
+
+	for _, token := range tokens {
+		if token.APIEndpoint == apiEndpoint {
+			stored = append(stored, token)
+		}
+	}
+
+	return stored, nil
+}
+
+func readTokenStore(storePath string) ([]*StoredToken, error) {
+	data, err := ioutil.ReadFile(storePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("reading token store %s: %w", storePath, err)
+	}
+
+	storeFile := &tokenStoreFile{}
+	if err := yaml.Unmarshal(data, storeFile); err != nil {
+		return nil, fmt.Errorf("unmarshalling token store: %w", err)
+	}
+
+	return storeFile.Tokens, nil
+}
+
+func normalizeAPIEndpoint(apiEndpoint string) string {
+	return strings.TrimSuffix(apiEndpoint, "/")
+}
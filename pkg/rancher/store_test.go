@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rancher
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fidelity/kconnect/pkg/provider/identity"
+)
+
+func Test_IdentityStore(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), tokenStoreFileName)
+
+	store, err := newIdentityStore(storePath, "https://rancher.test/v3/", "bob", "rancher-local")
+	if err != nil {
+		t.Fatalf("creating store: %s", err)
+	}
+
+	if _, err := store.Load(); !errors.Is(err, ErrNoStoredToken) {
+		t.Fatalf("expected ErrNoStoredToken but got: %v", err)
+	}
+
+	if !store.Expired() {
+		t.Fatal("expected missing token to be expired")
+	}
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := store.Save(identity.NewTokenIdentity("u-bob", "token-abc:secret", "rancher-local").WithExpiry(expiresAt)); err != nil {
+		t.Fatalf("saving token: %s", err)
+	}
+
+	otherStore, _ := newIdentityStore(storePath, "https://rancher.test/v3", "alice", "rancher-local")
+	if err := otherStore.Save(identity.NewTokenIdentity("u-alice", "token-def:secret", "rancher-local").WithExpiry(time.Now().Add(-time.Hour))); err != nil {
+		t.Fatalf("saving other token: %s", err)
+	}
+
+	// browser logins have no username so the identity provider is also used to find the token
+	githubStore, _ := newIdentityStore(storePath, "https://rancher.test/v3", "", "rancher-github")
+	if err := githubStore.Save(identity.NewTokenIdentity("u-github", "token-github:secret", "rancher-github")); err != nil {
+		t.Fatalf("saving github token: %s", err)
+	}
+
+	ssoStore, _ := newIdentityStore(storePath, "https://rancher.test/v3", "", "rancher-sso")
+	if exists, _ := ssoStore.CredsExists(); exists {
+		t.Fatal("expected no token for a different identity provider")
+	}
+
+	adStore, _ := newIdentityStore(storePath, "https://rancher.test/v3", "bob", "rancher-ad")
+	if exists, _ := adStore.CredsExists(); exists {
+		t.Fatal("expected no token for the same username with a different identity provider")
+	}
+
+	if store.Expired() {
+		t.Fatal("expected token not to be expired")
+	}
+
+	if !otherStore.Expired() {
+		t.Fatal("expected other token to be expired")
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("loading token: %s", err)
+	}
+
+	tokenID, ok := loaded.(*identity.TokenIdentity)
+	if !ok {
+		t.Fatalf("expected token identity but got %T", loaded)
+	}
+
+	if tokenID.Token() != "token-abc:secret" || tokenID.Name() != "u-bob" || !tokenID.ExpiresAt().Equal(expiresAt) {
+		t.Fatalf("unexpected token loaded: %s %s %s", tokenID.Name(), tokenID.Token(), tokenID.ExpiresAt())
+	}
+
+	if err := store.Delete(); err != nil {
+		t.Fatalf("deleting token: %s", err)
+	}
+
+	if exists, _ := store.CredsExists(); exists {
+		t.Fatal("expected token to be deleted")
+	}
+
+	if exists, _ := otherStore.CredsExists(); !exists {
+		t.Fatal("expected other token to still exist")
+	}
+
+	if exists, _ := githubStore.CredsExists(); !exists {
+		t.Fatal("expected github token to still exist")
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fidelity/kconnect/pkg/defaults"
+	khttp "github.com/fidelity/kconnect/pkg/http"
+)
+
+// TokenDescriptionPrefix is the prefix of the description of the tokens created by kconnect
+const TokenDescriptionPrefix = "kconnect"
+
+// Token represents a Rancher api token
+type Token struct {
+	Name        string `json:"name"`
+	UserID      string `json:"userId"`
+	Description string `json:"description"`
+	Created     string `json:"created"`
+	ExpiresAt   string `json:"expiresAt"`
+	Expired     bool   `json:"expired"`
+	Current     bool   `json:"current"`
+	IsDerived   bool   `json:"isDerived"`
+}
+
+func init() {
+	// The kubeconfig generated by Rancher is returned as a string that contains the token
+	khttp.RegisterRedaction(&khttp.Redaction{Pattern: regexp.MustCompile(`(token: (?:\\?")?)[^"\\\s]+`)})
+}
+
+type tokensResponse struct {
+	Data []Token `json:"data"`
+}
+
+// CreatedByKconnect returns true if the token was created by kconnect
+func (t *Token) CreatedByKconnect() bool {
+	return strings.HasPrefix(t.Description, TokenDescriptionPrefix)
+}
+
+// Expiry returns the time the token expires or the zero time if the token doesn't expire
+func (t *Token) Expiry() time.Time {
+	return parseExpiry(t.ExpiresAt)
+}
+
+// TokenDescription returns the description used for the tokens created by kconnect. The
+// hostname is included so that the tokens from different machines can be told apart.
+func TokenDescription() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return TokenDescriptionPrefix
+	}
+
+	return fmt.Sprintf("%s (%s)", TokenDescriptionPrefix, hostname)
+}
+
+// TokenName returns the name of a token from its value. Rancher tokens are in the
+// format name:secret.
+func TokenName(token string) string {
+	parts := strings.SplitN(token, ":", 2)
+
+	return parts[0]
+}
+
+// ListTokens will return the tokens of the user that the token belongs to
+func ListTokens(httpClient khttp.Client, resolver EndpointsResolver, token string) ([]Token, error) {
+	headers := defaults.Headers(defaults.WithNoCache(), defaults.WithJSON(), defaults.WithBearerAuth(token))
+
+	resp, err := httpClient.Get(resolver.Tokens(), headers)
+	if err != nil {
+		return nil, fmt.Errorf("getting tokens using api: %w", err)
+	}
+
+	if resp.ResponseCode() != http.StatusOK {
+		return nil, ErrListingTokens
+	}
+
+	tokens := &tokensResponse{}
+	if err := json.Unmarshal([]byte(resp.Body()), tokens); err != nil {
+		return nil, fmt.Errorf("unmarshalling tokens response: %w", err)
+	}
+
+	return tokens.Data, nil
+}
+
+// GetToken will get the details of a token. ErrTokenNotFound is returned if the token
+// doesn't exist or has been revoked.
+func GetToken(httpClient khttp.Client, resolver EndpointsResolver, token, tokenName string) (*Token, error) {
+	headers := defaults.Headers(defaults.WithNoCache(), defaults.WithJSON(), defaults.WithBearerAuth(token))
+
+	resp, err := httpClient.Get(resolver.Token(tokenName), headers)
+	if err != nil {
+		return nil, fmt.Errorf("getting token %s using api: %w", tokenName, err)
+	}
+
+	switch resp.ResponseCode() {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return nil, fmt.Errorf("getting token %s: %w", tokenName, ErrTokenNotFound)
+	default:
+		return nil, fmt.Errorf("getting token %s: %w", tokenName, ErrGettingToken)
+	}
+
+	details := &Token{}
+	if err := json.Unmarshal([]byte(resp.Body()), details); err != nil {
+		return nil, fmt.Errorf("unmarshalling token response: %w", err)
+	}
+
+	return details, nil
+}
+
+// DeleteToken will revoke a token. Revoking a token that no longer exists isn't an error.
+func DeleteToken(httpClient khttp.Client, resolver EndpointsResolver, token, tokenName string) error {
+	resp, err := httpClient.Do(&khttp.ClientRequest{
+		Method:  http.MethodDelete,
+		URL:     resolver.Token(tokenName),
+		Headers: defaults.Headers(defaults.WithJSON(), defaults.WithBearerAuth(token)),
+	})
+	if err != nil {
+		return fmt.Errorf("deleting token %s using api: %w", tokenName, err)
+	}
+
+	switch resp.ResponseCode() {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("deleting token %s: %w", tokenName, ErrDeletingToken)
+	}
+}
+
+func parseExpiry(expiresAt string) time.Time {
+	if expiresAt == "" {
+		return time.Time{}
+	}
+
+	expiry, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return expiry
+}
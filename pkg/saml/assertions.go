@@ -0,0 +1,240 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package saml
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+
+	"github.com/fidelity/kconnect/pkg/defaults"
+	"github.com/fidelity/kconnect/pkg/encryptedfile"
+)
+
+const (
+	assertionCacheFileName    = "saml-assertions.enc"
+	assertionCacheKeyFileName = "saml-assertions.key"
+
+	// AssertionExpiryMargin is how long before its NotOnOrAfter time that a cached assertion
+	// is no longer used, so that it doesn't expire before AWS has accepted it
+	AssertionExpiryMargin = time.Minute
+
+	notOnOrAfterAttr = "NotOnOrAfter"
+)
+
+// CachedAssertion is a SAML assertion that has been saved in the assertion cache
+type CachedAssertion struct {
+	IdpEndpoint  string    `json:"idpEndpoint"`
+	Username     string    `json:"username"`
+	Assertion    string    `json:"assertion"`
+	NotOnOrAfter time.Time `json:"notOnOrAfter"`
+}
+
+// IsValid returns true if the assertion can still be used
+func (a *CachedAssertion) IsValid() bool {
+	return time.Now().Add(AssertionExpiryMargin).Before(a.NotOnOrAfter)
+}
+
+type assertionCacheFile struct {
+	Assertions []*CachedAssertion `json:"assertions"`
+}
+
+// AssertionCachePath returns the path of the file that the SAML assertions are cached in
+func AssertionCachePath() string {
+	return path.Join(defaults.AppDirectory(), assertionCacheFileName)
+}
+
+// AssertionCache is a file based cache of SAML assertions, keyed by the IdP endpoint
+// and username. The file is only readable by the user and is obfuscated by encrypting
+// it with a key stored alongside it, which doesn't protect it from anyone who can read
+// the user's files.
+type AssertionCache struct {
+	file *encryptedfile.File
+}
+
+// NewAssertionCache will create an assertion cache that uses the default paths in the app directory
+func NewAssertionCache() *AssertionCache {
+	return newAssertionCache(AssertionCachePath(), path.Join(defaults.AppDirectory(), assertionCacheKeyFileName))
+}
+
+func newAssertionCache(cachePath, keyPath string) *AssertionCache {
+	return &AssertionCache{
+		file: encryptedfile.New(cachePath, keyPath),
+	}
+}
+
+// Find returns the cached assertion for the IdP endpoint and username if it's still valid.
+// Nil is returned if there isn't a valid cached assertion.
+func (c *AssertionCache) Find(idpEndpoint, username string) (*CachedAssertion, error) {
+	assertions, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, assertion := range assertions {
+		if assertion.matches(idpEndpoint, username) && assertion.IsValid() {
+			return assertion, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Put will save an assertion in the cache, replacing any assertion for the same IdP endpoint
+// and username. The expired assertions are removed at the same time.
+func (c *AssertionCache) Put(idpEndpoint, username, assertion string) (*CachedAssertion, error) {
+	notOnOrAfter, err := AssertionExpiry(assertion)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := &CachedAssertion{
+		IdpEndpoint:  normalizeEndpoint(idpEndpoint),
+		Username:     username,
+		Assertion:    assertion,
+		NotOnOrAfter: notOnOrAfter,
+	}
+
+	err = c.update(func(assertions []*CachedAssertion) []*CachedAssertion {
+		updated := []*CachedAssertion{cached}
+
+		for _, existing := range assertions {
+			if !existing.matches(idpEndpoint, username) && existing.IsValid() {
+				updated = append(updated, existing)
+			}
+		}
+
+		return updated
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cached, nil
+}
+
+// Delete will remove the cached assertion for the IdP endpoint and username. If the username
+// is empty then the assertions of all users of the IdP endpoint are removed.
+func (c *AssertionCache) Delete(idpEndpoint, username string) error {
+	return c.update(func(assertions []*CachedAssertion) []*CachedAssertion {
+		updated := []*CachedAssertion{}
+
+		for _, existing := range assertions {
+			if existing.IdpEndpoint == normalizeEndpoint(idpEndpoint) && (username == "" || existing.matches(idpEndpoint, username)) {
+				continue
+			}
+
+			updated = append(updated, existing)
+		}
+
+		return updated
+	})
+}
+
+func (a *CachedAssertion) matches(idpEndpoint, username string) bool {
+	return a.IdpEndpoint == normalizeEndpoint(idpEndpoint) && strings.EqualFold(a.Username, username)
+}
+
+func normalizeEndpoint(idpEndpoint string) string {
+	return strings.TrimSuffix(idpEndpoint, "/")
+}
+
+// AssertionExpiry returns the earliest NotOnOrAfter time in a base64 encoded SAML response.
+// This is the time until which the assertion can be used to assume roles.
+func AssertionExpiry(assertion string) (time.Time, error) {
+	data, err := base64.StdEncoding.DecodeString(assertion)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("decoding saml assertion: %w", err)
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return time.Time{}, fmt.Errorf("parsing saml assertion: %w", err)
+	}
+
+	var expiry time.Time
+
+	for _, element := range doc.FindElements("//[@" + notOnOrAfterAttr + "]") {
+		value, err := time.Parse(time.RFC3339, element.SelectAttrValue(notOnOrAfterAttr, ""))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parsing %s of %s: %w", notOnOrAfterAttr, element.Tag, err)
+		}
+
+		if expiry.IsZero() || value.Before(expiry) {
+			expiry = value
+		}
+	}
+
+	if expiry.IsZero() {
+		return time.Time{}, ErrNoAssertionExpiry
+	}
+
+	return expiry, nil
+}
+
+func (c *AssertionCache) update(updateFn func(assertions []*CachedAssertion) []*CachedAssertion) error {
+	err := c.file.Update(func(data []byte) ([]byte, error) {
+		assertions, err := unmarshalAssertions(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(&assertionCacheFile{Assertions: updateFn(assertions)})
+	})
+	if err != nil {
+		return fmt.Errorf("updating assertion cache: %w", assertionCacheError(err))
+	}
+
+	return nil
+}
+
+func (c *AssertionCache) read() ([]*CachedAssertion, error) {
+	data, err := c.file.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading assertion cache: %w", assertionCacheError(err))
+	}
+
+	return unmarshalAssertions(data)
+}
+
+func unmarshalAssertions(data []byte) ([]*CachedAssertion, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	cacheFile := &assertionCacheFile{}
+	if err := json.Unmarshal(data, cacheFile); err != nil {
+		return nil, fmt.Errorf("unmarshalling assertion cache: %w", err)
+	}
+
+	return cacheFile.Assertions, nil
+}
+
+// assertionCacheError returns ErrInvalidAssertionCache if the cache can't be decrypted
+func assertionCacheError(err error) error {
+	if errors.Is(err, encryptedfile.ErrInvalidFile) {
+		return fmt.Errorf("%s: %w", err.Error(), ErrInvalidAssertionCache)
+	}
+
+	return err
+}
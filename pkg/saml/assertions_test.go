@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package saml
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testAssertion(notOnOrAfter ...time.Time) string {
+	response := `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion"><saml:Assertion>`
+	for _, t := range notOnOrAfter {
+		response += fmt.Sprintf(`<saml:Conditions NotOnOrAfter="%s"/>`, t.UTC().Format("2006-01-02T15:04:05.000Z"))
+	}
+
+	response += `</saml:Assertion></samlp:Response>`
+
+	return base64.StdEncoding.EncodeToString([]byte(response))
+}
+
+func Test_AssertionExpiry(t *testing.T) {
+	earliest := time.Now().Add(5 * time.Minute).Truncate(time.Millisecond)
+
+	expiry, err := AssertionExpiry(testAssertion(earliest.Add(time.Hour), earliest))
+	if err != nil {
+		t.Fatalf("getting expiry: %s", err)
+	}
+
+	if !expiry.Equal(earliest) {
+		t.Fatalf("expected expiry %s but got %s", earliest, expiry)
+	}
+
+	if _, err := AssertionExpiry(testAssertion()); !errors.Is(err, ErrNoAssertionExpiry) {
+		t.Fatalf("expected ErrNoAssertionExpiry but got: %v", err)
+	}
+}
+
+func Test_AssertionCache(t *testing.T) {
+	dir := t.TempDir()
+	cache := newAssertionCache(filepath.Join(dir, assertionCacheFileName), filepath.Join(dir, assertionCacheKeyFileName))
+
+	cached, err := cache.Find("https://idp.test/saml", "bob")
+	if err != nil {
+		t.Fatalf("finding in empty cache: %s", err)
+	}
+
+	if cached != nil {
+		t.Fatal("expected no cached assertion")
+	}
+
+	bobAssertion := testAssertion(time.Now().Add(5 * time.Minute))
+	if _, err := cache.Put("https://idp.test/saml/", "bob", bobAssertion); err != nil {
+		t.Fatalf("caching assertion: %s", err)
+	}
+
+	if _, err := cache.Put("https://idp.test/saml", "alice", testAssertion(time.Now().Add(30*time.Second))); err != nil {
+		t.Fatalf("caching expiring assertion: %s", err)
+	}
+
+	cached, err = cache.Find("https://idp.test/saml", "BOB")
+	if err != nil {
+		t.Fatalf("finding assertion: %s", err)
+	}
+
+	if cached == nil || cached.Assertion != bobAssertion {
+		t.Fatalf("expected cached assertion for bob but got %v", cached)
+	}
+
+	if cached, _ := cache.Find("https://idp.test/saml", "alice"); cached != nil {
+		t.Fatal("expected assertion within the expiry margin not to be used")
+	}
+
+	if err := cache.Delete("https://idp.test/saml", ""); err != nil {
+		t.Fatalf("deleting assertions: %s", err)
+	}
+
+	if cached, _ := cache.Find("https://idp.test/saml", "bob"); cached != nil {
+		t.Fatal("expected assertion to be deleted")
+	}
+
+	otherCache := newAssertionCache(filepath.Join(dir, assertionCacheFileName), filepath.Join(t.TempDir(), assertionCacheKeyFileName))
+	if _, err := otherCache.Find("https://idp.test/saml", "bob"); !errors.Is(err, ErrInvalidAssertionCache) {
+		t.Fatalf("expected ErrInvalidAssertionCache without the key but got: %v", err)
+	}
+}
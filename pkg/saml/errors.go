@@ -1,5 +1,5 @@
 /*
-Copyright 2020 The kconnect Authors.
+Copyright 2021 The kconnect Authors.
 
 Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
@@ -14,21 +14,11 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package activedirectory
+package saml
 
-import "encoding/json"
+import "errors"
 
-type loginRequest struct {
-	Type        string `json:"type"`
-	Description string `json:"description"`
-	Username    string `json:"username"`
-	Password    string `json:"password"`
-}
-
-type loginResponse struct { //TODO: add additional fields
-	Type   string      `json:"type"`
-	Name   string      `json:"name"`
-	Token  string      `json:"token"`
-	UserID string      `json:"userId"`
-	TTL    json.Number `json:"ttl"`
-}
+var (
+	ErrInvalidAssertionCache = errors.New("invalid saml assertion cache")
+	ErrNoAssertionExpiry     = errors.New("saml assertion doesn't have a NotOnOrAfter time")
+)
@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// handleConnect will handle a HTTP CONNECT request
+func (f *Forwarder) handleConnect(reader *bufio.Reader, conn net.Conn) (net.Conn, error) {
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading http request: %w", err)
+	}
+
+	if req.Method != http.MethodConnect {
+		writeHTTPStatus(conn, http.StatusMethodNotAllowed)
+		return nil, fmt.Errorf("method %s: %w", req.Method, ErrMethodNotSupported)
+	}
+
+	f.logger.Debugw("forwarding http connect", "address", req.Host)
+
+	target, err := f.dial("tcp", req.Host)
+	if err != nil {
+		writeHTTPStatus(conn, http.StatusBadGateway)
+		return nil, fmt.Errorf("dialing %s: %w", req.Host, err)
+	}
+
+	if _, err := fmt.Fprint(conn, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+		target.Close() //nolint: errcheck
+		return nil, fmt.Errorf("writing http response: %w", err)
+	}
+
+	return target, nil
+}
+
+func writeHTTPStatus(conn net.Conn, status int) {
+	fmt.Fprintf(conn, "HTTP/1.1 %d %s\r\n\r\n", status, http.StatusText(status)) //nolint: errcheck
+}
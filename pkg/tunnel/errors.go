@@ -0,0 +1,29 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import "errors"
+
+var (
+	ErrSOCKSAuthNotSupported    = errors.New("socks authentication isn't supported")
+	ErrSOCKSCommandNotSupported = errors.New("only the socks connect command is supported")
+	ErrSOCKSAddrNotSupported    = errors.New("unsupported socks address type")
+	ErrMethodNotSupported       = errors.New("only the http connect method is supported")
+	ErrJumpHostRequired         = errors.New("jump host is required")
+	ErrNoSSHAuth                = errors.New("no ssh key file or ssh agent available")
+	ErrSSHKeyPassphrase         = errors.New("ssh key file needs a passphrase, add the key to the ssh agent")
+)
@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultHandshakeTimeout is how long a client has to send the proxy request
+	defaultHandshakeTimeout = 30 * time.Second
+)
+
+// DialFunc dials an address through the tunnel, e.g. using a SSH client
+type DialFunc func(network, addr string) (net.Conn, error)
+
+// Forwarder is a local proxy that accepts SOCKS5 and HTTP CONNECT requests and
+// forwards the connections using the dial function
+type Forwarder struct {
+	dial             DialFunc
+	handshakeTimeout time.Duration
+	logger           *zap.SugaredLogger
+}
+
+// NewForwarder creates a new forwarder that will dial the requested addresses using dial
+func NewForwarder(dial DialFunc) *Forwarder {
+	return &Forwarder{
+		dial:             dial,
+		handshakeTimeout: defaultHandshakeTimeout,
+		logger:           zap.S().With("component", "tunnel"),
+	}
+}
+
+// Serve will accept connections on the listener until the context is cancelled
+func (f *Forwarder) Serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		listener.Close() //nolint: errcheck
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return fmt.Errorf("accepting connection: %w", err)
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			f.handle(ctx, conn)
+		}()
+	}
+}
+
+func (f *Forwarder) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close() //nolint: errcheck
+
+	// stop a client that never sends a request from holding the connection open
+	if err := conn.SetDeadline(time.Now().Add(f.handshakeTimeout)); err != nil {
+		f.logger.Debugw("setting proxy handshake deadline", "error", err)
+		return
+	}
+
+	reader := bufio.NewReader(conn)
+
+	version, err := reader.Peek(1)
+	if err != nil {
+		f.logger.Debugw("reading proxy request", "error", err)
+		return
+	}
+
+	var target net.Conn
+
+	if version[0] == socksVersion {
+		target, err = f.handleSOCKS(reader, conn)
+	} else {
+		target, err = f.handleConnect(reader, conn)
+	}
+
+	if err != nil {
+		f.logger.Warnw("failed forwarding connection", "client", conn.RemoteAddr().String(), "error", err.Error())
+		return
+	}
+	defer target.Close() //nolint: errcheck
+
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		f.logger.Debugw("clearing proxy handshake deadline", "error", err)
+		return
+	}
+
+	pipe(ctx, &bufferedConn{Conn: conn, reader: reader}, target)
+}
+
+// pipe copies the data between the connections until one of them is closed
+func pipe(ctx context.Context, client, target net.Conn) {
+	done := make(chan struct{}, 2)
+
+	copyConn := func(dst, src net.Conn) {
+		io.Copy(dst, src) //nolint: errcheck
+		done <- struct{}{}
+	}
+
+	go copyConn(target, client)
+	go copyConn(client, target)
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// bufferedConn reads any data already buffered from the client before reading
+// from the connection
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
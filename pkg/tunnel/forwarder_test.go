@@ -0,0 +1,190 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+func Test_ForwarderSOCKS(t *testing.T) {
+	echoAddr := startEchoServer(t)
+	proxyAddr := startForwarder(t)
+
+	dialer, err := proxy.SOCKS5("tcp", proxyAddr, nil, proxy.Direct)
+	if err != nil {
+		t.Fatalf("creating socks dialer: %s", err)
+	}
+
+	conn, err := dialer.Dial("tcp", echoAddr)
+	if err != nil {
+		t.Fatalf("dialing through socks proxy: %s", err)
+	}
+	defer conn.Close()
+
+	assertEcho(t, conn, conn)
+}
+
+func Test_ForwarderConnect(t *testing.T) {
+	echoAddr := startEchoServer(t)
+	proxyAddr := startForwarder(t)
+
+	conn, err := net.Dial("tcp", proxyAddr)
+	if err != nil {
+		t.Fatalf("dialing proxy: %s", err)
+	}
+	defer conn.Close()
+
+	fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", echoAddr, echoAddr)
+
+	reader := bufio.NewReader(conn)
+
+	resp, err := http.ReadResponse(reader, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		t.Fatalf("reading connect response: %s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 but got %d", resp.StatusCode)
+	}
+
+	assertEcho(t, reader, conn)
+}
+
+func Test_ForwarderConnectMethodNotAllowed(t *testing.T) {
+	proxyAddr := startForwarder(t)
+
+	conn, err := net.Dial("tcp", proxyAddr)
+	if err != nil {
+		t.Fatalf("dialing proxy: %s", err)
+	}
+	defer conn.Close()
+
+	fmt.Fprint(conn, "GET http://example.com/ HTTP/1.1\r\nHost: example.com\r\n\r\n")
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("reading response: %s", err)
+	}
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status 405 but got %d", resp.StatusCode)
+	}
+}
+
+func Test_ForwarderHandshakeTimeout(t *testing.T) {
+	forwarder := NewForwarder(net.Dial)
+	forwarder.handshakeTimeout = 50 * time.Millisecond
+	proxyAddr := serveForwarder(t, forwarder)
+
+	conn, err := net.Dial("tcp", proxyAddr)
+	if err != nil {
+		t.Fatalf("dialing proxy: %s", err)
+	}
+	defer conn.Close()
+
+	// the forwarder should close the connection as no request is sent
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second)) //nolint: errcheck
+
+	if _, err := conn.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected the connection to be closed but got: %v", err)
+	}
+}
+
+func assertEcho(t *testing.T, reader io.Reader, writer io.Writer) {
+	t.Helper()
+
+	expected := "hello cluster"
+	if _, err := fmt.Fprint(writer, expected); err != nil {
+		t.Fatalf("writing to tunnel: %s", err)
+	}
+
+	actual := make([]byte, len(expected))
+	if _, err := io.ReadFull(reader, actual); err != nil {
+		t.Fatalf("reading from tunnel: %s", err)
+	}
+
+	if string(actual) != expected {
+		t.Fatalf("expected %q but got %q", expected, string(actual))
+	}
+}
+
+func startForwarder(t *testing.T) string {
+	t.Helper()
+
+	return serveForwarder(t, NewForwarder(net.Dial))
+}
+
+func serveForwarder(t *testing.T, forwarder *Forwarder) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		forwarder.Serve(ctx, listener) //nolint: errcheck
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return listener.Addr().String()
+}
+
+func startEchoServer(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %s", err)
+	}
+
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn) //nolint: errcheck
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
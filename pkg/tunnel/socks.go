@@ -0,0 +1,155 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+)
+
+const (
+	socksVersion = 0x05
+
+	socksMethodNoAuth       = 0x00
+	socksMethodNoAcceptable = 0xff
+
+	socksCmdConnect = 0x01
+
+	socksAddrIPv4   = 0x01
+	socksAddrDomain = 0x03
+	socksAddrIPv6   = 0x04
+
+	socksReplySucceeded           = 0x00
+	socksReplyGeneralFailure      = 0x01
+	socksReplyCommandNotSupported = 0x07
+	socksReplyAddrNotSupported    = 0x08
+)
+
+// handleSOCKS will handle a SOCKS5 request. Only the CONNECT command without authentication
+// is supported as the forwarder only listens locally.
+func (f *Forwarder) handleSOCKS(reader *bufio.Reader, conn net.Conn) (net.Conn, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("reading socks greeting: %w", err)
+	}
+
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(reader, methods); err != nil {
+		return nil, fmt.Errorf("reading socks methods: %w", err)
+	}
+
+	if !hasMethod(methods, socksMethodNoAuth) {
+		conn.Write([]byte{socksVersion, socksMethodNoAcceptable}) //nolint: errcheck
+		return nil, ErrSOCKSAuthNotSupported
+	}
+
+	if _, err := conn.Write([]byte{socksVersion, socksMethodNoAuth}); err != nil {
+		return nil, fmt.Errorf("writing socks method: %w", err)
+	}
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(reader, request); err != nil {
+		return nil, fmt.Errorf("reading socks request: %w", err)
+	}
+
+	if request[1] != socksCmdConnect {
+		writeSOCKSReply(conn, socksReplyCommandNotSupported) //nolint: errcheck
+		return nil, fmt.Errorf("command %d: %w", request[1], ErrSOCKSCommandNotSupported)
+	}
+
+	addr, err := readSOCKSAddr(reader, request[3])
+	if err != nil {
+		writeSOCKSReply(conn, socksReplyAddrNotSupported) //nolint: errcheck
+		return nil, err
+	}
+
+	f.logger.Debugw("forwarding socks connection", "address", addr)
+
+	target, err := f.dial("tcp", addr)
+	if err != nil {
+		writeSOCKSReply(conn, socksReplyGeneralFailure) //nolint: errcheck
+		return nil, fmt.Errorf("dialing %s: %w", addr, err)
+	}
+
+	if err := writeSOCKSReply(conn, socksReplySucceeded); err != nil {
+		target.Close() //nolint: errcheck
+		return nil, fmt.Errorf("writing socks reply: %w", err)
+	}
+
+	return target, nil
+}
+
+func readSOCKSAddr(reader *bufio.Reader, addrType byte) (string, error) {
+	var host string
+
+	switch addrType {
+	case socksAddrIPv4, socksAddrIPv6:
+		size := net.IPv4len
+		if addrType == socksAddrIPv6 {
+			size = net.IPv6len
+		}
+
+		ip := make([]byte, size)
+		if _, err := io.ReadFull(reader, ip); err != nil {
+			return "", fmt.Errorf("reading socks address: %w", err)
+		}
+
+		host = net.IP(ip).String()
+	case socksAddrDomain:
+		length, err := reader.ReadByte()
+		if err != nil {
+			return "", fmt.Errorf("reading socks domain length: %w", err)
+		}
+
+		domain := make([]byte, length)
+		if _, err := io.ReadFull(reader, domain); err != nil {
+			return "", fmt.Errorf("reading socks domain: %w", err)
+		}
+
+		host = string(domain)
+	default:
+		return "", fmt.Errorf("address type %d: %w", addrType, ErrSOCKSAddrNotSupported)
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(reader, port); err != nil {
+		return "", fmt.Errorf("reading socks port: %w", err)
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), nil
+}
+
+// writeSOCKSReply writes a reply with an empty bound address as the address isn't used by clients
+func writeSOCKSReply(conn net.Conn, reply byte) error {
+	_, err := conn.Write([]byte{socksVersion, reply, 0x00, socksAddrIPv4, 0, 0, 0, 0, 0, 0})
+
+	return err
+}
+
+func hasMethod(methods []byte, method byte) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
+}
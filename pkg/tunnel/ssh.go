@@ -0,0 +1,181 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	defaultSSHPort = "22"
+)
+
+// SSHConfig is the configuration used to connect to the jump host
+type SSHConfig struct {
+	// JumpHost is the host to connect to in the format [user@]host[:port]
+	JumpHost string
+	// KeyFile is the private key to authenticate with. The ssh agent is also used if it's running.
+	KeyFile string
+	// KnownHostsFile is used to verify the host key of the jump host
+	KnownHostsFile string
+}
+
+// DialSSH will connect to the jump host. The returned client can be used to dial
+// addresses that are reachable from the jump host.
+func DialSSH(cfg *SSHConfig) (*ssh.Client, error) {
+	if cfg.JumpHost == "" {
+		return nil, ErrJumpHostRequired
+	}
+
+	username, addr, err := parseJumpHost(cfg.JumpHost)
+	if err != nil {
+		return nil, err
+	}
+
+	knownHostsFile, err := homedir.Expand(cfg.KnownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("expanding known hosts file path: %w", err)
+	}
+
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading known hosts file %s: %w", cfg.KnownHostsFile, err)
+	}
+
+	keyFile, err := homedir.Expand(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("expanding ssh key file path: %w", err)
+	}
+
+	// the agent is only used to sign during the handshake so it can be closed after dialing
+	agentConn := dialAgent()
+	if agentConn != nil {
+		defer agentConn.Close() //nolint: errcheck
+	}
+
+	authMethods, err := authMethods(keyFile, agentConn)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            username,
+		Auth:            authMethods,
+		HostKeyCallback: hostKeyCallback,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connecting to jump host %s: %w", addr, err)
+	}
+
+	return client, nil
+}
+
+// parseJumpHost will split [user@]host[:port] into the user and address. The current
+// user and port 22 are used by default.
+func parseJumpHost(jumpHost string) (string, string, error) {
+	username, host, found := strings.Cut(jumpHost, "@")
+	if !found {
+		host = jumpHost
+
+		current, err := user.Current()
+		if err != nil {
+			return "", "", fmt.Errorf("getting current user: %w", err)
+		}
+
+		username = current.Username
+	}
+
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, defaultSSHPort)
+	}
+
+	return username, host, nil
+}
+
+// dialAgent connects to the ssh agent if it's running
+func dialAgent() net.Conn {
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil
+	}
+
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		zap.S().Debugw("connecting to ssh agent", "error", err.Error())
+		return nil
+	}
+
+	return conn
+}
+
+// authMethods returns the methods used to authenticate with the jump host. If the key file
+// can't be used, for example it needs a passphrase, then it's skipped when the agent can be used.
+func authMethods(keyFile string, agentConn net.Conn) ([]ssh.AuthMethod, error) {
+	methods := []ssh.AuthMethod{}
+
+	if agentConn != nil {
+		methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+	}
+
+	if keyFile != "" {
+		signer, err := readKeyFile(keyFile)
+		switch {
+		case err == nil:
+			methods = append(methods, ssh.PublicKeys(signer))
+		case len(methods) == 0:
+			return nil, err
+		default:
+			zap.S().Debugw("skipping ssh key file, using the ssh agent", "error", err.Error())
+		}
+	}
+
+	if len(methods) == 0 {
+		return nil, ErrNoSSHAuth
+	}
+
+	return methods, nil
+}
+
+func readKeyFile(keyFile string) (ssh.Signer, error) {
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading ssh key file %s: %w", keyFile, err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		var passphraseErr *ssh.PassphraseMissingError
+		if errors.As(err, &passphraseErr) {
+			return nil, fmt.Errorf("ssh key file %s: %w", keyFile, ErrSSHKeyPassphrase)
+		}
+
+		return nil, fmt.Errorf("parsing ssh key file %s: %w", keyFile, err)
+	}
+
+	return signer, nil
+}
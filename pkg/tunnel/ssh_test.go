@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The kconnect Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func Test_AuthMethods(t *testing.T) {
+	testCases := []struct {
+		name          string
+		keyFile       string
+		agent         bool
+		expectMethods int
+		expectErr     error
+	}{
+		{
+			name:      "no key file or agent",
+			expectErr: ErrNoSSHAuth,
+		},
+		{
+			name:          "agent only",
+			agent:         true,
+			expectMethods: 1,
+		},
+		{
+			name:          "key file and agent",
+			keyFile:       "id_ed25519",
+			agent:         true,
+			expectMethods: 2,
+		},
+		{
+			name:          "key file only",
+			keyFile:       "id_ed25519",
+			expectMethods: 1,
+		},
+		{
+			name:          "missing key file with agent",
+			keyFile:       "missing",
+			agent:         true,
+			expectMethods: 1,
+		},
+		{
+			name:      "missing key file without agent",
+			keyFile:   "missing",
+			expectErr: os.ErrNotExist,
+		},
+		{
+			name:          "key file with passphrase and agent",
+			keyFile:       "id_passphrase",
+			agent:         true,
+			expectMethods: 1,
+		},
+		{
+			name:      "key file with passphrase without agent",
+			keyFile:   "id_passphrase",
+			expectErr: ErrSSHKeyPassphrase,
+		},
+	}
+
+	keyDir := writeTestKeys(t)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			keyFile := ""
+			if tc.keyFile != "" {
+				keyFile = filepath.Join(keyDir, tc.keyFile)
+			}
+
+			var agentConn net.Conn
+			if tc.agent {
+				client, server := net.Pipe()
+				t.Cleanup(func() {
+					client.Close()
+					server.Close()
+				})
+				agentConn = client
+			}
+
+			methods, err := authMethods(keyFile, agentConn)
+			if tc.expectErr != nil {
+				if !errors.Is(err, tc.expectErr) {
+					t.Fatalf("expected error %v but got %v", tc.expectErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(methods) != tc.expectMethods {
+				t.Fatalf("expected %d auth methods but got %d", tc.expectMethods, len(methods))
+			}
+		})
+	}
+}
+
+func writeTestKeys(t *testing.T) string {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %s", err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatalf("marshalling key: %s", err)
+	}
+
+	encryptedBlock, err := ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte("secret"))
+	if err != nil {
+		t.Fatalf("marshalling key with passphrase: %s", err)
+	}
+
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "id_ed25519"), pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("writing key: %s", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "id_passphrase"), pem.EncodeToMemory(encryptedBlock), 0o600); err != nil {
+		t.Fatalf("writing key: %s", err)
+	}
+
+	return dir
+}
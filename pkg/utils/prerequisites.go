@@ -67,6 +67,25 @@ func CheckAWSIAMAuthPrereq() error {
 	return nil
 }
 
+func CheckAWSCliPrereq() error {
+	cmd := exec.Command("aws", "--version")
+
+	_, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("error finding aws cli: %w", err)
+	}
+
+	return nil
+}
+
+func CheckKconnectPrereq() error {
+	if _, err := exec.LookPath("kconnect"); err != nil {
+		return fmt.Errorf("error finding kconnect on the path: %w", err)
+	}
+
+	return nil
+}
+
 func CheckKubeloginPrereq() error {
 	cmd := exec.Command("kubelogin")
 